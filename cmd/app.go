@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/zhoudm1743/go-frame/pkg/core"
+	"github.com/zhoudm1743/go-frame/pkg/http"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"go.uber.org/fx"
+)
+
+// appFactory 应用构建函数，由main包注册
+var appFactory func() *core.App
+
+// RegisterApp 注册应用构建函数
+// 需要加载应用的命令（如routes）通过它构建应用容器
+func RegisterApp(factory func() *core.App) {
+	appFactory = factory
+}
+
+// IsCommand 判断参数是否为已注册的子命令
+func IsCommand(name string) bool {
+	if name == "help" || name == "completion" || name == "-h" || name == "--help" {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadServer 构建应用容器并获取HTTP服务器，不会启动服务
+// 构建过程中执行的路由注册会写入服务器的路由表
+func loadServer(verbose bool) (http.Server, error) {
+	var server http.Server
+	if err := loadApp(verbose, &server); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// loadApp 构建应用容器并将容器中的对象填充到targets，不会启动服务
+func loadApp(verbose bool, targets ...interface{}) error {
+	if appFactory == nil {
+		return errors.New("未注册应用，请在main中调用cmd.RegisterApp")
+	}
+
+	opts := appFactory().Options()
+	if !verbose {
+		// 静默应用日志，避免污染命令输出
+		opts = append(opts, fx.Decorate(log.NewDiscardLogger))
+	}
+
+	fxApp := fx.New(
+		fx.Options(opts...),
+		fx.NopLogger,
+		fx.Populate(targets...),
+	)
+	return fxApp.Err()
+}
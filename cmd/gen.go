@@ -29,6 +29,7 @@ var genCmd = &cobra.Command{
 		}
 
 		fmt.Printf("代码生成成功! 模块: %s, 名称: %s\n", module, name)
+		fmt.Printf("在应用中添加 app.AddModule(%s.NewModule()) 即可启用模块，路由会自动挂载\n", module)
 	},
 }
 
@@ -40,11 +41,11 @@ func init() {
 var controllerTmpl = `package controller
 
 import (
+	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/model"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/schemas/req"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/service"
 	"github.com/zhoudm1743/go-frame/pkg/http/unified"
 	"github.com/zhoudm1743/go-frame/pkg/response"
-	"github.com/zhoudm1743/go-frame/util"
 )
 
 // {{.Name}}Controller {{.Comment}}控制器
@@ -60,90 +61,33 @@ func New{{.Name}}Controller(service *service.{{.Name}}Service) *{{.Name}}Control
 }
 
 // List 获取列表
-func (c *{{.Name}}Controller) List(ctx unified.Context) error {
-	// 这里不需要验证参数，直接获取所有数据
-	items, err := c.service.GetAll()
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, items)
+func (c *{{.Name}}Controller) List(ctx unified.Context, _ *unified.Empty) ([]*model.{{.Name}}, error) {
+	return c.service.GetAll(ctx.Context())
 }
 
 // Get 获取单个记录
-func (c *{{.Name}}Controller) Get(ctx unified.Context) error {
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	item, err := c.service.GetByID(idReq.ID)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, item)
+func (c *{{.Name}}Controller) Get(ctx unified.Context, idReq *req.IdReq) (*model.{{.Name}}, error) {
+	return c.service.GetByID(ctx.Context(), idReq.ID)
 }
 
 // Create 创建记录
-func (c *{{.Name}}Controller) Create(ctx unified.Context) error {
-	var createReq req.{{.Name}}CreateReq
-	if err := util.VerifyUtil.VerifyJSON(ctx, &createReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	created, err := c.service.Create(&createReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, created)
+func (c *{{.Name}}Controller) Create(ctx unified.Context, createReq *req.{{.Name}}CreateReq) (*model.{{.Name}}, error) {
+	return c.service.Create(ctx.Context(), createReq)
 }
 
 // Update 更新记录
-func (c *{{.Name}}Controller) Update(ctx unified.Context) error {
-	// 验证路径参数
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	// 验证请求体
-	var updateReq req.{{.Name}}UpdateReq
-	if err := util.VerifyUtil.VerifyJSON(ctx, &updateReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	updated, err := c.service.Update(idReq.ID, &updateReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, updated)
+func (c *{{.Name}}Controller) Update(ctx unified.Context, updateReq *req.{{.Name}}UpdateReq) (*model.{{.Name}}, error) {
+	return c.service.Update(ctx.Context(), updateReq.ID, updateReq)
 }
 
 // Delete 删除记录
-func (c *{{.Name}}Controller) Delete(ctx unified.Context) error {
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	err := c.service.Delete(idReq.ID)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOk(ctx)
+func (c *{{.Name}}Controller) Delete(ctx unified.Context, idReq *req.IdReq) (unified.Empty, error) {
+	return unified.Empty{}, c.service.Delete(ctx.Context(), idReq.ID)
 }
 
 // Page 分页查询
-func (c *{{.Name}}Controller) Page(ctx unified.Context) error {
-	var pageReq req.PageReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &pageReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	pageResult, err := c.service.GetPage(&pageReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, pageResult)
+func (c *{{.Name}}Controller) Page(ctx unified.Context, pageReq *req.PageReq) (*response.PageResult[*model.{{.Name}}], error) {
+	return c.service.GetPage(ctx.Context(), pageReq)
 }
 `
 
@@ -151,7 +95,10 @@ func (c *{{.Name}}Controller) Page(ctx unified.Context) error {
 var routerTmpl = `package controller
 
 import (
+	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/model"
+	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/schemas/req"
 	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/response"
 )
 
 // {{.Name}}Router {{.Comment}}路由
@@ -170,12 +117,18 @@ func New{{.Name}}Router(controller *{{.Name}}Controller) *{{.Name}}Router {
 func (r *{{.Name}}Router) RegisterRoutes(router unified.Router) {
 	group := router.Group("/api/{{.LowerName}}ies")
 
-	group.GET("", r.controller.List)
-	group.GET("/:id", r.controller.Get)
-	group.POST("", r.controller.Create)
-	group.PUT("/:id", r.controller.Update)
-	group.DELETE("/:id", r.controller.Delete)
-	group.GET("/page", r.controller.Page)
+	group.GET("", unified.Handle(r.controller.List)).
+		Doc(unified.RouteDoc{Summary: "获取{{.Comment}}列表", Response: []*model.{{.Name}}{}})
+	group.GET("/page", unified.Handle(r.controller.Page)).
+		Doc(unified.RouteDoc{Summary: "分页查询{{.Comment}}", Request: req.PageReq{}, Response: response.PageResult[*model.{{.Name}}]{}})
+	group.GET("/:id", unified.Handle(r.controller.Get)).
+		Doc(unified.RouteDoc{Summary: "获取{{.Comment}}详情", Request: req.IdReq{}, Response: model.{{.Name}}{}})
+	group.POST("", unified.Handle(r.controller.Create)).
+		Doc(unified.RouteDoc{Summary: "创建{{.Comment}}", Request: req.{{.Name}}CreateReq{}, Response: model.{{.Name}}{}})
+	group.PUT("/:id", unified.Handle(r.controller.Update)).
+		Doc(unified.RouteDoc{Summary: "更新{{.Comment}}", Request: req.{{.Name}}UpdateReq{}, Response: model.{{.Name}}{}})
+	group.DELETE("/:id", unified.Handle(r.controller.Delete)).
+		Doc(unified.RouteDoc{Summary: "删除{{.Comment}}", Request: req.IdReq{}})
 }
 `
 
@@ -183,6 +136,8 @@ func (r *{{.Name}}Router) RegisterRoutes(router unified.Router) {
 var serviceTmpl = `package service
 
 import (
+	"context"
+
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/model"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/repository"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/schemas/req"
@@ -202,17 +157,17 @@ func New{{.Name}}Service(repo *repository.{{.Name}}Repository) *{{.Name}}Service
 }
 
 // GetAll 获取所有{{.Comment}}
-func (s *{{.Name}}Service) GetAll() ([]*model.{{.Name}}, error) {
-	return s.repo.FindAll()
+func (s *{{.Name}}Service) GetAll(ctx context.Context) ([]*model.{{.Name}}, error) {
+	return s.repo.FindAll(ctx)
 }
 
 // GetByID 根据ID获取{{.Comment}}
-func (s *{{.Name}}Service) GetByID(id uint) (*model.{{.Name}}, error) {
-	return s.repo.FindByID(id)
+func (s *{{.Name}}Service) GetByID(ctx context.Context, id uint) (*model.{{.Name}}, error) {
+	return s.repo.FindByID(ctx, id)
 }
 
 // Create 创建{{.Comment}}
-func (s *{{.Name}}Service) Create(req *req.{{.Name}}CreateReq) (*model.{{.Name}}, error) {
+func (s *{{.Name}}Service) Create(ctx context.Context, req *req.{{.Name}}CreateReq) (*model.{{.Name}}, error) {
 	// 将请求转换为模型
 	{{.LowerName}} := &model.{{.Name}}{
 		Name:        req.Name,
@@ -222,13 +177,13 @@ func (s *{{.Name}}Service) Create(req *req.{{.Name}}CreateReq) (*model.{{.Name}}
 	}
 
 	// 创建记录
-	return s.repo.Create({{.LowerName}})
+	return s.repo.Create(ctx, {{.LowerName}})
 }
 
 // Update 更新{{.Comment}}
-func (s *{{.Name}}Service) Update(id uint, req *req.{{.Name}}UpdateReq) (*model.{{.Name}}, error) {
+func (s *{{.Name}}Service) Update(ctx context.Context, id uint, req *req.{{.Name}}UpdateReq) (*model.{{.Name}}, error) {
 	// 查找记录
-	{{.LowerName}}, err := s.repo.FindByID(id)
+	{{.LowerName}}, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -240,17 +195,17 @@ func (s *{{.Name}}Service) Update(id uint, req *req.{{.Name}}UpdateReq) (*model.
 	// 根据需要添加更多字段
 
 	// 保存更新
-	return s.repo.Update({{.LowerName}})
+	return s.repo.Update(ctx, {{.LowerName}})
 }
 
 // Delete 删除{{.Comment}}
-func (s *{{.Name}}Service) Delete(id uint) error {
-	return s.repo.Delete(id)
+func (s *{{.Name}}Service) Delete(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
 }
 
 // GetPage 分页查询{{.Comment}}
-func (s *{{.Name}}Service) GetPage(req *req.PageReq) (*response.PageResult[*model.{{.Name}}], error) {
-	return s.repo.FindPage(req)
+func (s *{{.Name}}Service) GetPage(ctx context.Context, req *req.PageReq) (*response.PageResult[*model.{{.Name}}], error) {
+	return s.repo.FindPage(ctx, req)
 }
 `
 
@@ -258,6 +213,8 @@ func (s *{{.Name}}Service) GetPage(req *req.PageReq) (*response.PageResult[*mode
 var repositoryTmpl = `package repository
 
 import (
+	"context"
+
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/model"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/schemas/req"
 	"github.com/zhoudm1743/go-frame/pkg/response"
@@ -280,43 +237,43 @@ func New{{.Name}}Repository(db *gorm.DB) *{{.Name}}Repository {
 }
 
 // FindAll 查询所有{{.Comment}}
-func (r *{{.Name}}Repository) FindAll() ([]*model.{{.Name}}, error) {
+func (r *{{.Name}}Repository) FindAll(ctx context.Context) ([]*model.{{.Name}}, error) {
 	var items []*model.{{.Name}}
-	err := r.db.Find(&items).Error
+	err := r.db.WithContext(ctx).Find(&items).Error
 	return items, err
 }
 
 // FindByID 根据ID查询{{.Comment}}
-func (r *{{.Name}}Repository) FindByID(id uint) (*model.{{.Name}}, error) {
+func (r *{{.Name}}Repository) FindByID(ctx context.Context, id uint) (*model.{{.Name}}, error) {
 	var item model.{{.Name}}
-	err := r.db.First(&item, id).Error
+	err := r.db.WithContext(ctx).First(&item, id).Error
 	return &item, err
 }
 
 // Create 创建{{.Comment}}
-func (r *{{.Name}}Repository) Create(item *model.{{.Name}}) (*model.{{.Name}}, error) {
-	err := r.db.Create(item).Error
+func (r *{{.Name}}Repository) Create(ctx context.Context, item *model.{{.Name}}) (*model.{{.Name}}, error) {
+	err := r.db.WithContext(ctx).Create(item).Error
 	return item, err
 }
 
 // Update 更新{{.Comment}}
-func (r *{{.Name}}Repository) Update(item *model.{{.Name}}) (*model.{{.Name}}, error) {
-	err := r.db.Save(item).Error
+func (r *{{.Name}}Repository) Update(ctx context.Context, item *model.{{.Name}}) (*model.{{.Name}}, error) {
+	err := r.db.WithContext(ctx).Save(item).Error
 	return item, err
 }
 
 // Delete 删除{{.Comment}}
-func (r *{{.Name}}Repository) Delete(id uint) error {
-	return r.db.Delete(&model.{{.Name}}{}, id).Error
+func (r *{{.Name}}Repository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.{{.Name}}{}, id).Error
 }
 
 // FindPage 分页查询{{.Comment}}
-func (r *{{.Name}}Repository) FindPage(req *req.PageReq) (*response.PageResult[*model.{{.Name}}], error) {
+func (r *{{.Name}}Repository) FindPage(ctx context.Context, req *req.PageReq) (*response.PageResult[*model.{{.Name}}], error) {
 	var items []*model.{{.Name}}
 	var total int64
 
 	// 查询总数
-	query := r.db.Model(&model.{{.Name}}{})
+	query := r.db.WithContext(ctx).Model(&model.{{.Name}}{})
 	err := query.Count(&total).Error
 	if err != nil {
 		return nil, err
@@ -365,6 +322,7 @@ type {{.Name}}CreateReq struct {
 
 // {{.Name}}UpdateReq {{.Comment}}更新请求
 type {{.Name}}UpdateReq struct {
+	ID          uint   ` + "`" + `uri:"id" json:"-" binding:"required,min=1"` + "`" + `
 	Name        string ` + "`" + `json:"name" binding:"required" msg:"请输入{{.Comment}}名称"` + "`" + `
 	Description string ` + "`" + `json:"description"` + "`" + `
 	Status      int8    ` + "`" + `json:"status" binding:"oneof=0 1" msg:"状态只能是0或1"` + "`" + `
@@ -407,16 +365,9 @@ import (
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/repository"
 	"github.com/zhoudm1743/go-frame/internal/{{.Module}}/service"
 	"github.com/zhoudm1743/go-frame/pkg/http"
-	"github.com/zhoudm1743/go-frame/pkg/http/unified"
-	"github.com/zhoudm1743/go-frame/pkg/log"
 	"go.uber.org/fx"
 )
 
-// Router 路由接口
-type Router interface {
-	RegisterRoutes(router unified.Router)
-}
-
 // Module 模块定义
 type Module struct {
 	moduleID string
@@ -454,27 +405,9 @@ func (m *Module) Options() fx.Option {
 			service.New{{.Name}}Service,
 			controller.New{{.Name}}Controller,
 			controller.New{{.Name}}Router,
-			// 注册路由组
-			fx.Annotate(
-				func(router *controller.{{.Name}}Router) Router {
-					return router
-				},
-				fx.ResultTags(fmt.Sprintf(` + "`" + `group:"%s_routers"` + "`" + `, m.moduleID)),
-			),
-		),
-		// 注册路由
-		fx.Invoke(
-			fx.Annotate(
-				func(server http.Server, logger log.Logger, routers []Router) {
-					logger.Infof("注册模块: %s", m.Name())
-					for _, router := range routers {
-						router.RegisterRoutes(server.Router())
-					}
-					logger.Infof("模块 %s 路由注册完成", m.moduleID)
-				},
-				fx.ParamTags(` + "`" + `` + "`" + `, ` + "`" + `` + "`" + `, fmt.Sprintf(` + "`" + `group:"%s_routers"` + "`" + `, m.moduleID)),
-			),
 		),
+		// 路由注册器加入routes组，由HTTP模块统一挂载到模块路由前缀下
+		http.ProvideRoutes[*controller.{{.Name}}Router](m.RoutePrefix()),
 	)
 }
 `
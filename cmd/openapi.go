@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/http"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// 输出文件，为空时输出到标准输出
+	openapiOutput string
+	// 输出格式: json 或 yaml
+	openapiFormat string
+	// 输出应用日志
+	openapiVerbose bool
+)
+
+// openapiCmd 导出OpenAPI文档命令
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "导出OpenAPI文档",
+	Long:  "加载应用并根据已注册的路由生成OpenAPI 3.1文档，可输出为JSON或YAML",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			server http.Server
+			cfg    *config.Config
+		)
+		if err := loadApp(openapiVerbose, &server, &cfg); err != nil {
+			fmt.Printf("加载应用失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		doc := http.OpenAPIDocument(server, cfg)
+
+		var out io.Writer = os.Stdout
+		if openapiOutput != "" {
+			if err := os.MkdirAll(filepath.Dir(openapiOutput), 0755); err != nil {
+				fmt.Printf("创建目录失败: %v\n", err)
+				os.Exit(1)
+			}
+			file, err := os.Create(openapiOutput)
+			if err != nil {
+				fmt.Printf("创建文件失败: %v\n", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		var err error
+		switch openapiFormat {
+		case "json":
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(doc)
+		case "yaml":
+			encoder := yaml.NewEncoder(out)
+			encoder.SetIndent(2)
+			err = encoder.Encode(doc)
+			if err == nil {
+				err = encoder.Close()
+			}
+		default:
+			err = fmt.Errorf("不支持的输出格式: %s", openapiFormat)
+		}
+		if err != nil {
+			fmt.Printf("输出文档失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		if openapiOutput != "" {
+			fmt.Printf("OpenAPI文档已导出到 %s\n", openapiOutput)
+		}
+	},
+}
+
+func init() {
+	openapiCmd.Flags().StringVarP(&openapiOutput, "output", "o", "", "输出文件路径，默认输出到标准输出")
+	openapiCmd.Flags().StringVarP(&openapiFormat, "format", "f", "json", "输出格式: json 或 yaml")
+	openapiCmd.Flags().BoolVarP(&openapiVerbose, "verbose", "v", false, "输出应用启动日志")
+	rootCmd.AddCommand(openapiCmd)
+}
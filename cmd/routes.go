@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+var (
+	// 输出格式: table 或 json
+	routesFormat string
+	// 按路径前缀过滤
+	routesPrefix string
+	// 输出应用日志
+	routesVerbose bool
+)
+
+// routesCmd 路由列表命令
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "查看已注册的路由",
+	Long:  "加载应用并列出所有已注册的路由，包括方法、完整路径、处理函数、中间件和分组前缀",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		server, err := loadServer(routesVerbose)
+		if err != nil {
+			fmt.Printf("加载应用失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		routes := filterRoutes(server.Routes(), routesPrefix)
+
+		switch routesFormat {
+		case "json":
+			err = printRoutesJSON(routes)
+		case "table":
+			err = printRoutesTable(routes)
+		default:
+			err = fmt.Errorf("不支持的输出格式: %s", routesFormat)
+		}
+		if err != nil {
+			fmt.Printf("输出路由失败: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	routesCmd.Flags().StringVarP(&routesFormat, "format", "f", "table", "输出格式: table 或 json")
+	routesCmd.Flags().StringVarP(&routesPrefix, "prefix", "p", "", "只显示指定路径前缀的路由")
+	routesCmd.Flags().BoolVarP(&routesVerbose, "verbose", "v", false, "输出应用启动日志")
+	rootCmd.AddCommand(routesCmd)
+}
+
+// filterRoutes 过滤并排序路由
+func filterRoutes(routes []unified.RouteInfo, prefix string) []unified.RouteInfo {
+	result := make([]unified.RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		if prefix == "" || strings.HasPrefix(r.Path, prefix) {
+			result = append(result, r)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// printRoutesJSON 以JSON格式输出路由
+func printRoutesJSON(routes []unified.RouteInfo) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(routes)
+}
+
+// printRoutesTable 以表格格式输出路由
+func printRoutesTable(routes []unified.RouteInfo) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARES\tPERMISSIONS\tGROUP")
+	for _, r := range routes {
+		middlewares := "-"
+		if len(r.Middlewares) > 0 {
+			middlewares = strings.Join(r.Middlewares, ", ")
+		}
+		permissions := "-"
+		if len(r.Permissions) > 0 {
+			permissions = strings.Join(r.Permissions, ", ")
+		}
+		group := r.Group
+		if group == "" {
+			group = "/"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Method, r.Path, r.Handler, middlewares, permissions, group)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n共 %d 条路由\n", len(routes))
+	return nil
+}
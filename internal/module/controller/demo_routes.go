@@ -1,12 +1,12 @@
 package controller
 
 import (
+	"github.com/zhoudm1743/go-frame/internal/module/model"
 	"github.com/zhoudm1743/go-frame/internal/module/schemas/req"
 	"github.com/zhoudm1743/go-frame/internal/module/service"
 	"github.com/zhoudm1743/go-frame/pkg/http"
 	"github.com/zhoudm1743/go-frame/pkg/http/unified"
 	"github.com/zhoudm1743/go-frame/pkg/response"
-	"github.com/zhoudm1743/go-frame/util"
 	"go.uber.org/fx"
 )
 
@@ -22,108 +22,56 @@ func NewDemoHandler(service *service.DemoService) *DemoHandler {
 	}
 }
 
-// RegisterDemoRoutes 注册示例路由
-func RegisterDemoRoutes(server http.Server, handler *DemoHandler) {
-	// 使用完整的路由路径，确保显示正确的URL
-	router := server.Router().Group("/api/demoies")
-
-	router.GET("", handler.List)
-	router.GET("/:id", handler.Get)
-	router.POST("", handler.Create)
-	router.PUT("/:id", handler.Update)
-	router.DELETE("/:id", handler.Delete)
-	router.GET("/page", handler.Page)
+// RegisterRoutes 注册示例路由
+func (c *DemoHandler) RegisterRoutes(r unified.Router) {
+	router := r.Group("/api/demoies")
+
+	router.GET("", unified.Handle(c.List)).
+		Doc(unified.RouteDoc{Summary: "获取示例列表", Response: []*model.Demo{}})
+	router.GET("/page", unified.Handle(c.Page)).
+		Doc(unified.RouteDoc{Summary: "分页查询示例", Request: req.PageReq{}, Response: response.PageResult[*model.Demo]{}})
+	router.GET("/:id", unified.Handle(c.Get)).
+		Doc(unified.RouteDoc{Summary: "获取示例详情", Request: req.IdReq{}, Response: model.Demo{}})
+	router.POST("", unified.Handle(c.Create)).
+		Doc(unified.RouteDoc{Summary: "创建示例", Request: req.DemoCreateReq{}, Response: model.Demo{}})
+	router.PUT("/:id", unified.Handle(c.Update)).
+		Doc(unified.RouteDoc{Summary: "更新示例", Request: req.DemoUpdateReq{}, Response: model.Demo{}})
+	router.DELETE("/:id", unified.Handle(c.Delete)).
+		Doc(unified.RouteDoc{Summary: "删除示例", Request: req.IdReq{}})
 }
 
 // DemoModule 示例模块
 var DemoModule = fx.Options(
 	fx.Provide(NewDemoHandler),
-	fx.Invoke(RegisterDemoRoutes),
+	http.ProvideRoutes[*DemoHandler](""),
 )
 
 // List 获取列表
-func (c *DemoHandler) List(ctx unified.Context) error {
-	// 这里不需要验证参数，直接获取所有数据
-	items, err := c.service.GetAll()
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, items)
+func (c *DemoHandler) List(ctx unified.Context, _ *unified.Empty) ([]*model.Demo, error) {
+	return c.service.GetAll(ctx.Context())
 }
 
 // Get 获取单个记录
-func (c *DemoHandler) Get(ctx unified.Context) error {
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	item, err := c.service.GetByID(idReq.ID)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, item)
+func (c *DemoHandler) Get(ctx unified.Context, idReq *req.IdReq) (*model.Demo, error) {
+	return c.service.GetByID(ctx.Context(), idReq.ID)
 }
 
 // Create 创建记录
-func (c *DemoHandler) Create(ctx unified.Context) error {
-	var createReq req.DemoCreateReq
-	if err := util.VerifyUtil.VerifyJSON(ctx, &createReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	created, err := c.service.Create(&createReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, created)
+func (c *DemoHandler) Create(ctx unified.Context, createReq *req.DemoCreateReq) (*model.Demo, error) {
+	return c.service.Create(ctx.Context(), createReq)
 }
 
 // Update 更新记录
-func (c *DemoHandler) Update(ctx unified.Context) error {
-	// 验证路径参数
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	// 验证请求体
-	var updateReq req.DemoUpdateReq
-	if err := util.VerifyUtil.VerifyJSON(ctx, &updateReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	updated, err := c.service.Update(idReq.ID, &updateReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, updated)
+func (c *DemoHandler) Update(ctx unified.Context, updateReq *req.DemoUpdateReq) (*model.Demo, error) {
+	return c.service.Update(ctx.Context(), updateReq.ID, updateReq)
 }
 
 // Delete 删除记录
-func (c *DemoHandler) Delete(ctx unified.Context) error {
-	var idReq req.IdReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &idReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, "无效的ID")
-	}
-
-	err := c.service.Delete(idReq.ID)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOk(ctx)
+func (c *DemoHandler) Delete(ctx unified.Context, idReq *req.IdReq) (unified.Empty, error) {
+	return unified.Empty{}, c.service.Delete(ctx.Context(), idReq.ID)
 }
 
 // Page 分页查询
-func (c *DemoHandler) Page(ctx unified.Context) error {
-	var pageReq req.PageReq
-	if err := util.VerifyUtil.VerifyQuery(ctx, &pageReq); err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.ParamsValidError, err.Error())
-	}
-
-	pageResult, err := c.service.GetPage(&pageReq)
-	if err != nil {
-		return response.UnifiedFailWithMsg(ctx, response.SystemError, err.Error())
-	}
-	return response.UnifiedOkWithData(ctx, pageResult)
+func (c *DemoHandler) Page(ctx unified.Context, pageReq *req.PageReq) (*response.PageResult[*model.Demo], error) {
+	return c.service.GetPage(ctx.Context(), pageReq)
 }
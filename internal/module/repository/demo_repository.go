@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/zhoudm1743/go-frame/internal/module/model"
 	"github.com/zhoudm1743/go-frame/internal/module/schemas/req"
 	"github.com/zhoudm1743/go-frame/pkg/response"
@@ -23,43 +25,43 @@ func NewDemoRepository(db *gorm.DB) *DemoRepository {
 }
 
 // FindAll 查询所有示例
-func (r *DemoRepository) FindAll() ([]*model.Demo, error) {
+func (r *DemoRepository) FindAll(ctx context.Context) ([]*model.Demo, error) {
 	var items []*model.Demo
-	err := r.db.Find(&items).Error
+	err := r.db.WithContext(ctx).Find(&items).Error
 	return items, err
 }
 
 // FindByID 根据ID查询示例
-func (r *DemoRepository) FindByID(id uint) (*model.Demo, error) {
+func (r *DemoRepository) FindByID(ctx context.Context, id uint) (*model.Demo, error) {
 	var item model.Demo
-	err := r.db.First(&item, id).Error
+	err := r.db.WithContext(ctx).First(&item, id).Error
 	return &item, err
 }
 
 // Create 创建示例
-func (r *DemoRepository) Create(item *model.Demo) (*model.Demo, error) {
-	err := r.db.Create(item).Error
+func (r *DemoRepository) Create(ctx context.Context, item *model.Demo) (*model.Demo, error) {
+	err := r.db.WithContext(ctx).Create(item).Error
 	return item, err
 }
 
 // Update 更新示例
-func (r *DemoRepository) Update(item *model.Demo) (*model.Demo, error) {
-	err := r.db.Save(item).Error
+func (r *DemoRepository) Update(ctx context.Context, item *model.Demo) (*model.Demo, error) {
+	err := r.db.WithContext(ctx).Save(item).Error
 	return item, err
 }
 
 // Delete 删除示例
-func (r *DemoRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Demo{}, id).Error
+func (r *DemoRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&model.Demo{}, id).Error
 }
 
 // FindPage 分页查询示例
-func (r *DemoRepository) FindPage(req *req.PageReq) (*response.PageResult[*model.Demo], error) {
+func (r *DemoRepository) FindPage(ctx context.Context, req *req.PageReq) (*response.PageResult[*model.Demo], error) {
 	var items []*model.Demo
 	var total int64
 
 	// 查询总数
-	query := r.db.Model(&model.Demo{})
+	query := r.db.WithContext(ctx).Model(&model.Demo{})
 	err := query.Count(&total).Error
 	if err != nil {
 		return nil, err
@@ -4,14 +4,15 @@ package req
 type DemoCreateReq struct {
 	Name        string `json:"name" binding:"required" msg:"请输入示例名称"`
 	Description string `json:"description"`
-	Status      int8    `json:"status" binding:"oneof=0 1" msg:"状态只能是0或1"`
+	Status      int8   `json:"status" binding:"oneof=0 1" msg:"状态只能是0或1"`
 	// 可以根据需要添加更多字段
 }
 
 // DemoUpdateReq 示例更新请求
 type DemoUpdateReq struct {
+	ID          uint   `uri:"id" json:"-" binding:"required,min=1"`
 	Name        string `json:"name" binding:"required" msg:"请输入示例名称"`
 	Description string `json:"description"`
-	Status      int8    `json:"status" binding:"oneof=0 1" msg:"状态只能是0或1"`
+	Status      int8   `json:"status" binding:"oneof=0 1" msg:"状态只能是0或1"`
 	// 可以根据需要添加更多字段
 }
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+
 	"github.com/zhoudm1743/go-frame/internal/module/model"
 	"github.com/zhoudm1743/go-frame/internal/module/repository"
 	"github.com/zhoudm1743/go-frame/internal/module/schemas/req"
@@ -20,17 +22,17 @@ func NewDemoService(repo *repository.DemoRepository) *DemoService {
 }
 
 // GetAll 获取所有示例
-func (s *DemoService) GetAll() ([]*model.Demo, error) {
-	return s.repo.FindAll()
+func (s *DemoService) GetAll(ctx context.Context) ([]*model.Demo, error) {
+	return s.repo.FindAll(ctx)
 }
 
 // GetByID 根据ID获取示例
-func (s *DemoService) GetByID(id uint) (*model.Demo, error) {
-	return s.repo.FindByID(id)
+func (s *DemoService) GetByID(ctx context.Context, id uint) (*model.Demo, error) {
+	return s.repo.FindByID(ctx, id)
 }
 
 // Create 创建示例
-func (s *DemoService) Create(req *req.DemoCreateReq) (*model.Demo, error) {
+func (s *DemoService) Create(ctx context.Context, req *req.DemoCreateReq) (*model.Demo, error) {
 	// 将请求转换为模型
 	demo := &model.Demo{
 		Name:        req.Name,
@@ -40,13 +42,13 @@ func (s *DemoService) Create(req *req.DemoCreateReq) (*model.Demo, error) {
 	}
 
 	// 创建记录
-	return s.repo.Create(demo)
+	return s.repo.Create(ctx, demo)
 }
 
 // Update 更新示例
-func (s *DemoService) Update(id uint, req *req.DemoUpdateReq) (*model.Demo, error) {
+func (s *DemoService) Update(ctx context.Context, id uint, req *req.DemoUpdateReq) (*model.Demo, error) {
 	// 查找记录
-	demo, err := s.repo.FindByID(id)
+	demo, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -58,15 +60,15 @@ func (s *DemoService) Update(id uint, req *req.DemoUpdateReq) (*model.Demo, erro
 	// 根据需要添加更多字段
 
 	// 保存更新
-	return s.repo.Update(demo)
+	return s.repo.Update(ctx, demo)
 }
 
 // Delete 删除示例
-func (s *DemoService) Delete(id uint) error {
-	return s.repo.Delete(id)
+func (s *DemoService) Delete(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
 }
 
 // GetPage 分页查询示例
-func (s *DemoService) GetPage(req *req.PageReq) (*response.PageResult[*model.Demo], error) {
-	return s.repo.FindPage(req)
+func (s *DemoService) GetPage(ctx context.Context, req *req.PageReq) (*response.PageResult[*model.Demo], error) {
+	return s.repo.FindPage(ctx, req)
 }
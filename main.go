@@ -10,12 +10,21 @@ import (
 )
 
 func main() {
+	// 注册应用构建函数，供routes等命令加载应用
+	cmd.RegisterApp(newApp)
+
 	// 检查是否是命令行模式
-	if len(os.Args) > 1 && os.Args[1] == "gen" {
+	if len(os.Args) > 1 && cmd.IsCommand(os.Args[1]) {
 		cmd.Execute()
 		return
 	}
 
+	// 启动应用
+	newApp().Run()
+}
+
+// newApp 创建应用
+func newApp() *core.App {
 	// 创建应用
 	app := core.NewApp("go-frame")
 
@@ -25,6 +34,5 @@ func main() {
 	// 添加业务模块
 	app.AddModule(module.NewModule())
 
-	// 启动应用
-	app.Run()
+	return app
 }
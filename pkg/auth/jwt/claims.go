@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// token类型
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+// ClaimsKey 认证中间件保存Claims的上下文数据键
+const ClaimsKey = "jwt_claims"
+
+// Claims token中的声明
+type Claims struct {
+	// ID token的唯一ID（jti），用于撤销刷新token
+	ID string `json:"jti,omitempty"`
+	// Subject 用户标识（sub），通常为用户ID
+	Subject string `json:"sub,omitempty"`
+	// Issuer 签发者（iss）
+	Issuer string `json:"iss,omitempty"`
+	// Audience 接收者（aud）
+	Audience Audience `json:"aud,omitempty"`
+	// IssuedAt 签发时间（iat），Unix时间戳
+	IssuedAt int64 `json:"iat,omitempty"`
+	// IssuedAtNano 签发时间，Unix纳秒时间戳，撤销用户的所有token时用于区分同一秒内签发的token
+	IssuedAtNano int64 `json:"iat_ns,omitempty"`
+	// NotBefore 生效时间（nbf），Unix时间戳
+	NotBefore int64 `json:"nbf,omitempty"`
+	// ExpiresAt 过期时间（exp），Unix时间戳
+	ExpiresAt int64 `json:"exp,omitempty"`
+	// Type token类型，AccessToken或RefreshToken
+	Type string `json:"token_type,omitempty"`
+	// Data 自定义数据，例如用户名、角色，刷新时原样带入新的token
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// ExpiresIn 距离过期的时间
+func (c *Claims) ExpiresIn() time.Duration {
+	return time.Until(time.Unix(c.ExpiresAt, 0))
+}
+
+// Audience 接收者，只有一个接收者时按字符串编码
+type Audience []string
+
+// MarshalJSON 实现json.Marshaler接口
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，支持字符串和字符串数组
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+// contains 是否包含任一接收者
+func (a Audience) contains(audience []string) bool {
+	for _, expected := range audience {
+		for _, actual := range a {
+			if actual == expected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimsContextKey 保存Claims的context.Context键
+type claimsContextKey struct{}
+
+// GetClaims 获取认证中间件保存的Claims
+func GetClaims(c unified.Context) (*Claims, bool) {
+	v, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(*Claims)
+	return claims, ok
+}
+
+// FromContext 从请求的context.Context获取Claims，用于服务层
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// withClaims 将Claims保存到context.Context
+func withClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
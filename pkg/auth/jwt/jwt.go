@@ -0,0 +1,352 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// 撤销列表的缓存键前缀
+const (
+	revokedPrefix        = "jwt:revoked:"
+	revokedSubjectPrefix = "jwt:revoked_subject:"
+)
+
+// 验证token的错误，都是response.RespType，处理函数直接返回时输出401响应
+var (
+	ErrTokenMalformed   = response.TokenInvalid.Make("token格式错误")
+	ErrSignatureInvalid = response.TokenInvalid.Make("token签名无效")
+	ErrUnknownKey       = response.TokenInvalid.Make("token签名密钥不存在")
+	ErrTokenType        = response.TokenInvalid.Make("token类型错误")
+	ErrTokenClaims      = response.TokenInvalid.Make("token签发者或接收者不匹配")
+	ErrTokenNotValidYet = response.TokenInvalid.Make("token尚未生效")
+	ErrTokenRevoked     = response.TokenInvalid.Make("token已被撤销")
+	ErrTokenExpired     = response.TokenExpired
+)
+
+// Options JWT管理器选项
+type Options struct {
+	// Keys 签名密钥，验证token时按头部的kid选择密钥
+	Keys []*Key
+	// SigningKey 签发token使用的密钥ID，默认使用第一个密钥
+	SigningKey string
+	// Issuer 签发者，设置后只接受该签发者的token
+	Issuer string
+	// Audience 接收者，设置后只接受包含其中任一接收者的token
+	Audience []string
+	// AccessTTL 访问token有效期，默认15分钟
+	AccessTTL time.Duration
+	// RefreshTTL 刷新token有效期，默认7天
+	RefreshTTL time.Duration
+	// Leeway 验证过期和生效时间时允许的时钟误差
+	Leeway time.Duration
+}
+
+// TokenPair 访问token和刷新token
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`         // 访问token有效期（秒）
+	RefreshExpiresIn int64  `json:"refresh_expires_in"` // 刷新token有效期（秒）
+}
+
+// Manager JWT管理器，签发和验证token，刷新token的撤销列表保存在缓存中
+type Manager struct {
+	options    Options
+	keys       map[string]*Key
+	signingKey *Key
+	cache      cache.Cache
+	logger     log.Logger
+	lookup     tokenLookup
+}
+
+// NewManager 根据配置创建JWT管理器
+func NewManager(config *config.Config, cache cache.Cache, logger log.Logger) (*Manager, error) {
+	cfg := config.JWT
+	keys := make([]*Key, 0, len(cfg.Keys))
+	for _, keyConfig := range cfg.Keys {
+		key, err := ParseKey(keyConfig)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	m, err := New(Options{
+		Keys:       keys,
+		SigningKey: cfg.SigningKey,
+		Issuer:     cfg.Issuer,
+		Audience:   cfg.Audience,
+		AccessTTL:  cfg.AccessTTL,
+		RefreshTTL: cfg.RefreshTTL,
+		Leeway:     cfg.Leeway,
+	}, cache, logger)
+	if err != nil {
+		return nil, err
+	}
+	m.lookup = tokenLookup{header: cfg.Header, scheme: cfg.Scheme, query: cfg.Query, cookie: cfg.Cookie}
+	return m, nil
+}
+
+// New 创建JWT管理器，用于在代码中创建密钥的场景
+func New(options Options, cache cache.Cache, logger log.Logger) (*Manager, error) {
+	if len(options.Keys) == 0 {
+		return nil, errors.New("未配置JWT签名密钥")
+	}
+	if options.AccessTTL <= 0 {
+		options.AccessTTL = 15 * time.Minute
+	}
+	if options.RefreshTTL <= 0 {
+		options.RefreshTTL = 7 * 24 * time.Hour
+	}
+
+	m := &Manager{
+		options: options,
+		keys:    make(map[string]*Key, len(options.Keys)),
+		cache:   cache,
+		logger:  logger,
+		lookup:  tokenLookup{header: "Authorization", scheme: "Bearer"},
+	}
+	for _, key := range options.Keys {
+		if _, ok := m.keys[key.ID]; ok {
+			return nil, fmt.Errorf("JWT密钥ID重复: %s", key.ID)
+		}
+		m.keys[key.ID] = key
+	}
+	m.signingKey = options.Keys[0]
+	if options.SigningKey != "" {
+		key, ok := m.keys[options.SigningKey]
+		if !ok {
+			return nil, fmt.Errorf("JWT签名密钥不存在: %s", options.SigningKey)
+		}
+		m.signingKey = key
+	}
+	if !m.signingKey.canSign() {
+		return nil, fmt.Errorf("JWT密钥%s缺少私钥，不能用于签发token", m.signingKey.ID)
+	}
+	return m, nil
+}
+
+// IssuePair 为用户签发访问token和刷新token，data为自定义数据
+func (m *Manager) IssuePair(subject string, data map[string]interface{}) (*TokenPair, error) {
+	now := time.Now()
+	access, err := m.issue(subject, AccessToken, data, now, m.options.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := m.issue(subject, RefreshToken, data, now, m.options.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(m.options.AccessTTL / time.Second),
+		RefreshExpiresIn: int64(m.options.RefreshTTL / time.Second),
+	}, nil
+}
+
+// Parse 验证访问token并返回其中的声明
+func (m *Manager) Parse(token string) (*Claims, error) {
+	return m.parse(token, AccessToken)
+}
+
+// Refresh 使用刷新token签发新的token，旧的刷新token随即撤销，只能使用一次
+func (m *Manager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	claims, err := m.parse(refreshToken, RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkSubject(ctx, claims); err != nil {
+		return nil, err
+	}
+	// 计数大于1说明刷新token已经使用过或被撤销，并发刷新时只有一个请求成功
+	count, err := m.cache.IncrByCtx(ctx, revokedPrefix+claims.ID, 1)
+	if err != nil {
+		return nil, err
+	}
+	if count > 1 {
+		m.logger.WithContext(ctx).WithFields(map[string]interface{}{
+			"subject": claims.Subject,
+			"jti":     claims.ID,
+		}).Warn("使用了已撤销的刷新token")
+		return nil, ErrTokenRevoked
+	}
+	if err := m.cache.ExpireCtx(ctx, revokedPrefix+claims.ID, revokeTTL(claims)); err != nil {
+		return nil, err
+	}
+	return m.IssuePair(claims.Subject, claims.Data)
+}
+
+// Revoke 撤销刷新token，用于退出登录，已过期的token直接忽略
+func (m *Manager) Revoke(ctx context.Context, refreshToken string) error {
+	claims, err := m.parse(refreshToken, RefreshToken)
+	if errors.Is(err, ErrTokenExpired) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := m.cache.IncrByCtx(ctx, revokedPrefix+claims.ID, 1); err != nil {
+		return err
+	}
+	return m.cache.ExpireCtx(ctx, revokedPrefix+claims.ID, revokeTTL(claims))
+}
+
+// RevokeSubject 撤销用户在此之前签发的所有刷新token，用于修改密码或在所有设备上退出登录
+func (m *Manager) RevokeSubject(ctx context.Context, subject string) error {
+	// 撤销时间精确到纳秒，之后同一秒内签发的token不受影响；多保留一秒，文件缓存的过期时间精确到秒
+	return m.cache.SetCtx(ctx, revokedSubjectPrefix+subject, strconv.FormatInt(time.Now().UnixNano(), 10), m.options.RefreshTTL+time.Second)
+}
+
+// checkSubject 检查刷新token是否在用户的所有token被撤销之前签发
+func (m *Manager) checkSubject(ctx context.Context, claims *Claims) error {
+	value, err := m.cache.GetCtx(ctx, revokedSubjectPrefix+claims.Subject)
+	if cache.IsMiss(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+	issuedAt := claims.IssuedAtNano
+	if issuedAt == 0 {
+		// 没有纳秒签发时间的token按秒计算，同一秒内签发的视为已撤销
+		issuedAt = time.Unix(claims.IssuedAt, 0).UnixNano()
+	}
+	if issuedAt < revokedAt {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// revokeTTL 撤销记录的保存时间，token过期后撤销记录不再需要
+func revokeTTL(claims *Claims) time.Duration {
+	return max(claims.ExpiresIn(), 0) + time.Second
+}
+
+// header token头部
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// issue 签发token
+func (m *Manager) issue(subject, tokenType string, data map[string]interface{}, now time.Time, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return m.sign(&Claims{
+		ID:           hex.EncodeToString(id),
+		Subject:      subject,
+		Issuer:       m.options.Issuer,
+		Audience:     m.options.Audience,
+		IssuedAt:     now.Unix(),
+		IssuedAtNano: now.UnixNano(),
+		NotBefore:    now.Unix(),
+		ExpiresAt:    now.Add(ttl).Unix(),
+		Type:         tokenType,
+		Data:         data,
+	})
+}
+
+// sign 使用当前的签名密钥生成token
+func (m *Manager) sign(claims *Claims) (string, error) {
+	key := m.signingKey
+	headerJSON, err := json.Marshal(header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	signature, err := key.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parse 验证token的签名、类型、签发者、接收者和有效期
+func (m *Manager) parse(token, tokenType string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	key := m.signingKey
+	if h.KeyID != "" {
+		var ok bool
+		if key, ok = m.keys[h.KeyID]; !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+	// 算法必须与密钥一致，避免使用公钥作为HMAC密钥伪造token
+	if h.Algorithm != key.Algorithm {
+		return nil, ErrSignatureInvalid
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrSignatureInvalid
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	if claims.Type != tokenType {
+		return nil, ErrTokenType
+	}
+	if m.options.Issuer != "" && claims.Issuer != m.options.Issuer {
+		return nil, ErrTokenClaims
+	}
+	if len(m.options.Audience) > 0 && !claims.Audience.contains(m.options.Audience) {
+		return nil, ErrTokenClaims
+	}
+	now := time.Now()
+	if claims.NotBefore != 0 && now.Add(m.options.Leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrTokenNotValidYet
+	}
+	if claims.ExpiresAt == 0 || !now.Add(-m.options.Leeway).Before(time.Unix(claims.ExpiresAt, 0)) {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+// decodeSegment 解码token的头部或声明
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,290 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// newTestManager 使用内存缓存创建JWT管理器
+func newTestManager(t *testing.T, options Options) *Manager {
+	t.Helper()
+	m, err := New(options, cache.NewTestMemory(t), log.NewDiscardLogger())
+	if err != nil {
+		t.Fatalf("创建JWT管理器失败: %v", err)
+	}
+	return m
+}
+
+func hmacKey(t *testing.T, id, secret string) *Key {
+	t.Helper()
+	key, err := NewHMACKey(id, []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func rsaKey(t *testing.T, id string) *Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewRSAKey(id, private, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func ed25519Key(t *testing.T, id string) *Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewEd25519Key(id, private, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestAlgorithms(t *testing.T) {
+	keys := []*Key{hmacKey(t, "hs", "secret"), rsaKey(t, "rs"), ed25519Key(t, "ed")}
+	for _, key := range keys {
+		m := newTestManager(t, Options{Keys: []*Key{key}})
+		pair, err := m.IssuePair("42", map[string]interface{}{"name": "张三"})
+		if err != nil {
+			t.Fatalf("%s: 签发失败: %v", key.Algorithm, err)
+		}
+		claims, err := m.Parse(pair.AccessToken)
+		if err != nil {
+			t.Fatalf("%s: 验证失败: %v", key.Algorithm, err)
+		}
+		if claims.Subject != "42" || claims.Data["name"] != "张三" || claims.Type != AccessToken {
+			t.Errorf("%s: 声明 %+v", key.Algorithm, claims)
+		}
+
+		// 修改声明后签名失效
+		parts := strings.Split(pair.AccessToken, ".")
+		forged, err := m.sign(&Claims{Subject: "1", Type: AccessToken, ExpiresAt: time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+		if _, err := m.Parse(tampered); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("%s: 修改声明: 错误 %v，期望 ErrSignatureInvalid", key.Algorithm, err)
+		}
+	}
+}
+
+func TestAlgorithmMismatch(t *testing.T) {
+	rs := rsaKey(t, "rs")
+	verifier := newTestManager(t, Options{Keys: []*Key{rs}})
+
+	// 使用公知的RSA公钥作为HMAC密钥伪造token，头部声明HS256
+	der, err := x509.MarshalPKIXPublicKey(rs.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forger := newTestManager(t, Options{Keys: []*Key{hmacKey(t, "rs", string(der))}})
+	pair, err := forger.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Parse(pair.AccessToken); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("HS256伪造RS256密钥: 错误 %v，期望 ErrSignatureInvalid", err)
+	}
+
+	// alg为none且没有签名
+	parts := strings.Split(pair.AccessToken, ".")
+	none := "eyJhbGciOiJub25lIiwia2lkIjoicnMifQ." + parts[1] + "."
+	if _, err := verifier.Parse(none); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("alg为none: 错误 %v，期望 ErrSignatureInvalid", err)
+	}
+
+	for _, token := range []string{"", "a.b", "!.b.c", pair.AccessToken + "."} {
+		if _, err := verifier.Parse(token); !errors.Is(err, ErrTokenMalformed) {
+			t.Errorf("%q: 错误 %v，期望 ErrTokenMalformed", token, err)
+		}
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := hmacKey(t, "2024-01", "old-secret")
+	current := ed25519Key(t, "2024-06")
+	before := newTestManager(t, Options{Keys: []*Key{old}})
+	after := newTestManager(t, Options{Keys: []*Key{old, current}, SigningKey: "2024-06"})
+
+	oldPair, err := before.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.Parse(oldPair.AccessToken); err != nil {
+		t.Errorf("轮换后旧密钥签发的token: %v", err)
+	}
+	newPair, err := after.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h header
+	if err := decodeSegment(strings.Split(newPair.AccessToken, ".")[0], &h); err != nil || h.KeyID != "2024-06" || h.Algorithm != EdDSA {
+		t.Errorf("轮换后的头部 %+v, %v", h, err)
+	}
+	if _, err := before.Parse(newPair.AccessToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("未知的kid: 错误 %v，期望 ErrUnknownKey", err)
+	}
+
+	// 只有公钥的密钥只能用于验证
+	verifyOnly, err := NewEd25519Key("verify", nil, current.publicKey.(ed25519.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewDiscardLogger()
+	if _, err := New(Options{Keys: []*Key{verifyOnly}}, nil, logger); err == nil {
+		t.Error("只有公钥的签名密钥应返回错误")
+	}
+	if _, err := New(Options{Keys: []*Key{old, old}}, nil, logger); err == nil {
+		t.Error("重复的密钥ID应返回错误")
+	}
+	if _, err := New(Options{Keys: []*Key{old}, SigningKey: "missing"}, nil, logger); err == nil {
+		t.Error("不存在的签名密钥应返回错误")
+	}
+}
+
+func TestClaimsValidation(t *testing.T) {
+	m := newTestManager(t, Options{
+		Keys:     []*Key{hmacKey(t, "hs", "secret")},
+		Issuer:   "go-frame",
+		Audience: []string{"web"},
+		Leeway:   30 * time.Second,
+	})
+	now := time.Now()
+	valid := Claims{Issuer: "go-frame", Audience: Audience{"app", "web"}, Type: AccessToken, ExpiresAt: now.Add(time.Minute).Unix()}
+
+	cases := []struct {
+		name   string
+		modify func(c *Claims)
+		want   error
+	}{
+		{"有效", func(c *Claims) {}, nil},
+		{"在允许误差内过期", func(c *Claims) { c.ExpiresAt = now.Add(-10 * time.Second).Unix() }, nil},
+		{"已过期", func(c *Claims) { c.ExpiresAt = now.Add(-time.Minute).Unix() }, ErrTokenExpired},
+		{"没有过期时间", func(c *Claims) { c.ExpiresAt = 0 }, ErrTokenExpired},
+		{"尚未生效", func(c *Claims) { c.NotBefore = now.Add(time.Minute).Unix() }, ErrTokenNotValidYet},
+		{"刷新token", func(c *Claims) { c.Type = RefreshToken }, ErrTokenType},
+		{"签发者不匹配", func(c *Claims) { c.Issuer = "other" }, ErrTokenClaims},
+		{"接收者不匹配", func(c *Claims) { c.Audience = Audience{"app"} }, ErrTokenClaims},
+	}
+	for _, tc := range cases {
+		claims := valid
+		tc.modify(&claims)
+		token, err := m.sign(&claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Parse(token); !errors.Is(err, tc.want) {
+			t.Errorf("%s: 错误 %v，期望 %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestRefreshReuse(t *testing.T) {
+	m := newTestManager(t, Options{Keys: []*Key{hmacKey(t, "hs", "secret")}})
+	ctx := context.Background()
+	pair, err := m.IssuePair("1", map[string]interface{}{"role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Refresh(ctx, pair.AccessToken); !errors.Is(err, ErrTokenType) {
+		t.Errorf("使用访问token刷新: 错误 %v，期望 ErrTokenType", err)
+	}
+	next, err := m.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("刷新失败: %v", err)
+	}
+	claims, err := m.Parse(next.AccessToken)
+	if err != nil || claims.Data["role"] != "admin" {
+		t.Errorf("刷新后的声明 %+v, %v", claims, err)
+	}
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("重复使用刷新token: 错误 %v，期望 ErrTokenRevoked", err)
+	}
+
+	// 并发刷新时只有一个请求成功
+	pair, err = m.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Refresh(ctx, pair.RefreshToken); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Errorf("并发刷新成功 %d 次，期望 1", succeeded)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	m := newTestManager(t, Options{Keys: []*Key{hmacKey(t, "hs", "secret")}})
+	ctx := context.Background()
+
+	pair, err := m.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Revoke(ctx, pair.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("撤销后刷新: 错误 %v，期望 ErrTokenRevoked", err)
+	}
+
+	// 撤销用户之前签发的所有刷新token
+	first, _ := m.IssuePair("2", nil)
+	other, _ := m.IssuePair("3", nil)
+	if err := m.RevokeSubject(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("撤销用户后刷新: 错误 %v，期望 ErrTokenRevoked", err)
+	}
+	if _, err := m.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Errorf("其他用户的刷新token: %v", err)
+	}
+
+	// 撤销后立即签发的token与撤销在同一秒内，仍然可以刷新
+	next, err := m.IssuePair("2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Refresh(ctx, next.RefreshToken); err != nil {
+		t.Errorf("撤销用户后签发的刷新token: %v", err)
+	}
+}
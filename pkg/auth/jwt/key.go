@@ -0,0 +1,208 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+)
+
+// 支持的签名算法
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key 签名密钥，通过ID（kid）区分，轮换密钥时旧密钥继续用于验证未过期的token
+type Key struct {
+	// ID 密钥ID，写入token头部的kid
+	ID string
+	// Algorithm 签名算法
+	Algorithm string
+
+	secret     []byte
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+// NewHMACKey 创建HS256密钥
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("JWT密钥%s的secret为空", id)
+	}
+	return &Key{ID: id, Algorithm: HS256, secret: secret}, nil
+}
+
+// NewRSAKey 创建RS256密钥，只用于验证时privateKey可以为nil
+func NewRSAKey(id string, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (*Key, error) {
+	key := &Key{ID: id, Algorithm: RS256}
+	if privateKey != nil {
+		key.privateKey = privateKey
+		publicKey = &privateKey.PublicKey
+	}
+	if publicKey == nil {
+		return nil, fmt.Errorf("JWT密钥%s缺少RSA公钥", id)
+	}
+	key.publicKey = publicKey
+	return key, nil
+}
+
+// NewEd25519Key 创建EdDSA密钥，只用于验证时privateKey可以为nil
+func NewEd25519Key(id string, privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) (*Key, error) {
+	key := &Key{ID: id, Algorithm: EdDSA}
+	if privateKey != nil {
+		key.privateKey = privateKey
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+	if publicKey == nil {
+		return nil, fmt.Errorf("JWT密钥%s缺少Ed25519公钥", id)
+	}
+	key.publicKey = publicKey
+	return key, nil
+}
+
+// ParseKey 根据配置创建密钥，PEM密钥可以直接写在配置中，也可以是文件路径
+func ParseKey(cfg config.JWTKeyConfig) (*Key, error) {
+	switch strings.ToUpper(cfg.Algorithm) {
+	case "", HS256:
+		return NewHMACKey(cfg.ID, []byte(cfg.Secret))
+	case RS256:
+		private, public, err := parsePEMKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			privateKey *rsa.PrivateKey
+			publicKey  *rsa.PublicKey
+			ok         bool
+		)
+		if private != nil {
+			if privateKey, ok = private.(*rsa.PrivateKey); !ok {
+				return nil, fmt.Errorf("JWT密钥%s的私钥不是RSA私钥", cfg.ID)
+			}
+		}
+		if public != nil {
+			if publicKey, ok = public.(*rsa.PublicKey); !ok {
+				return nil, fmt.Errorf("JWT密钥%s的公钥不是RSA公钥", cfg.ID)
+			}
+		}
+		return NewRSAKey(cfg.ID, privateKey, publicKey)
+	case strings.ToUpper(EdDSA):
+		private, public, err := parsePEMKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			privateKey ed25519.PrivateKey
+			publicKey  ed25519.PublicKey
+			ok         bool
+		)
+		if private != nil {
+			if privateKey, ok = private.(ed25519.PrivateKey); !ok {
+				return nil, fmt.Errorf("JWT密钥%s的私钥不是Ed25519私钥", cfg.ID)
+			}
+		}
+		if public != nil {
+			if publicKey, ok = public.(ed25519.PublicKey); !ok {
+				return nil, fmt.Errorf("JWT密钥%s的公钥不是Ed25519公钥", cfg.ID)
+			}
+		}
+		return NewEd25519Key(cfg.ID, privateKey, publicKey)
+	}
+	return nil, fmt.Errorf("JWT密钥%s使用了不支持的签名算法: %s", cfg.ID, cfg.Algorithm)
+}
+
+// canSign 密钥是否可以签名
+func (k *Key) canSign() bool {
+	return k.secret != nil || k.privateKey != nil
+}
+
+// sign 签名
+func (k *Key) sign(input []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		sum := sha256.Sum256(input)
+		return k.privateKey.Sign(rand.Reader, sum[:], crypto.SHA256)
+	case EdDSA:
+		return k.privateKey.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	return nil, fmt.Errorf("不支持的签名算法: %s", k.Algorithm)
+}
+
+// verify 验证签名
+func (k *Key) verify(input, signature []byte) bool {
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write(input)
+		return hmac.Equal(signature, mac.Sum(nil))
+	case RS256:
+		sum := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(k.publicKey.(*rsa.PublicKey), crypto.SHA256, sum[:], signature) == nil
+	case EdDSA:
+		return ed25519.Verify(k.publicKey.(ed25519.PublicKey), input, signature)
+	}
+	return false
+}
+
+// parsePEMKeys 读取配置中的PEM私钥和公钥
+func parsePEMKeys(cfg config.JWTKeyConfig) (crypto.PrivateKey, crypto.PublicKey, error) {
+	var (
+		private crypto.PrivateKey
+		public  crypto.PublicKey
+	)
+	if cfg.PrivateKey != "" {
+		block, err := readPEM(cfg.PrivateKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取JWT密钥%s的私钥失败: %w", cfg.ID, err)
+		}
+		if private, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			if private, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return nil, nil, fmt.Errorf("解析JWT密钥%s的私钥失败: %w", cfg.ID, err)
+			}
+		}
+	}
+	if cfg.PublicKey != "" {
+		block, err := readPEM(cfg.PublicKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取JWT密钥%s的公钥失败: %w", cfg.ID, err)
+		}
+		if public, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			if public, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+				return nil, nil, fmt.Errorf("解析JWT密钥%s的公钥失败: %w", cfg.ID, err)
+			}
+		}
+	}
+	return private, public, nil
+}
+
+// readPEM 读取PEM数据，value不是PEM格式时作为文件路径读取
+func readPEM(value string) (*pem.Block, error) {
+	data := []byte(value)
+	if !strings.Contains(value, "-----BEGIN") {
+		var err error
+		if data, err = os.ReadFile(value); err != nil {
+			return nil, err
+		}
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("不是有效的PEM数据")
+	}
+	return block, nil
+}
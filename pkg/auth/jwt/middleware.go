@@ -0,0 +1,86 @@
+package jwt
+
+import (
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// headerKey token来自请求头时认证中间件在上下文数据中保存的标记
+const headerKey = "jwt_header"
+
+// tokenLookup 从请求中读取token的位置
+type tokenLookup struct {
+	header string
+	scheme string
+	query  string
+	cookie string
+}
+
+// Middleware 认证中间件，验证访问token并保存Claims，例如:
+//
+//	api := router.Group("/api", jwtManager.Middleware())
+//	api.GET("/profile", func(c unified.Context) error {
+//		claims, _ := jwt.GetClaims(c)
+//		return c.JSON(200, claims.Subject)
+//	})
+//
+// token依次从请求头（默认Authorization: Bearer）、查询参数和Cookie中读取，
+// 没有token时返回response.TokenEmpty，token过期时返回response.TokenExpired，其他错误返回response.TokenInvalid；
+// 验证通过后Claims保存到上下文数据ClaimsKey和请求的context.Context中，用户ID（sub）保存到user_id，供ratelimit.ByUser使用
+func (m *Manager) Middleware() unified.MiddlewareFunc {
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			token, fromHeader, ok := m.lookup.extract(c)
+			if !ok {
+				c.SetHeader("WWW-Authenticate", "Bearer")
+				return response.TokenEmpty
+			}
+			claims, err := m.Parse(token)
+			if err != nil {
+				c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+				return err
+			}
+
+			c.Set(ClaimsKey, claims)
+			if fromHeader {
+				c.Set(headerKey, true)
+			}
+			c.Set(unified.UserIDKey, claims.Subject)
+			c.SetContext(withClaims(c.Context(), claims))
+			return next(c)
+		}
+	}
+}
+
+// HeaderAuthenticated 判断请求是否已由认证中间件通过请求头中的token认证，
+// 浏览器跨站请求不会自动携带该请求头，token来自查询参数或Cookie时返回false
+func HeaderAuthenticated(c unified.Context) bool {
+	v, ok := c.Get(headerKey)
+	return ok && v == true
+}
+
+// extract 读取请求中的token，fromHeader表示token来自请求头，请求头的前缀不匹配时视为没有token
+func (l tokenLookup) extract(c unified.Context) (token string, fromHeader bool, ok bool) {
+	if value := strings.TrimSpace(c.GetHeader(l.header)); value != "" {
+		if l.scheme == "" {
+			return value, true, true
+		}
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, l.scheme) && strings.TrimSpace(token) != "" {
+			return strings.TrimSpace(token), true, true
+		}
+	}
+	if l.query != "" {
+		if token := c.Query(l.query); token != "" {
+			return token, false, true
+		}
+	}
+	if l.cookie != "" {
+		if token, err := c.Cookie(l.cookie); err == nil && token != "" {
+			return token, false, true
+		}
+	}
+	return "", false, false
+}
@@ -0,0 +1,8 @@
+package jwt
+
+import "go.uber.org/fx"
+
+// Module JWT认证模块，提供*Manager，需要配置jwt.keys
+var Module = fx.Options(
+	fx.Provide(NewManager),
+)
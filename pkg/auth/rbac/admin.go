@@ -0,0 +1,151 @@
+package rbac
+
+import (
+	"context"
+	"sort"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// RoleIDReq 角色ID请求
+type RoleIDReq struct {
+	ID uint `uri:"id" binding:"required,min=1"`
+}
+
+// RoleReq 创建或修改角色的请求
+type RoleReq struct {
+	ID          uint     `uri:"id" json:"-"`
+	Name        string   `json:"name" binding:"required,max=64" msg:"请输入角色标识"`
+	Title       string   `json:"title" binding:"max=100"`
+	Description string   `json:"description" binding:"max=500"`
+	Permissions []string `json:"permissions"`
+}
+
+// UserIDReq 用户ID请求
+type UserIDReq struct {
+	UserID string `uri:"user_id" binding:"required"`
+}
+
+// UserRolesReq 设置用户角色的请求
+type UserRolesReq struct {
+	UserID  string `uri:"user_id" json:"-" binding:"required"`
+	RoleIDs []uint `json:"role_ids"`
+}
+
+// AdminHandler 角色管理接口，挂载到带有认证和授权中间件的路由组，例如:
+//
+//	admin := router.Group("/admin", jwtManager.Middleware(), rbacManager.Middleware())
+//	rbacAdmin.RegisterRoutes(admin.Group("/rbac"))
+//
+// 接口声明了rbac:role:*和rbac:user:*权限，拥有"rbac:*"权限的角色可以管理所有角色
+type AdminHandler struct {
+	manager *Manager
+	routes  func() []unified.RouteInfo
+}
+
+// NewAdminHandler 创建角色管理接口
+func NewAdminHandler(manager *Manager) *AdminHandler {
+	return &AdminHandler{manager: manager}
+}
+
+// RegisterRoutes 注册角色管理路由
+func (h *AdminHandler) RegisterRoutes(r unified.Router) {
+	h.routes = r.Routes
+
+	r.GET("/roles", unified.Handle(h.ListRoles)).
+		Doc(unified.RouteDoc{Summary: "获取角色列表", Tags: []string{"rbac"}, Response: []*Role{}}).
+		Permission("rbac:role:list")
+	r.GET("/roles/:id", unified.Handle(h.GetRole)).
+		Doc(unified.RouteDoc{Summary: "获取角色详情", Tags: []string{"rbac"}, Request: RoleIDReq{}, Response: Role{}}).
+		Permission("rbac:role:list")
+	r.POST("/roles", unified.Handle(h.CreateRole)).
+		Doc(unified.RouteDoc{Summary: "创建角色", Tags: []string{"rbac"}, Request: RoleReq{}, Response: Role{}}).
+		Permission("rbac:role:create")
+	r.PUT("/roles/:id", unified.Handle(h.UpdateRole)).
+		Doc(unified.RouteDoc{Summary: "修改角色及其权限", Tags: []string{"rbac"}, Request: RoleReq{}, Response: Role{}}).
+		Permission("rbac:role:update")
+	r.DELETE("/roles/:id", unified.Handle(h.DeleteRole)).
+		Doc(unified.RouteDoc{Summary: "删除角色", Tags: []string{"rbac"}, Request: RoleIDReq{}}).
+		Permission("rbac:role:delete")
+	r.GET("/permissions", unified.Handle(h.ListPermissions)).
+		Doc(unified.RouteDoc{Summary: "获取可分配的权限，包括路由声明的权限", Tags: []string{"rbac"}, Response: []string{}}).
+		Permission("rbac:role:list")
+	r.GET("/users/:user_id/roles", unified.Handle(h.UserRoles)).
+		Doc(unified.RouteDoc{Summary: "获取用户的角色", Tags: []string{"rbac"}, Request: UserIDReq{}, Response: []*Role{}}).
+		Permission("rbac:user:list")
+	r.PUT("/users/:user_id/roles", unified.Handle(h.SetUserRoles)).
+		Doc(unified.RouteDoc{Summary: "设置用户的角色", Tags: []string{"rbac"}, Request: UserRolesReq{}, Response: []*Role{}}).
+		Permission("rbac:user:assign")
+}
+
+// ListRoles 获取角色列表
+func (h *AdminHandler) ListRoles(c unified.Context, _ *unified.Empty) ([]*Role, error) {
+	return h.manager.ListRoles(c.Context())
+}
+
+// GetRole 获取角色详情
+func (h *AdminHandler) GetRole(c unified.Context, req *RoleIDReq) (*Role, error) {
+	return h.manager.GetRole(c.Context(), req.ID)
+}
+
+// CreateRole 创建角色
+func (h *AdminHandler) CreateRole(c unified.Context, req *RoleReq) (*Role, error) {
+	role := &Role{Name: req.Name, Title: req.Title, Description: req.Description}
+	if err := h.manager.CreateRole(c.Context(), role, req.Permissions); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole 修改角色及其权限
+func (h *AdminHandler) UpdateRole(c unified.Context, req *RoleReq) (*Role, error) {
+	role := &Role{Name: req.Name, Title: req.Title, Description: req.Description}
+	role.ID = req.ID
+	if err := h.manager.UpdateRole(c.Context(), role, req.Permissions); err != nil {
+		return nil, err
+	}
+	return h.manager.GetRole(c.Context(), req.ID)
+}
+
+// DeleteRole 删除角色
+func (h *AdminHandler) DeleteRole(c unified.Context, req *RoleIDReq) (unified.Empty, error) {
+	return unified.Empty{}, h.manager.DeleteRole(c.Context(), req.ID)
+}
+
+// ListPermissions 获取已保存的权限和路由声明的权限
+func (h *AdminHandler) ListPermissions(c unified.Context, _ *unified.Empty) ([]string, error) {
+	return h.permissions(c.Context())
+}
+
+// UserRoles 获取用户的角色
+func (h *AdminHandler) UserRoles(c unified.Context, req *UserIDReq) ([]*Role, error) {
+	return h.manager.UserRoles(c.Context(), req.UserID)
+}
+
+// SetUserRoles 设置用户的角色
+func (h *AdminHandler) SetUserRoles(c unified.Context, req *UserRolesReq) ([]*Role, error) {
+	if err := h.manager.SetUserRoles(c.Context(), req.UserID, req.RoleIDs...); err != nil {
+		return nil, err
+	}
+	return h.manager.UserRoles(c.Context(), req.UserID)
+}
+
+// permissions 合并数据库中的权限和路由声明的权限，按名称排序
+func (h *AdminHandler) permissions(ctx context.Context) ([]string, error) {
+	saved, err := h.manager.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(saved))
+	for _, p := range saved {
+		names = append(names, p.Name)
+	}
+	if h.routes != nil {
+		for _, route := range h.routes() {
+			names = append(names, route.Permissions...)
+		}
+	}
+	names = unique(names)
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,49 @@
+package rbac
+
+import "github.com/zhoudm1743/go-frame/pkg/types"
+
+// Role 角色
+type Role struct {
+	types.GormModel
+	Name        string       `json:"name" gorm:"size:64;uniqueIndex;not null;comment:角色标识"`
+	Title       string       `json:"title" gorm:"size:100;comment:角色名称"`
+	Description string       `json:"description" gorm:"size:500;comment:角色描述"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:rbac_role_permissions"`
+}
+
+// TableName 表名
+func (Role) TableName() string {
+	return "rbac_roles"
+}
+
+// PermissionNames 角色的权限名称
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Permission 权限，名称通常为"资源:操作"，例如"user:delete"，"user:*"表示资源的所有操作，"*"表示所有权限
+type Permission struct {
+	types.GormModel
+	Name        string `json:"name" gorm:"size:128;uniqueIndex;not null;comment:权限标识"`
+	Description string `json:"description" gorm:"size:500;comment:权限描述"`
+}
+
+// TableName 表名
+func (Permission) TableName() string {
+	return "rbac_permissions"
+}
+
+// UserRole 用户和角色的关联，用户ID与认证中间件保存的user_id一致
+type UserRole struct {
+	UserID string `json:"user_id" gorm:"primaryKey;size:64;comment:用户ID"`
+	RoleID uint   `json:"role_id" gorm:"primaryKey;index;comment:角色ID"`
+}
+
+// TableName 表名
+func (UserRole) TableName() string {
+	return "rbac_user_roles"
+}
@@ -0,0 +1,9 @@
+package rbac
+
+import "go.uber.org/fx"
+
+// Module 权限模块，提供*Manager和*AdminHandler，角色管理接口需要自行挂载到带有认证中间件的路由组
+var Module = fx.Options(
+	fx.Provide(NewManager),
+	fx.Provide(NewAdminHandler),
+)
@@ -0,0 +1,155 @@
+package rbac
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+	"gorm.io/gorm"
+)
+
+// 策略缓存键
+const (
+	versionKey = "rbac:version"
+	userPrefix = "rbac:user:"
+)
+
+// policyTTL 用户权限的缓存时长
+const policyTTL = 10 * time.Minute
+
+// Manager 基于角色的权限管理，角色、权限和用户角色保存在数据库中，用户的权限缓存在缓存中，
+// 修改角色的权限后所有用户的缓存失效，修改用户的角色后该用户的缓存失效
+type Manager struct {
+	db     *gorm.DB
+	cache  cache.Cache
+	logger log.Logger
+}
+
+// NewManager 创建权限管理并迁移数据表
+func NewManager(db *gorm.DB, cache cache.Cache, logger log.Logger) (*Manager, error) {
+	if err := db.AutoMigrate(&Permission{}, &Role{}, &UserRole{}); err != nil {
+		return nil, fmt.Errorf("迁移权限数据表失败: %w", err)
+	}
+	return &Manager{
+		db:     db,
+		cache:  cache,
+		logger: logger,
+	}, nil
+}
+
+// Middleware 授权中间件，检查路由通过Router.Permission声明的权限，需要在认证中间件之后执行，例如:
+//
+//	admin := router.Group("/admin", jwtManager.Middleware(), rbacManager.Middleware())
+//	admin.DELETE("/users/:id", handler).Permission("user:delete")
+//
+// 用户ID从上下文数据user_id读取，没有声明权限的路由直接放行，
+// 用户未登录或缺少任一声明的权限时返回response.NoPermission
+func (m *Manager) Middleware() unified.MiddlewareFunc {
+	return unified.Authorize(m.authorize)
+}
+
+// authorize 检查当前用户是否拥有路由声明的所有权限
+func (m *Manager) authorize(c unified.Context, required []string) error {
+	v, ok := c.Get(unified.UserIDKey)
+	if !ok || v == nil || fmt.Sprint(v) == "" {
+		return response.NoPermission
+	}
+	allowed, err := m.Can(c.Context(), fmt.Sprint(v), required...)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return response.NoPermission
+	}
+	return nil
+}
+
+// Can 判断用户是否拥有所有指定的权限
+func (m *Manager) Can(ctx context.Context, userID string, permissions ...string) (bool, error) {
+	granted, err := m.Permissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if !match(granted, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Permissions 获取用户通过角色获得的所有权限，优先读取缓存
+func (m *Manager) Permissions(ctx context.Context, userID string) ([]string, error) {
+	key := m.userKey(ctx, userID)
+	if data, err := m.cache.GetCtx(ctx, key); err == nil {
+		var permissions []string
+		if err := json.Unmarshal([]byte(data), &permissions); err == nil {
+			return permissions, nil
+		}
+	} else if !cache.IsMiss(err) {
+		m.logger.WithContext(ctx).WithError(err).Warn("读取权限缓存失败")
+	}
+
+	var permissions []string
+	err := m.db.WithContext(ctx).Model(&Permission{}).Distinct("rbac_permissions.name").
+		Joins("JOIN rbac_role_permissions ON rbac_role_permissions.permission_id = rbac_permissions.id").
+		Joins("JOIN rbac_user_roles ON rbac_user_roles.role_id = rbac_role_permissions.role_id").
+		Where("rbac_user_roles.user_id = ?", userID).
+		Pluck("rbac_permissions.name", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	data, _ := json.Marshal(permissions)
+	if err := m.cache.SetCtx(ctx, key, string(data), policyTTL); err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("保存权限缓存失败")
+	}
+	return permissions, nil
+}
+
+// userKey 用户权限的缓存键，包含策略版本，版本变化后旧的缓存不再使用并自然过期
+func (m *Manager) userKey(ctx context.Context, userID string) string {
+	// 加0读取版本，各种缓存中计数器的读取方式一致
+	version, err := m.cache.IncrByCtx(ctx, versionKey, 0)
+	if err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("读取权限缓存版本失败")
+	}
+	return userPrefix + strconv.FormatInt(version, 10) + ":" + userID
+}
+
+// invalidateAll 修改角色的权限后使所有用户的权限缓存失效
+func (m *Manager) invalidateAll(ctx context.Context) {
+	if _, err := m.cache.IncrByCtx(ctx, versionKey, 1); err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("更新权限缓存版本失败")
+	}
+}
+
+// invalidateUser 修改用户的角色后使该用户的权限缓存失效
+func (m *Manager) invalidateUser(ctx context.Context, userID string) {
+	if _, err := m.cache.DelCtx(ctx, m.userKey(ctx, userID)); err != nil {
+		m.logger.WithContext(ctx).WithError(err).Warn("删除权限缓存失败")
+	}
+}
+
+// match 判断已有的权限是否包含指定的权限，"*"匹配所有权限，"user:*"匹配"user:"开头的权限
+func match(granted []string, permission string) bool {
+	for _, g := range granted {
+		if g == permission || g == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(g, "*"); ok && strings.HasPrefix(permission, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package rbac
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		granted    []string
+		permission string
+		want       bool
+	}{
+		{nil, "user:read", false},
+		{[]string{"user:read"}, "user:read", true},
+		{[]string{"user:read"}, "user:write", false},
+		{[]string{"user:read"}, "user:read:all", false},
+		{[]string{"*"}, "user:read", true},
+		{[]string{"*"}, "", true},
+		{[]string{"user:*"}, "user:read", true},
+		{[]string{"user:*"}, "user:role:assign", true},
+		{[]string{"user:*"}, "users:read", false},
+		{[]string{"user:*"}, "user", false},
+		{[]string{"user:*"}, "order:read", false},
+		{[]string{"user:read", "order:*"}, "order:delete", true},
+		// "*"只作为结尾的通配符，出现在中间时按字面匹配
+		{[]string{"user:*:read"}, "user:role:read", false},
+		{[]string{"user:*:read"}, "user:*:read", true},
+	}
+	for _, tc := range cases {
+		if got := match(tc.granted, tc.permission); got != tc.want {
+			t.Errorf("match(%q, %q) = %v，期望 %v", tc.granted, tc.permission, got, tc.want)
+		}
+	}
+}
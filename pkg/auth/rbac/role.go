@@ -0,0 +1,255 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zhoudm1743/go-frame/pkg/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 角色管理的错误
+var (
+	ErrRoleNotFound  = response.Request404Error.Make("角色不存在")
+	ErrRoleDuplicate = response.RequestErrDuplicateNameError.Make("角色标识已存在")
+)
+
+// ListRoles 获取所有角色及其权限
+func (m *Manager) ListRoles(ctx context.Context) ([]*Role, error) {
+	var roles []*Role
+	err := m.db.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// GetRole 根据ID获取角色及其权限
+func (m *Manager) GetRole(ctx context.Context, id uint) (*Role, error) {
+	return m.findRole(ctx, m.db.Where("id = ?", id))
+}
+
+// GetRoleByName 根据角色标识获取角色及其权限
+func (m *Manager) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	return m.findRole(ctx, m.db.Where("name = ?", name))
+}
+
+// CreateRole 创建角色并设置权限，权限不存在时自动创建
+func (m *Manager) CreateRole(ctx context.Context, role *Role, permissions []string) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Role{}).Where("name = ?", role.Name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleDuplicate
+		}
+		perms, err := ensurePermissions(tx, permissions)
+		if err != nil {
+			return err
+		}
+		role.Permissions = perms
+		return tx.Create(role).Error
+	})
+}
+
+// UpdateRole 修改角色的标识、名称和描述，并将权限替换为permissions
+func (m *Manager) UpdateRole(ctx context.Context, role *Role, permissions []string) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Role{}).Where("name = ? AND id <> ?", role.Name, role.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrRoleDuplicate
+		}
+		result := tx.Model(&Role{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
+			"name":        role.Name,
+			"title":       role.Title,
+			"description": role.Description,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if err := tx.Select("id").First(&Role{}, role.ID).Error; err != nil {
+				return roleError(err)
+			}
+		}
+		perms, err := ensurePermissions(tx, permissions)
+		if err != nil {
+			return err
+		}
+		role.Permissions = perms
+		return tx.Model(role).Association("Permissions").Replace(perms)
+	})
+	if err != nil {
+		return err
+	}
+	m.invalidateAll(ctx)
+	return nil
+}
+
+// DeleteRole 删除角色，同时删除角色的权限关联和用户关联
+func (m *Manager) DeleteRole(ctx context.Context, id uint) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := &Role{}
+		if err := tx.First(role, id).Error; err != nil {
+			return roleError(err)
+		}
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+	if err != nil {
+		return err
+	}
+	m.invalidateAll(ctx)
+	return nil
+}
+
+// EnsureRole 角色不存在时创建，并为角色添加缺少的权限，用于初始化超级管理员等内置角色，例如:
+//
+//	manager.EnsureRole(ctx, "admin", "*")
+func (m *Manager) EnsureRole(ctx context.Context, name string, permissions ...string) (*Role, error) {
+	role := &Role{Name: name}
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(Role{Name: name}).FirstOrCreate(role).Error; err != nil {
+			return err
+		}
+		perms, err := ensurePermissions(tx, permissions)
+		if err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Permissions").Append(perms)
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.invalidateAll(ctx)
+	return m.GetRole(ctx, role.ID)
+}
+
+// ListPermissions 获取所有已保存的权限
+func (m *Manager) ListPermissions(ctx context.Context) ([]*Permission, error) {
+	var permissions []*Permission
+	err := m.db.WithContext(ctx).Order("name").Find(&permissions).Error
+	return permissions, err
+}
+
+// UserRoles 获取用户的角色
+func (m *Manager) UserRoles(ctx context.Context, userID string) ([]*Role, error) {
+	var roles []*Role
+	err := m.db.WithContext(ctx).Preload("Permissions").
+		Where("id IN (?)", m.db.Model(&UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// AssignRoles 为用户添加角色，已有的角色忽略
+func (m *Manager) AssignRoles(ctx context.Context, userID string, roleIDs ...uint) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return assignRoles(tx, userID, roleIDs)
+	})
+	if err != nil {
+		return err
+	}
+	m.invalidateUser(ctx, userID)
+	return nil
+}
+
+// RemoveRoles 移除用户的角色
+func (m *Manager) RemoveRoles(ctx context.Context, userID string, roleIDs ...uint) error {
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	err := m.db.WithContext(ctx).Where("user_id = ? AND role_id IN ?", userID, roleIDs).Delete(&UserRole{}).Error
+	if err != nil {
+		return err
+	}
+	m.invalidateUser(ctx, userID)
+	return nil
+}
+
+// SetUserRoles 将用户的角色替换为roleIDs
+func (m *Manager) SetUserRoles(ctx context.Context, userID string, roleIDs ...uint) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&UserRole{}).Error; err != nil {
+			return err
+		}
+		return assignRoles(tx, userID, roleIDs)
+	})
+	if err != nil {
+		return err
+	}
+	m.invalidateUser(ctx, userID)
+	return nil
+}
+
+// findRole 查询角色及其权限
+func (m *Manager) findRole(ctx context.Context, query *gorm.DB) (*Role, error) {
+	role := &Role{}
+	if err := query.WithContext(ctx).Preload("Permissions").First(role).Error; err != nil {
+		return nil, roleError(err)
+	}
+	return role, nil
+}
+
+// assignRoles 检查角色是否存在并添加用户角色
+func assignRoles(tx *gorm.DB, userID string, roleIDs []uint) error {
+	roleIDs = unique(roleIDs)
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&Role{}).Where("id IN ?", roleIDs).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) != len(roleIDs) {
+		return ErrRoleNotFound
+	}
+	userRoles := make([]UserRole, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		userRoles = append(userRoles, UserRole{UserID: userID, RoleID: id})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&userRoles).Error
+}
+
+// ensurePermissions 获取权限记录，不存在的权限自动创建
+func ensurePermissions(tx *gorm.DB, names []string) ([]Permission, error) {
+	names = unique(names)
+	perms := make([]Permission, 0, len(names))
+	for _, name := range names {
+		perm := Permission{Name: name}
+		if err := tx.Where(Permission{Name: name}).FirstOrCreate(&perm).Error; err != nil {
+			return nil, err
+		}
+		perms = append(perms, perm)
+	}
+	return perms, nil
+}
+
+// roleError 将记录不存在的错误转换为ErrRoleNotFound
+func roleError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoleNotFound
+	}
+	return err
+}
+
+// unique 去除重复和空值，保持原有顺序
+func unique[T comparable](values []T) []T {
+	var zero T
+	seen := make(map[T]bool, len(values))
+	result := make([]T, 0, len(values))
+	for _, v := range values {
+		if v == zero || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
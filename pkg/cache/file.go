@@ -35,6 +35,9 @@ const (
 	zsetScoreBucket  = "zset_score"
 	expirationBucket = "expiration"
 
+	// 哈希、列表、集合和有序集合键的过期时间，键为"桶名\x00键名"
+	collectionExpirationBucket = "collection_expiration"
+
 	// 压缩相关常量
 	compressionThreshold = 4096    // 超过4KB的值进行压缩
 	compressionFlag      = byte(1) // 标记值是否被压缩
@@ -152,6 +155,7 @@ func NewFileCache(cfg *config.Config, logger log.Logger) (Cache, error) {
 			zsetBucket,
 			zsetScoreBucket,
 			expirationBucket,
+			collectionExpirationBucket,
 		}
 
 		for _, bucket := range buckets {
@@ -221,11 +225,24 @@ func cleanExpiredKeys(db *bbolt.DB, logger log.Logger) {
 					continue
 				}
 
-				// 删除每个过期的键
+				// 删除每个过期的键，重新设置过过期时间的键以最新的过期时间为准
 				for _, key := range keys {
 					bucketName, bucketKey := parseKey(key)
+					if bucketName != defaultBucket {
+						if exp := collectionExpiration(tx, bucketName, bucketKey); exp > 0 && exp <= now {
+							if err := deleteCollection(tx, bucketName, bucketKey); err != nil {
+								logger.Errorf("删除过期键失败: %v", err)
+							}
+						}
+						continue
+					}
 					bucket := tx.Bucket([]byte(bucketName))
 					if bucket != nil {
+						var item cacheItem
+						data := bucket.Get([]byte(bucketKey))
+						if data != nil && json.Unmarshal(data, &item) == nil && (item.Expiration == 0 || item.Expiration > now) {
+							continue
+						}
 						bucket.Delete([]byte(bucketKey))
 					}
 				}
@@ -303,10 +320,94 @@ func bytesToInt64(b []byte) int64 {
 
 // 解析键，返回桶名和实际键名
 func parseKey(key string) (string, string) {
+	// 集合类型的键带有桶名
+	if i := strings.IndexByte(key, 0); i >= 0 {
+		return key[:i], key[i+1:]
+	}
 	// 默认使用默认桶
 	return defaultBucket, key
 }
 
+// ================== 集合类型键的过期时间 ==================
+
+// collectionBuckets 哈希、列表、集合和有序集合的桶，每个键对应其中的一个子桶
+var collectionBuckets = []string{hashBucket, listBucket, setBucket, zsetBucket}
+
+// collectionKey 集合类型键在过期时间索引中的名称
+func collectionKey(bucketName, key string) string {
+	return bucketName + "\x00" + key
+}
+
+// findCollection 查找键所在的集合类型桶，不存在时返回空字符串
+func findCollection(tx *bbolt.Tx, key string) string {
+	for _, name := range collectionBuckets {
+		if bucket := tx.Bucket([]byte(name)); bucket != nil && bucket.Bucket([]byte(key)) != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// collectionExpiration 获取集合类型键的过期时间，0表示永不过期
+func collectionExpiration(tx *bbolt.Tx, bucketName, key string) int64 {
+	bucket := tx.Bucket([]byte(collectionExpirationBucket))
+	if bucket == nil {
+		return 0
+	}
+	data := bucket.Get([]byte(collectionKey(bucketName, key)))
+	if len(data) != 8 {
+		return 0
+	}
+	return bytesToInt64(data)
+}
+
+// setCollectionExpiration 设置集合类型键的过期时间，0表示永不过期
+func setCollectionExpiration(tx *bbolt.Tx, bucketName, key string, expiration int64) error {
+	bucket := tx.Bucket([]byte(collectionExpirationBucket))
+	if bucket == nil {
+		return fmt.Errorf("过期桶不存在")
+	}
+	name := collectionKey(bucketName, key)
+	if expiration == 0 {
+		return bucket.Delete([]byte(name))
+	}
+	if err := bucket.Put([]byte(name), int64ToBytes(expiration)); err != nil {
+		return err
+	}
+	return addKeyExpiration(tx, name, expiration)
+}
+
+// deleteCollection 删除集合类型键及其过期时间
+func deleteCollection(tx *bbolt.Tx, bucketName, key string) error {
+	names := []string{bucketName}
+	if bucketName == zsetBucket {
+		names = append(names, zsetScoreBucket)
+	}
+	for _, name := range names {
+		if parent := tx.Bucket([]byte(name)); parent != nil && parent.Bucket([]byte(key)) != nil {
+			if err := parent.DeleteBucket([]byte(key)); err != nil {
+				return err
+			}
+		}
+	}
+	if bucket := tx.Bucket([]byte(collectionExpirationBucket)); bucket != nil {
+		return bucket.Delete([]byte(collectionKey(bucketName, key)))
+	}
+	return nil
+}
+
+// collectionExpired 检查集合类型键是否已过期，写事务中同时删除已过期的键
+func collectionExpired(tx *bbolt.Tx, bucketName, key string) (bool, error) {
+	exp := collectionExpiration(tx, bucketName, key)
+	if exp == 0 || exp > time.Now().Unix() {
+		return false, nil
+	}
+	if tx.Writable() {
+		return true, deleteCollection(tx, bucketName, key)
+	}
+	return true, nil
+}
+
 // buildKey 构建带前缀的键
 func (f *FileCache) buildKey(key string) string {
 	if f.prefix == "" {
@@ -463,6 +564,120 @@ func (f *FileCache) Set(key string, value interface{}, expiration time.Duration)
 	return err
 }
 
+// SetNX 键不存在时设置缓存，返回是否设置成功
+func (f *FileCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	prefixedKey := f.buildKey(key)
+
+	var exp int64
+	if expiration > 0 {
+		exp = time.Now().Add(expiration).Unix()
+	}
+	item := cacheItem{
+		Value:      value,
+		Expiration: exp,
+	}
+
+	// 检查和写入在同一个事务中完成
+	set := false
+	err := f.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(defaultBucket))
+		if bucket == nil {
+			return fmt.Errorf("桶不存在")
+		}
+
+		current, err := loadItem(bucket, prefixedKey)
+		if err != nil || current != nil {
+			return err
+		}
+		if name := findCollection(tx, prefixedKey); name != "" {
+			expired, err := collectionExpired(tx, name, prefixedKey)
+			if err != nil || !expired {
+				return err
+			}
+		}
+
+		if expiration > 0 {
+			if err := addKeyExpiration(tx, prefixedKey, exp); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		compressedData, err := compressValue(data)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(prefixedKey), compressedData); err != nil {
+			return err
+		}
+		set = true
+		return nil
+	})
+
+	if err == nil && set {
+		f.memCache.Store(prefixedKey, item)
+	}
+
+	return set, err
+}
+
+// CompareAndDel 键的值等于value时删除，返回是否删除
+func (f *FileCache) CompareAndDel(key, value string) (bool, error) {
+	prefixedKey := f.buildKey(key)
+
+	deleted := false
+	err := f.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(defaultBucket))
+		if bucket == nil {
+			return fmt.Errorf("桶不存在")
+		}
+
+		current, err := loadItem(bucket, prefixedKey)
+		if err != nil || current == nil {
+			return err
+		}
+		if v, ok := current.Value.(string); !ok || v != value {
+			return nil
+		}
+		if err := bucket.Delete([]byte(prefixedKey)); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+
+	if err == nil && deleted {
+		f.memCache.Delete(prefixedKey)
+	}
+
+	return deleted, err
+}
+
+// loadItem 读取未过期的缓存项，键不存在或已过期时返回nil
+func loadItem(bucket *bbolt.Bucket, key string) (*cacheItem, error) {
+	data := bucket.Get([]byte(key))
+	if data == nil {
+		return nil, nil
+	}
+
+	decompressedData, err := decompressValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var item cacheItem
+	if err := json.Unmarshal(decompressedData, &item); err != nil {
+		return nil, err
+	}
+	if item.Expiration > 0 && item.Expiration <= time.Now().Unix() {
+		return nil, nil
+	}
+
+	return &item, nil
+}
+
 // Del 删除缓存
 func (f *FileCache) Del(keys ...string) (int64, error) {
 	var count int64
@@ -483,6 +698,22 @@ func (f *FileCache) Del(keys ...string) (int64, error) {
 
 				// 同时删除内存缓存
 				f.memCache.Delete(prefixedKey)
+				continue
+			}
+
+			// 哈希、列表、集合和有序集合
+			if name := findCollection(tx, prefixedKey); name != "" {
+				expired, err := collectionExpired(tx, name, prefixedKey)
+				if err != nil {
+					return err
+				}
+				if expired {
+					continue
+				}
+				if err := deleteCollection(tx, name, prefixedKey); err != nil {
+					return err
+				}
+				count++
 			}
 		}
 
@@ -515,6 +746,14 @@ func (f *FileCache) Exists(keys ...string) (int64, error) {
 				if item.Expiration == 0 || item.Expiration > now {
 					count++
 				}
+				continue
+			}
+
+			// 哈希、列表、集合和有序集合
+			if name := findCollection(tx, f.buildKey(key)); name != "" {
+				if exp := collectionExpiration(tx, name, f.buildKey(key)); exp == 0 || exp > now {
+					count++
+				}
 			}
 		}
 
@@ -535,7 +774,7 @@ func (f *FileCache) Expire(key string, expiration time.Duration) error {
 		prefixedKey := f.buildKey(key)
 		data := bucket.Get([]byte(prefixedKey))
 		if data == nil {
-			return ErrKeyNotFound
+			return f.expireCollection(tx, prefixedKey, expiration)
 		}
 
 		var item cacheItem
@@ -565,6 +804,26 @@ func (f *FileCache) Expire(key string, expiration time.Duration) error {
 	})
 }
 
+// expireCollection 设置哈希、列表、集合和有序集合键的过期时间
+func (f *FileCache) expireCollection(tx *bbolt.Tx, key string, expiration time.Duration) error {
+	name := findCollection(tx, key)
+	if name == "" {
+		return ErrKeyNotFound
+	}
+	if expired, err := collectionExpired(tx, name, key); err != nil || expired {
+		if err == nil {
+			err = ErrKeyNotFound
+		}
+		return err
+	}
+
+	var exp int64
+	if expiration > 0 {
+		exp = time.Now().Add(expiration).Unix()
+	}
+	return setCollectionExpiration(tx, name, key, exp)
+}
+
 // TTL 获取剩余生存时间
 func (f *FileCache) TTL(key string) (time.Duration, error) {
 	var ttl time.Duration
@@ -574,14 +833,16 @@ func (f *FileCache) TTL(key string) (time.Duration, error) {
 			return fmt.Errorf("桶不存在")
 		}
 
-		data := bucket.Get([]byte(f.buildKey(key)))
-		if data == nil {
-			return ErrKeyNotFound // 已过期
-		}
-
 		var item cacheItem
-		if err := json.Unmarshal(data, &item); err != nil {
-			return err
+		if data := bucket.Get([]byte(f.buildKey(key))); data != nil {
+			if err := json.Unmarshal(data, &item); err != nil {
+				return err
+			}
+		} else if name := findCollection(tx, f.buildKey(key)); name != "" {
+			// 哈希、列表、集合和有序集合
+			item.Expiration = collectionExpiration(tx, name, f.buildKey(key))
+		} else {
+			return ErrKeyNotFound // 已过期
 		}
 
 		// 检查过期时间
@@ -704,6 +965,13 @@ func getHashBucket(tx *bbolt.Tx, key string) (*bbolt.Bucket, error) {
 		return nil, fmt.Errorf("哈希桶不存在")
 	}
 
+	// 已过期的键视为不存在，写事务中会重新创建
+	if expired, err := collectionExpired(tx, hashBucket, key); err != nil {
+		return nil, err
+	} else if expired && !tx.Writable() {
+		return nil, ErrKeyNotFound
+	}
+
 	// 创建或获取特定哈希表的子桶
 	var bucket *bbolt.Bucket
 	var err error
@@ -889,6 +1157,13 @@ func getListBucket(tx *bbolt.Tx, key string) (*bbolt.Bucket, error) {
 		return nil, fmt.Errorf("列表桶不存在")
 	}
 
+	// 已过期的键视为不存在，写事务中会重新创建
+	if expired, err := collectionExpired(tx, listBucket, key); err != nil {
+		return nil, err
+	} else if expired && !tx.Writable() {
+		return nil, ErrKeyNotFound
+	}
+
 	// 创建或获取特定列表的子桶
 	var bucket *bbolt.Bucket
 	var err error
@@ -1259,6 +1534,13 @@ func getSetBucket(tx *bbolt.Tx, key string) (*bbolt.Bucket, error) {
 		return nil, fmt.Errorf("集合桶不存在")
 	}
 
+	// 已过期的键视为不存在，写事务中会重新创建
+	if expired, err := collectionExpired(tx, setBucket, key); err != nil {
+		return nil, err
+	} else if expired && !tx.Writable() {
+		return nil, ErrKeyNotFound
+	}
+
 	// 创建或获取特定集合的子桶
 	var bucket *bbolt.Bucket
 	var err error
@@ -1458,6 +1740,24 @@ func (f *FileCache) SetCtx(ctx context.Context, key string, value interface{}, e
 	return f.Set(key, value, expiration)
 }
 
+// SetNXCtx 键不存在时设置缓存（带上下文）
+func (f *FileCache) SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	// 检查上下文是否已取消
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return f.SetNX(key, value, expiration)
+}
+
+// CompareAndDelCtx 键的值等于value时删除（带上下文）
+func (f *FileCache) CompareAndDelCtx(ctx context.Context, key, value string) (bool, error) {
+	// 检查上下文是否已取消
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return f.CompareAndDel(key, value)
+}
+
 // DelCtx 删除缓存（带上下文）
 func (f *FileCache) DelCtx(ctx context.Context, keys ...string) (int64, error) {
 	// 检查上下文是否已取消
@@ -1808,6 +2108,13 @@ func getZSetBucket(tx *bbolt.Tx, key string) (*bbolt.Bucket, error) {
 		return nil, fmt.Errorf("有序集合桶不存在")
 	}
 
+	// 已过期的键视为不存在，写事务中会重新创建
+	if expired, err := collectionExpired(tx, zsetBucket, key); err != nil {
+		return nil, err
+	} else if expired && !tx.Writable() {
+		return nil, ErrKeyNotFound
+	}
+
 	// 创建或获取特定有序集合的子桶
 	var bucket *bbolt.Bucket
 	var err error
@@ -1968,6 +2275,50 @@ func (f *FileCache) ZAdd(key string, members ...Z) (int64, error) {
 	return count, err
 }
 
+// ZRemRangeByScore 删除分数在[min, max]范围内的有序集合成员
+func (f *FileCache) ZRemRangeByScore(key string, min, max float64) (int64, error) {
+	var count int64
+	err := f.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := getZSetBucket(tx, f.buildKey(key))
+		if err != nil {
+			return err
+		}
+		scoreBucket, err := getZSetScoreBucket(tx, f.buildKey(key))
+		if err != nil {
+			return err
+		}
+
+		// 遍历时不能删除，先收集范围内的成员
+		var removed []zsetMember
+		err = bucket.ForEach(func(k, v []byte) error {
+			var member zsetMember
+			if err := json.Unmarshal(v, &member); err != nil {
+				return err
+			}
+			if member.Score >= min && member.Score <= max {
+				removed = append(removed, member)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, member := range removed {
+			if err := scoreBucket.Delete(float64ToSortableBytes(member.Score)); err != nil {
+				return err
+			}
+			if err := bucket.Delete([]byte(member.Value)); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
 // ZScore 返回有序集合中成员的分数值
 func (f *FileCache) ZScore(key string, member string) (float64, error) {
 	var score float64
@@ -2269,6 +2620,15 @@ func (f *FileCache) ZRangeWithScores(key string, start, stop int64) ([]Z, error)
 
 // ================== 带 Context 的有序集合操作 ==================
 
+// ZRemRangeByScoreCtx 删除分数在[min, max]范围内的有序集合成员（带上下文）
+func (f *FileCache) ZRemRangeByScoreCtx(ctx context.Context, key string, min, max float64) (int64, error) {
+	// 检查上下文是否已取消
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.ZRemRangeByScore(key, min, max)
+}
+
 // ZAddCtx 将一个或多个成员元素及其分数值加入到有序集合中（带上下文）
 func (f *FileCache) ZAddCtx(ctx context.Context, key string, members ...Z) (int64, error) {
 	// 检查上下文是否已取消
@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -56,15 +58,29 @@ func (h *CacheHelper) RememberJSON(key string, expiration time.Duration, dest in
 }
 
 // Lock 分布式锁
+//
+// Deprecated: 不记录持有者，使用TryLock
 func (h *CacheHelper) Lock(key string, expiration time.Duration) (bool, error) {
 	return h.LockCtx(context.Background(), key, expiration)
 }
 
 // Unlock 释放分布式锁
+//
+// Deprecated: 不检查持有者，使用Release
 func (h *CacheHelper) Unlock(key string) error {
 	return h.UnlockCtx(context.Background(), key)
 }
 
+// TryLock 获取分布式锁，成功时返回持有者token，锁已被占用时返回空字符串
+func (h *CacheHelper) TryLock(key string, expiration time.Duration) (string, error) {
+	return h.TryLockCtx(context.Background(), key, expiration)
+}
+
+// Release 使用TryLock返回的token释放分布式锁
+func (h *CacheHelper) Release(key, token string) error {
+	return h.ReleaseCtx(context.Background(), key, token)
+}
+
 // WithLock 使用分布式锁执行函数
 func (h *CacheHelper) WithLock(key string, expiration time.Duration, fn func() error) error {
 	return h.WithLockCtx(context.Background(), key, expiration, fn)
@@ -128,7 +144,7 @@ func (h *CacheHelper) RememberCtx(ctx context.Context, key string, expiration ti
 
 	// 缓存结果
 	if err := h.cache.SetCtx(ctx, fullKey, result, expiration); err != nil {
-		h.logger.WithFields(map[string]interface{}{
+		h.logger.WithContext(ctx).WithFields(map[string]interface{}{
 			"key":   fullKey,
 			"error": err,
 		}).Error("缓存设置失败")
@@ -154,7 +170,7 @@ func (h *CacheHelper) RememberJSONCtx(ctx context.Context, key string, expiratio
 
 	// 缓存结果
 	if err := h.SetJSONCtx(ctx, key, result, expiration); err != nil {
-		h.logger.WithFields(map[string]interface{}{
+		h.logger.WithContext(ctx).WithFields(map[string]interface{}{
 			"key":   fullKey,
 			"error": err,
 		}).Warn("缓存设置失败")
@@ -169,51 +185,92 @@ func (h *CacheHelper) RememberJSONCtx(ctx context.Context, key string, expiratio
 }
 
 // LockCtx 分布式锁
+//
+// Deprecated: 不记录持有者，使用TryLockCtx
 func (h *CacheHelper) LockCtx(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	fullKey := fmt.Sprintf("lock:%s", h.buildKey(key))
+	token, err := h.TryLockCtx(ctx, key, expiration)
+	return token != "", err
+}
+
+// UnlockCtx 释放分布式锁，不检查持有者
+//
+// Deprecated: 锁过期后被其他请求获取时也会被删除，使用ReleaseCtx
+func (h *CacheHelper) UnlockCtx(ctx context.Context, key string) error {
+	_, err := h.cache.DelCtx(ctx, h.lockKey(key))
+	return err
+}
+
+// TryLockCtx 获取分布式锁，成功时返回持有者token，锁已被占用时返回空字符串。
+// 缓存实现了Atomic接口时使用SetNX原子地加锁，否则先检查再写入，并发请求可能同时获得锁
+func (h *CacheHelper) TryLockCtx(ctx context.Context, key string, expiration time.Duration) (string, error) {
+	fullKey := h.lockKey(key)
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	if a, ok := h.cache.(Atomic); ok {
+		locked, err := a.SetNXCtx(ctx, fullKey, token, expiration)
+		if err != nil || !locked {
+			return "", err
+		}
+		return token, nil
+	}
 
-	// 使用 SET NX EX 实现分布式锁
-	// 检查键是否存在
 	exists, err := h.cache.ExistsCtx(ctx, fullKey)
-	if err != nil {
-		return false, err
+	if err != nil || exists > 0 {
+		return "", err
+	}
+	if err := h.cache.SetCtx(ctx, fullKey, token, expiration); err != nil {
+		return "", err
 	}
+	return token, nil
+}
 
-	if exists > 0 {
-		return false, nil
+// ReleaseCtx 释放分布式锁，只有token与当前持有者一致时才删除，
+// 持有时间超过过期时间后锁被其他请求获取的，不会被原持有者释放
+func (h *CacheHelper) ReleaseCtx(ctx context.Context, key, token string) error {
+	fullKey := h.lockKey(key)
+
+	if a, ok := h.cache.(Atomic); ok {
+		_, err := a.CompareAndDelCtx(ctx, fullKey, token)
+		return err
 	}
 
-	// 设置锁
-	err = h.cache.SetCtx(ctx, fullKey, "locked", expiration)
+	current, err := h.cache.GetCtx(ctx, fullKey)
+	if IsMiss(err) || (err == nil && current != token) {
+		return nil
+	}
 	if err != nil {
-		return false, err
+		return err
 	}
-
-	return true, nil
+	_, err = h.cache.DelCtx(ctx, fullKey)
+	return err
 }
 
-// UnlockCtx 释放分布式锁
-func (h *CacheHelper) UnlockCtx(ctx context.Context, key string) error {
-	fullKey := fmt.Sprintf("lock:%s", h.buildKey(key))
-	_, err := h.cache.DelCtx(ctx, fullKey)
-	return err
+// lockKey 锁的缓存键
+func (h *CacheHelper) lockKey(key string) string {
+	return fmt.Sprintf("lock:%s", h.buildKey(key))
 }
 
 // WithLockCtx 使用分布式锁执行函数
 func (h *CacheHelper) WithLockCtx(ctx context.Context, key string, expiration time.Duration, fn func() error) error {
 	// 获取锁
-	locked, err := h.LockCtx(ctx, key, expiration)
+	token, err := h.TryLockCtx(ctx, key, expiration)
 	if err != nil {
 		return fmt.Errorf("获取锁失败: %w", err)
 	}
-	if !locked {
+	if token == "" {
 		return fmt.Errorf("无法获取锁: %s", key)
 	}
 
-	// 确保释放锁
+	// 确保释放锁，上下文被取消后也要释放
 	defer func() {
-		if err := h.UnlockCtx(ctx, key); err != nil {
-			h.logger.WithFields(map[string]interface{}{
+		ctx := context.WithoutCancel(ctx)
+		if err := h.ReleaseCtx(ctx, key, token); err != nil {
+			h.logger.WithContext(ctx).WithFields(map[string]interface{}{
 				"key":   key,
 				"error": err,
 			}).Error("释放锁失败")
@@ -293,7 +350,7 @@ func (h *CacheHelper) GetOrSetCtx(ctx context.Context, key string, defaultValue
 	}
 
 	// 键不存在，设置默认值
-	if err != ErrKeyNotFound {
+	if !IsMiss(err) {
 		return "", err
 	}
 
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// testCaches 返回内存缓存和文件缓存
+func testCaches(t *testing.T) map[string]Cache {
+	return map[string]Cache{"memory": NewTestMemory(t), "file": NewTestFile(t)}
+}
+
+func TestLock(t *testing.T) {
+	for name, c := range testCaches(t) {
+		h := NewCacheHelper(c, log.NewDiscardLogger(), "test")
+
+		// 并发加锁时只有一个请求成功
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			tokens []string
+		)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := h.TryLock("order", time.Minute)
+				if err != nil {
+					t.Errorf("%s: 加锁失败: %v", name, err)
+					return
+				}
+				if token != "" {
+					mu.Lock()
+					tokens = append(tokens, token)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if len(tokens) != 1 {
+			t.Fatalf("%s: 并发加锁成功 %d 次，期望 1", name, len(tokens))
+		}
+
+		// 其他持有者的token不能释放锁
+		if err := h.Release("order", "other"); err != nil {
+			t.Fatal(err)
+		}
+		if token, _ := h.TryLock("order", time.Minute); token != "" {
+			t.Errorf("%s: 错误的token释放了锁", name)
+		}
+		if err := h.Release("order", tokens[0]); err != nil {
+			t.Fatal(err)
+		}
+		if token, _ := h.TryLock("order", time.Minute); token == "" {
+			t.Errorf("%s: 释放后无法重新加锁", name)
+		}
+	}
+}
+
+func TestSetNX(t *testing.T) {
+	for name, cache := range testCaches(t) {
+		c, ok := cache.(Atomic)
+		if !ok {
+			t.Fatalf("%s: 没有实现Atomic接口", name)
+		}
+		if ok, err := c.SetNX("k", "a", 0); err != nil || !ok {
+			t.Fatalf("%s: 键不存在时设置: %v, %v", name, ok, err)
+		}
+		if ok, _ := c.SetNX("k", "b", 0); ok {
+			t.Errorf("%s: 键已存在时设置成功", name)
+		}
+		if v, _ := cache.Get("k"); v != "a" {
+			t.Errorf("%s: 值 %q，期望 a", name, v)
+		}
+
+		if ok, _ := c.CompareAndDel("k", "b"); ok {
+			t.Errorf("%s: 值不相等时删除成功", name)
+		}
+		if ok, err := c.CompareAndDel("k", "a"); err != nil || !ok {
+			t.Errorf("%s: 值相等时删除: %v, %v", name, ok, err)
+		}
+		if ok, _ := c.CompareAndDel("k", "a"); ok {
+			t.Errorf("%s: 键不存在时删除成功", name)
+		}
+
+		// 已过期的键视为不存在
+		if err := cache.Set("expired", "a", time.Second); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(1100 * time.Millisecond)
+		if ok, err := c.SetNX("expired", "b", 0); err != nil || !ok {
+			t.Errorf("%s: 键过期后设置: %v, %v", name, ok, err)
+		}
+	}
+}
+
+// plainCache 隐藏Atomic接口的缓存
+type plainCache struct {
+	Cache
+}
+
+// nopObserver 不做任何处理的观察者
+type nopObserver struct{}
+
+func (nopObserver) Start(ctx context.Context, backend, op string) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
+
+func TestLockCompat(t *testing.T) {
+	memory := NewTestMemory(t)
+	if _, ok := Observe(memory, "memory", nopObserver{}).(Atomic); !ok {
+		t.Error("观察者包装后丢失了Atomic接口")
+	}
+	if _, ok := Observe(memory, "memory", nopObserver{}).(ZRangeRemover); !ok {
+		t.Error("观察者包装后丢失了ZRangeRemover接口")
+	}
+
+	// 没有实现Atomic接口的缓存退化为先检查再写入，仍然检查持有者
+	h := NewCacheHelper(plainCache{memory}, log.NewDiscardLogger(), "test")
+	token, err := h.TryLock("order", time.Minute)
+	if err != nil || token == "" {
+		t.Fatalf("加锁失败: %q, %v", token, err)
+	}
+	if next, _ := h.TryLock("order", time.Minute); next != "" {
+		t.Error("锁被占用时加锁成功")
+	}
+	if err := h.Release("order", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := h.TryLock("order", time.Minute); next != "" {
+		t.Error("错误的token释放了锁")
+	}
+	if err := h.Release("order", token); err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧的Lock和Unlock不使用token
+	if locked, err := h.Lock("legacy", time.Minute); err != nil || !locked {
+		t.Fatalf("Lock: %v, %v", locked, err)
+	}
+	if locked, _ := h.Lock("legacy", time.Minute); locked {
+		t.Error("Lock: 锁被占用时加锁成功")
+	}
+	if err := h.Unlock("legacy"); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _ := h.Lock("legacy", time.Minute); !locked {
+		t.Error("Unlock后无法重新加锁")
+	}
+}
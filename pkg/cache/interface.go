@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 // 错误定义
@@ -14,13 +16,19 @@ var (
 	ErrTypeMismatch = errors.New("值类型不匹配")
 )
 
+// IsMiss 判断错误是否表示键不存在，内存和文件缓存返回ErrKeyNotFound，Redis缓存返回redis.Nil
+func IsMiss(err error) bool {
+	return errors.Is(err, ErrKeyNotFound) || errors.Is(err, redis.Nil)
+}
+
 // Z 是有序集合的成员结构
 type Z struct {
 	Score  float64
 	Member interface{}
 }
 
-// Cache 缓存接口
+// Cache 缓存接口，内存和文件缓存只在当前进程内有效，Redis缓存由所有节点共享，
+// 限流、会话、JWT撤销列表等基于Cache的组件在多节点部署时需要使用Redis缓存
 type Cache interface {
 	// 默认方法（不带 Context，使用 unified.Background()）
 	// 基础操作
@@ -125,6 +133,33 @@ type Cache interface {
 	GetClient() interface{}
 }
 
+// Atomic 支持原子条件写入的缓存，内存、文件和Redis缓存都实现了此接口，
+// 它不属于Cache接口，使用方通过类型断言判断，例如CacheHelper.TryLock
+type Atomic interface {
+	// SetNX 键不存在时设置缓存，返回是否设置成功
+	SetNX(key string, value interface{}, expiration time.Duration) (bool, error)
+	// CompareAndDel 键的值等于value时删除，返回是否删除
+	CompareAndDel(key, value string) (bool, error)
+
+	SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	CompareAndDelCtx(ctx context.Context, key, value string) (bool, error)
+}
+
+// ZRangeRemover 支持按分数范围删除有序集合成员的缓存，内存、文件和Redis缓存都实现了此接口，
+// 它不属于Cache接口，使用方通过类型断言判断，例如滑动窗口限流
+type ZRangeRemover interface {
+	// ZRemRangeByScore 删除分数在[min, max]范围内的成员，返回删除的数量
+	ZRemRangeByScore(key string, min, max float64) (int64, error)
+
+	ZRemRangeByScoreCtx(ctx context.Context, key string, min, max float64) (int64, error)
+}
+
+// Scripter 支持执行Lua脚本的缓存，Redis缓存实现了此接口，keys会加上缓存键前缀，
+// 脚本在Redis中原子执行，用于需要读取后写入的操作，例如令牌桶限流
+type Scripter interface {
+	RunScriptCtx(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error)
+}
+
 // ZMember 有序集合成员
 type ZMember struct {
 	Score  float64
@@ -54,7 +54,7 @@ func (m *MemoryCache) isExpired(key string) bool {
 	return false
 }
 
-// cleanExpired 清理过期的键
+// cleanExpired 清理过期的键，会修改数据，调用方需要持有写锁
 func (m *MemoryCache) cleanExpired(key string) {
 	if m.isExpired(key) {
 		delete(m.data, key)
@@ -87,6 +87,16 @@ func (m *MemoryCache) Set(key string, value interface{}, expiration time.Duratio
 	return m.SetCtx(context.Background(), key, value, expiration)
 }
 
+// SetNX 键不存在时设置缓存
+func (m *MemoryCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return m.SetNXCtx(context.Background(), key, value, expiration)
+}
+
+// CompareAndDel 键的值等于value时删除
+func (m *MemoryCache) CompareAndDel(key, value string) (bool, error) {
+	return m.CompareAndDelCtx(context.Background(), key, value)
+}
+
 // Del 删除缓存
 func (m *MemoryCache) Del(keys ...string) (int64, error) {
 	return m.DelCtx(context.Background(), keys...)
@@ -109,8 +119,8 @@ func (m *MemoryCache) TTL(key string) (time.Duration, error) {
 
 // GetCtx 获取缓存
 func (m *MemoryCache) GetCtx(ctx context.Context, key string) (string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -142,6 +152,48 @@ func (m *MemoryCache) SetCtx(ctx context.Context, key string, value interface{},
 	return nil
 }
 
+// ZRemRangeByScore 删除分数在范围内的有序集合成员
+func (m *MemoryCache) ZRemRangeByScore(key string, min, max float64) (int64, error) {
+	return m.ZRemRangeByScoreCtx(context.Background(), key, min, max)
+}
+
+// SetNXCtx 键不存在时设置缓存，返回是否设置成功
+func (m *MemoryCache) SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fullKey := m.buildKey(key)
+	m.cleanExpired(fullKey)
+	if _, ok := m.data[fullKey]; ok {
+		return false, nil
+	}
+
+	m.data[fullKey] = value
+	if expiration > 0 {
+		m.expiry[fullKey] = time.Now().Add(expiration)
+	} else {
+		delete(m.expiry, fullKey)
+	}
+
+	return true, nil
+}
+
+// CompareAndDelCtx 键的值等于value时删除，返回是否删除
+func (m *MemoryCache) CompareAndDelCtx(ctx context.Context, key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fullKey := m.buildKey(key)
+	m.cleanExpired(fullKey)
+	if current, ok := m.data[fullKey].(string); !ok || current != value {
+		return false, nil
+	}
+
+	delete(m.data, fullKey)
+	delete(m.expiry, fullKey)
+	return true, nil
+}
+
 // DelCtx 删除缓存
 func (m *MemoryCache) DelCtx(ctx context.Context, keys ...string) (int64, error) {
 	m.mu.Lock()
@@ -162,8 +214,8 @@ func (m *MemoryCache) DelCtx(ctx context.Context, keys ...string) (int64, error)
 
 // ExistsCtx 检查键是否存在
 func (m *MemoryCache) ExistsCtx(ctx context.Context, keys ...string) (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	var count int64
 	for _, key := range keys {
@@ -198,8 +250,8 @@ func (m *MemoryCache) ExpireCtx(ctx context.Context, key string, expiration time
 
 // TTLCtx 获取过期时间
 func (m *MemoryCache) TTLCtx(ctx context.Context, key string) (time.Duration, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -318,8 +370,8 @@ func (m *MemoryCache) HLen(key string) (int64, error) {
 
 // HGetCtx 获取哈希表字段值
 func (m *MemoryCache) HGetCtx(ctx context.Context, key, field string) (string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -424,8 +476,8 @@ func (m *MemoryCache) HDelCtx(ctx context.Context, key string, fields ...string)
 
 // HGetAllCtx 获取哈希表所有字段值
 func (m *MemoryCache) HGetAllCtx(ctx context.Context, key string) (map[string]string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -455,8 +507,8 @@ func (m *MemoryCache) HGetAllCtx(ctx context.Context, key string) (map[string]st
 
 // HExistsCtx 检查哈希表字段是否存在
 func (m *MemoryCache) HExistsCtx(ctx context.Context, key, field string) (bool, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -477,8 +529,8 @@ func (m *MemoryCache) HExistsCtx(ctx context.Context, key, field string) (bool,
 
 // HLenCtx 获取哈希表字段数量
 func (m *MemoryCache) HLenCtx(ctx context.Context, key string) (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -503,8 +555,8 @@ func (m *MemoryCache) Keys(pattern string) ([]string, error) {
 
 // KeysCtx 获取所有匹配的键
 func (m *MemoryCache) KeysCtx(ctx context.Context, pattern string) ([]string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	var keys []string
 	for key := range m.data {
@@ -591,8 +643,8 @@ func (m *MemoryCache) LLen(key string) (int64, error) {
 
 // LLenCtx 获取列表长度
 func (m *MemoryCache) LLenCtx(ctx context.Context, key string) (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -783,8 +835,8 @@ func (m *MemoryCache) LRange(key string, start, stop int64) ([]string, error) {
 
 // LRangeCtx 获取列表范围内的元素
 func (m *MemoryCache) LRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -938,8 +990,8 @@ func (m *MemoryCache) SRemCtx(ctx context.Context, key string, members ...interf
 
 // SMembersCtx 获取集合所有成员
 func (m *MemoryCache) SMembersCtx(ctx context.Context, key string) ([]string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -964,8 +1016,8 @@ func (m *MemoryCache) SMembersCtx(ctx context.Context, key string) ([]string, er
 
 // SIsMemberCtx 检查成员是否在集合中
 func (m *MemoryCache) SIsMemberCtx(ctx context.Context, key string, member interface{}) (bool, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -986,8 +1038,8 @@ func (m *MemoryCache) SIsMemberCtx(ctx context.Context, key string, member inter
 
 // SCardCtx 获取集合成员数
 func (m *MemoryCache) SCardCtx(ctx context.Context, key string) (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -1102,10 +1154,38 @@ func (m *MemoryCache) ZRemCtx(ctx context.Context, key string, members ...interf
 	return removed, nil
 }
 
+// ZRemRangeByScoreCtx 删除分数在[min, max]范围内的有序集合成员
+func (m *MemoryCache) ZRemRangeByScoreCtx(ctx context.Context, key string, min, max float64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fullKey := m.buildKey(key)
+	m.cleanExpired(fullKey)
+
+	val, ok := m.data[fullKey]
+	if !ok {
+		return 0, nil
+	}
+	zset, ok := val.(map[interface{}]float64)
+	if !ok {
+		return 0, ErrTypeMismatch
+	}
+
+	var removed int64
+	for member, score := range zset {
+		if score >= min && score <= max {
+			delete(zset, member)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
 // ZRangeCtx 获取有序集合范围
 func (m *MemoryCache) ZRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -1157,8 +1237,8 @@ func (m *MemoryCache) ZRangeCtx(ctx context.Context, key string, start, stop int
 
 // ZRangeWithScoresCtx 获取有序集合范围及分数
 func (m *MemoryCache) ZRangeWithScoresCtx(ctx context.Context, key string, start, stop int64) ([]Z, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -1210,8 +1290,8 @@ func (m *MemoryCache) ZRangeWithScoresCtx(ctx context.Context, key string, start
 
 // ZCardCtx 获取有序集合成员数
 func (m *MemoryCache) ZCardCtx(ctx context.Context, key string) (int64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
@@ -1232,8 +1312,8 @@ func (m *MemoryCache) ZCardCtx(ctx context.Context, key string) (int64, error) {
 
 // ZScoreCtx 获取有序集合成员分数
 func (m *MemoryCache) ZScoreCtx(ctx context.Context, key, member string) (float64, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	fullKey := m.buildKey(key)
 	m.cleanExpired(fullKey)
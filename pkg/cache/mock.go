@@ -83,6 +83,16 @@ func (m *MockCache) Set(key string, value interface{}, expiration time.Duration)
 	return m.SetCtx(context.Background(), key, value, expiration)
 }
 
+// SetNX 键不存在时设置缓存
+func (m *MockCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return m.SetNXCtx(context.Background(), key, value, expiration)
+}
+
+// CompareAndDel 键的值等于value时删除
+func (m *MockCache) CompareAndDel(key, value string) (bool, error) {
+	return m.CompareAndDelCtx(context.Background(), key, value)
+}
+
 // Del 删除缓存
 func (m *MockCache) Del(keys ...string) (int64, error) {
 	return m.DelCtx(context.Background(), keys...)
@@ -138,6 +148,43 @@ func (m *MockCache) SetCtx(ctx context.Context, key string, value interface{}, e
 	return nil
 }
 
+// SetNXCtx 键不存在时设置缓存，返回是否设置成功
+func (m *MockCache) SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fullKey := m.buildKey(key)
+	m.cleanExpired(fullKey)
+	if _, ok := m.data[fullKey]; ok {
+		return false, nil
+	}
+
+	m.data[fullKey] = value
+	if expiration > 0 {
+		m.expiry[fullKey] = time.Now().Add(expiration)
+	} else {
+		delete(m.expiry, fullKey)
+	}
+
+	return true, nil
+}
+
+// CompareAndDelCtx 键的值等于value时删除，返回是否删除
+func (m *MockCache) CompareAndDelCtx(ctx context.Context, key, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fullKey := m.buildKey(key)
+	m.cleanExpired(fullKey)
+	if current, ok := m.data[fullKey].(string); !ok || current != value {
+		return false, nil
+	}
+
+	delete(m.data, fullKey)
+	delete(m.expiry, fullKey)
+	return true, nil
+}
+
 // DelCtx 删除缓存
 func (m *MockCache) DelCtx(ctx context.Context, keys ...string) (int64, error) {
 	m.mu.Lock()
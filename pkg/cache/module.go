@@ -1,14 +1,17 @@
 package cache
 
 import (
+	"context"
+
 	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/log"
 	"go.uber.org/fx"
 )
 
 // Module 缓存模块
 var Module = fx.Options(
 	fx.Provide(
-		NewCacheProvider,
+		NewCache,
 	),
 )
 
@@ -23,3 +26,40 @@ func NewCacheProvider(cfg *config.Config) fx.Option {
 		return fx.Provide(NewRedisCache)
 	}
 }
+
+// CacheParams 缓存参数
+type CacheParams struct {
+	fx.In
+	Lifecycle fx.Lifecycle
+	Config    *config.Config
+	Logger    log.Logger
+	Observers []Observer `group:"cache_observers"`
+}
+
+// NewCache 根据配置创建缓存实现，注册了观察者时包装缓存实现，应用停止时关闭
+func NewCache(p CacheParams) (Cache, error) {
+	var (
+		cache   Cache
+		backend = p.Config.Cache.Type
+		err     error
+	)
+	switch backend {
+	case "memory":
+		cache, err = NewMemoryCache(p.Config, p.Logger)
+	case "file":
+		cache, err = NewFileCache(p.Config, p.Logger)
+	default:
+		backend = "redis"
+		cache, err = NewRedisCache(p.Config, p.Logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return cache.Close()
+		},
+	})
+	return Observe(cache, backend, p.Observers...), nil
+}
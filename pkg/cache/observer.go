@@ -0,0 +1,595 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+// ObserversGroup 缓存观察者所在的fx值组名称
+const ObserversGroup = "cache_observers"
+
+// Observer 缓存操作观察者，用于统计指标和链路追踪，通过ProvideObserver注册后由NewCache包装缓存实现
+type Observer interface {
+	// Start 在操作执行前调用，backend为缓存类型，op为操作名称（如get、set、hgetall），
+	// 返回的上下文传给缓存实现，done在操作结束后以操作的错误调用
+	Start(ctx context.Context, backend, op string) (context.Context, func(err error))
+}
+
+// ProvideObserver 将构造函数返回的观察者加入cache_observers组，构造函数返回nil时不生效
+func ProvideObserver(constructor interface{}) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(Observer)),
+			fx.ResultTags(`group:"`+ObserversGroup+`"`),
+		),
+	)
+}
+
+// Observe 包装缓存实现，每个操作依次通知观察者，不带Context的方法使用context.Background()，
+// 被包装的缓存实现的Atomic、ZRangeRemover和Scripter接口在包装后仍然可用
+func Observe(c Cache, backend string, observers ...Observer) Cache {
+	active := make([]Observer, 0, len(observers))
+	for _, o := range observers {
+		if o != nil {
+			active = append(active, o)
+		}
+	}
+	if len(active) == 0 {
+		return c
+	}
+	observed := &observedCache{cache: c, backend: backend, observers: active}
+
+	// 保留被包装的缓存实现的可选接口
+	a, isAtomic := c.(Atomic)
+	z, isRanger := c.(ZRangeRemover)
+	sc, isScripter := c.(Scripter)
+	withAtomic := observedAtomic{observed, a}
+	withRanger := observedZRangeRemover{observed, z}
+	withScripter := observedScripter{observed, sc}
+	switch {
+	case isAtomic && isRanger && isScripter:
+		return struct {
+			*observedCache
+			observedAtomic
+			observedZRangeRemover
+			observedScripter
+		}{observed, withAtomic, withRanger, withScripter}
+	case isAtomic && isRanger:
+		return struct {
+			*observedCache
+			observedAtomic
+			observedZRangeRemover
+		}{observed, withAtomic, withRanger}
+	case isAtomic && isScripter:
+		return struct {
+			*observedCache
+			observedAtomic
+			observedScripter
+		}{observed, withAtomic, withScripter}
+	case isRanger && isScripter:
+		return struct {
+			*observedCache
+			observedZRangeRemover
+			observedScripter
+		}{observed, withRanger, withScripter}
+	case isAtomic:
+		return struct {
+			*observedCache
+			observedAtomic
+		}{observed, withAtomic}
+	case isRanger:
+		return struct {
+			*observedCache
+			observedZRangeRemover
+		}{observed, withRanger}
+	case isScripter:
+		return struct {
+			*observedCache
+			observedScripter
+		}{observed, withScripter}
+	}
+	return observed
+}
+
+// observedCache 通知观察者的缓存包装
+type observedCache struct {
+	cache     Cache
+	backend   string
+	observers []Observer
+}
+
+// observedAtomic 通知观察者的Atomic接口实现
+type observedAtomic struct {
+	observed *observedCache
+	atomic   Atomic
+}
+
+// SetNX 实现Atomic接口
+func (o observedAtomic) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return o.SetNXCtx(context.Background(), key, value, expiration)
+}
+
+// CompareAndDel 实现Atomic接口
+func (o observedAtomic) CompareAndDel(key, value string) (bool, error) {
+	return o.CompareAndDelCtx(context.Background(), key, value)
+}
+
+// SetNXCtx 实现Atomic接口
+func (o observedAtomic) SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (ok bool, err error) {
+	ctx, done := o.observed.start(ctx, "setnx")
+	defer func() { done(err) }()
+	return o.atomic.SetNXCtx(ctx, key, value, expiration)
+}
+
+// CompareAndDelCtx 实现Atomic接口
+func (o observedAtomic) CompareAndDelCtx(ctx context.Context, key, value string) (ok bool, err error) {
+	ctx, done := o.observed.start(ctx, "compare_and_del")
+	defer func() { done(err) }()
+	return o.atomic.CompareAndDelCtx(ctx, key, value)
+}
+
+// observedZRangeRemover 通知观察者的ZRangeRemover接口实现
+type observedZRangeRemover struct {
+	observed *observedCache
+	remover  ZRangeRemover
+}
+
+// ZRemRangeByScore 实现ZRangeRemover接口
+func (o observedZRangeRemover) ZRemRangeByScore(key string, min, max float64) (int64, error) {
+	return o.ZRemRangeByScoreCtx(context.Background(), key, min, max)
+}
+
+// ZRemRangeByScoreCtx 实现ZRangeRemover接口
+func (o observedZRangeRemover) ZRemRangeByScoreCtx(ctx context.Context, key string, min, max float64) (n int64, err error) {
+	ctx, done := o.observed.start(ctx, "zremrangebyscore")
+	defer func() { done(err) }()
+	return o.remover.ZRemRangeByScoreCtx(ctx, key, min, max)
+}
+
+// observedScripter 通知观察者的Scripter接口实现
+type observedScripter struct {
+	observed *observedCache
+	scripter Scripter
+}
+
+// RunScriptCtx 实现Scripter接口
+func (o observedScripter) RunScriptCtx(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (result interface{}, err error) {
+	ctx, done := o.observed.start(ctx, "eval")
+	defer func() { done(err) }()
+	return o.scripter.RunScriptCtx(ctx, script, keys, args...)
+}
+
+// start 通知所有观察者操作开始，返回的函数按相反顺序通知操作结束
+func (c *observedCache) start(ctx context.Context, op string) (context.Context, func(err error)) {
+	dones := make([]func(error), len(c.observers))
+	for i, o := range c.observers {
+		ctx, dones[i] = o.Start(ctx, c.backend, op)
+	}
+	return ctx, func(err error) {
+		for i := len(dones) - 1; i >= 0; i-- {
+			dones[i](err)
+		}
+	}
+}
+
+// Get 实现Cache接口
+func (c *observedCache) Get(key string) (string, error) {
+	return c.GetCtx(context.Background(), key)
+}
+
+// Set 实现Cache接口
+func (c *observedCache) Set(key string, value interface{}, expiration time.Duration) error {
+	return c.SetCtx(context.Background(), key, value, expiration)
+}
+
+// Del 实现Cache接口
+func (c *observedCache) Del(keys ...string) (int64, error) {
+	return c.DelCtx(context.Background(), keys...)
+}
+
+// Exists 实现Cache接口
+func (c *observedCache) Exists(keys ...string) (int64, error) {
+	return c.ExistsCtx(context.Background(), keys...)
+}
+
+// Expire 实现Cache接口
+func (c *observedCache) Expire(key string, expiration time.Duration) error {
+	return c.ExpireCtx(context.Background(), key, expiration)
+}
+
+// TTL 实现Cache接口
+func (c *observedCache) TTL(key string) (time.Duration, error) {
+	return c.TTLCtx(context.Background(), key)
+}
+
+// Incr 实现Cache接口
+func (c *observedCache) Incr(key string) (int64, error) {
+	return c.IncrCtx(context.Background(), key)
+}
+
+// Decr 实现Cache接口
+func (c *observedCache) Decr(key string) (int64, error) {
+	return c.DecrCtx(context.Background(), key)
+}
+
+// IncrBy 实现Cache接口
+func (c *observedCache) IncrBy(key string, value int64) (int64, error) {
+	return c.IncrByCtx(context.Background(), key, value)
+}
+
+// HGet 实现Cache接口
+func (c *observedCache) HGet(key, field string) (string, error) {
+	return c.HGetCtx(context.Background(), key, field)
+}
+
+// HSet 实现Cache接口
+func (c *observedCache) HSet(key string, values ...interface{}) (int64, error) {
+	return c.HSetCtx(context.Background(), key, values...)
+}
+
+// HDel 实现Cache接口
+func (c *observedCache) HDel(key string, fields ...string) (int64, error) {
+	return c.HDelCtx(context.Background(), key, fields...)
+}
+
+// HGetAll 实现Cache接口
+func (c *observedCache) HGetAll(key string) (map[string]string, error) {
+	return c.HGetAllCtx(context.Background(), key)
+}
+
+// HExists 实现Cache接口
+func (c *observedCache) HExists(key, field string) (bool, error) {
+	return c.HExistsCtx(context.Background(), key, field)
+}
+
+// HLen 实现Cache接口
+func (c *observedCache) HLen(key string) (int64, error) {
+	return c.HLenCtx(context.Background(), key)
+}
+
+// LPush 实现Cache接口
+func (c *observedCache) LPush(key string, values ...interface{}) (int64, error) {
+	return c.LPushCtx(context.Background(), key, values...)
+}
+
+// RPush 实现Cache接口
+func (c *observedCache) RPush(key string, values ...interface{}) (int64, error) {
+	return c.RPushCtx(context.Background(), key, values...)
+}
+
+// LPop 实现Cache接口
+func (c *observedCache) LPop(key string) (string, error) {
+	return c.LPopCtx(context.Background(), key)
+}
+
+// RPop 实现Cache接口
+func (c *observedCache) RPop(key string) (string, error) {
+	return c.RPopCtx(context.Background(), key)
+}
+
+// LLen 实现Cache接口
+func (c *observedCache) LLen(key string) (int64, error) {
+	return c.LLenCtx(context.Background(), key)
+}
+
+// LRange 实现Cache接口
+func (c *observedCache) LRange(key string, start, stop int64) ([]string, error) {
+	return c.LRangeCtx(context.Background(), key, start, stop)
+}
+
+// SAdd 实现Cache接口
+func (c *observedCache) SAdd(key string, members ...interface{}) (int64, error) {
+	return c.SAddCtx(context.Background(), key, members...)
+}
+
+// SRem 实现Cache接口
+func (c *observedCache) SRem(key string, members ...interface{}) (int64, error) {
+	return c.SRemCtx(context.Background(), key, members...)
+}
+
+// SMembers 实现Cache接口
+func (c *observedCache) SMembers(key string) ([]string, error) {
+	return c.SMembersCtx(context.Background(), key)
+}
+
+// SIsMember 实现Cache接口
+func (c *observedCache) SIsMember(key string, member interface{}) (bool, error) {
+	return c.SIsMemberCtx(context.Background(), key, member)
+}
+
+// SCard 实现Cache接口
+func (c *observedCache) SCard(key string) (int64, error) {
+	return c.SCardCtx(context.Background(), key)
+}
+
+// ZAdd 实现Cache接口
+func (c *observedCache) ZAdd(key string, members ...Z) (int64, error) {
+	return c.ZAddCtx(context.Background(), key, members...)
+}
+
+// ZRem 实现Cache接口
+func (c *observedCache) ZRem(key string, members ...interface{}) (int64, error) {
+	return c.ZRemCtx(context.Background(), key, members...)
+}
+
+// ZRange 实现Cache接口
+func (c *observedCache) ZRange(key string, start, stop int64) ([]string, error) {
+	return c.ZRangeCtx(context.Background(), key, start, stop)
+}
+
+// ZRangeWithScores 实现Cache接口
+func (c *observedCache) ZRangeWithScores(key string, start, stop int64) ([]Z, error) {
+	return c.ZRangeWithScoresCtx(context.Background(), key, start, stop)
+}
+
+// ZCard 实现Cache接口
+func (c *observedCache) ZCard(key string) (int64, error) {
+	return c.ZCardCtx(context.Background(), key)
+}
+
+// ZScore 实现Cache接口
+func (c *observedCache) ZScore(key, member string) (float64, error) {
+	return c.ZScoreCtx(context.Background(), key, member)
+}
+
+// Keys 实现Cache接口
+func (c *observedCache) Keys(pattern string) ([]string, error) {
+	return c.KeysCtx(context.Background(), pattern)
+}
+
+// Ping 实现Cache接口
+func (c *observedCache) Ping() error {
+	return c.PingCtx(context.Background())
+}
+
+// GetCtx 实现Cache接口
+func (c *observedCache) GetCtx(ctx context.Context, key string) (value string, err error) {
+	ctx, done := c.start(ctx, "get")
+	defer func() { done(err) }()
+	return c.cache.GetCtx(ctx, key)
+}
+
+// SetCtx 实现Cache接口
+func (c *observedCache) SetCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (err error) {
+	ctx, done := c.start(ctx, "set")
+	defer func() { done(err) }()
+	return c.cache.SetCtx(ctx, key, value, expiration)
+}
+
+// DelCtx 实现Cache接口
+func (c *observedCache) DelCtx(ctx context.Context, keys ...string) (n int64, err error) {
+	ctx, done := c.start(ctx, "del")
+	defer func() { done(err) }()
+	return c.cache.DelCtx(ctx, keys...)
+}
+
+// ExistsCtx 实现Cache接口
+func (c *observedCache) ExistsCtx(ctx context.Context, keys ...string) (n int64, err error) {
+	ctx, done := c.start(ctx, "exists")
+	defer func() { done(err) }()
+	return c.cache.ExistsCtx(ctx, keys...)
+}
+
+// ExpireCtx 实现Cache接口
+func (c *observedCache) ExpireCtx(ctx context.Context, key string, expiration time.Duration) (err error) {
+	ctx, done := c.start(ctx, "expire")
+	defer func() { done(err) }()
+	return c.cache.ExpireCtx(ctx, key, expiration)
+}
+
+// TTLCtx 实现Cache接口
+func (c *observedCache) TTLCtx(ctx context.Context, key string) (ttl time.Duration, err error) {
+	ctx, done := c.start(ctx, "ttl")
+	defer func() { done(err) }()
+	return c.cache.TTLCtx(ctx, key)
+}
+
+// IncrCtx 实现Cache接口
+func (c *observedCache) IncrCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "incr")
+	defer func() { done(err) }()
+	return c.cache.IncrCtx(ctx, key)
+}
+
+// DecrCtx 实现Cache接口
+func (c *observedCache) DecrCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "decr")
+	defer func() { done(err) }()
+	return c.cache.DecrCtx(ctx, key)
+}
+
+// IncrByCtx 实现Cache接口
+func (c *observedCache) IncrByCtx(ctx context.Context, key string, value int64) (n int64, err error) {
+	ctx, done := c.start(ctx, "incrby")
+	defer func() { done(err) }()
+	return c.cache.IncrByCtx(ctx, key, value)
+}
+
+// HGetCtx 实现Cache接口
+func (c *observedCache) HGetCtx(ctx context.Context, key, field string) (value string, err error) {
+	ctx, done := c.start(ctx, "hget")
+	defer func() { done(err) }()
+	return c.cache.HGetCtx(ctx, key, field)
+}
+
+// HSetCtx 实现Cache接口
+func (c *observedCache) HSetCtx(ctx context.Context, key string, values ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "hset")
+	defer func() { done(err) }()
+	return c.cache.HSetCtx(ctx, key, values...)
+}
+
+// HDelCtx 实现Cache接口
+func (c *observedCache) HDelCtx(ctx context.Context, key string, fields ...string) (n int64, err error) {
+	ctx, done := c.start(ctx, "hdel")
+	defer func() { done(err) }()
+	return c.cache.HDelCtx(ctx, key, fields...)
+}
+
+// HGetAllCtx 实现Cache接口
+func (c *observedCache) HGetAllCtx(ctx context.Context, key string) (values map[string]string, err error) {
+	ctx, done := c.start(ctx, "hgetall")
+	defer func() { done(err) }()
+	return c.cache.HGetAllCtx(ctx, key)
+}
+
+// HExistsCtx 实现Cache接口
+func (c *observedCache) HExistsCtx(ctx context.Context, key, field string) (ok bool, err error) {
+	ctx, done := c.start(ctx, "hexists")
+	defer func() { done(err) }()
+	return c.cache.HExistsCtx(ctx, key, field)
+}
+
+// HLenCtx 实现Cache接口
+func (c *observedCache) HLenCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "hlen")
+	defer func() { done(err) }()
+	return c.cache.HLenCtx(ctx, key)
+}
+
+// LPushCtx 实现Cache接口
+func (c *observedCache) LPushCtx(ctx context.Context, key string, values ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "lpush")
+	defer func() { done(err) }()
+	return c.cache.LPushCtx(ctx, key, values...)
+}
+
+// RPushCtx 实现Cache接口
+func (c *observedCache) RPushCtx(ctx context.Context, key string, values ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "rpush")
+	defer func() { done(err) }()
+	return c.cache.RPushCtx(ctx, key, values...)
+}
+
+// LPopCtx 实现Cache接口
+func (c *observedCache) LPopCtx(ctx context.Context, key string) (value string, err error) {
+	ctx, done := c.start(ctx, "lpop")
+	defer func() { done(err) }()
+	return c.cache.LPopCtx(ctx, key)
+}
+
+// RPopCtx 实现Cache接口
+func (c *observedCache) RPopCtx(ctx context.Context, key string) (value string, err error) {
+	ctx, done := c.start(ctx, "rpop")
+	defer func() { done(err) }()
+	return c.cache.RPopCtx(ctx, key)
+}
+
+// LLenCtx 实现Cache接口
+func (c *observedCache) LLenCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "llen")
+	defer func() { done(err) }()
+	return c.cache.LLenCtx(ctx, key)
+}
+
+// LRangeCtx 实现Cache接口
+func (c *observedCache) LRangeCtx(ctx context.Context, key string, start, stop int64) (values []string, err error) {
+	ctx, done := c.start(ctx, "lrange")
+	defer func() { done(err) }()
+	return c.cache.LRangeCtx(ctx, key, start, stop)
+}
+
+// SAddCtx 实现Cache接口
+func (c *observedCache) SAddCtx(ctx context.Context, key string, members ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "sadd")
+	defer func() { done(err) }()
+	return c.cache.SAddCtx(ctx, key, members...)
+}
+
+// SRemCtx 实现Cache接口
+func (c *observedCache) SRemCtx(ctx context.Context, key string, members ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "srem")
+	defer func() { done(err) }()
+	return c.cache.SRemCtx(ctx, key, members...)
+}
+
+// SMembersCtx 实现Cache接口
+func (c *observedCache) SMembersCtx(ctx context.Context, key string) (members []string, err error) {
+	ctx, done := c.start(ctx, "smembers")
+	defer func() { done(err) }()
+	return c.cache.SMembersCtx(ctx, key)
+}
+
+// SIsMemberCtx 实现Cache接口
+func (c *observedCache) SIsMemberCtx(ctx context.Context, key string, member interface{}) (ok bool, err error) {
+	ctx, done := c.start(ctx, "sismember")
+	defer func() { done(err) }()
+	return c.cache.SIsMemberCtx(ctx, key, member)
+}
+
+// SCardCtx 实现Cache接口
+func (c *observedCache) SCardCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "scard")
+	defer func() { done(err) }()
+	return c.cache.SCardCtx(ctx, key)
+}
+
+// ZAddCtx 实现Cache接口
+func (c *observedCache) ZAddCtx(ctx context.Context, key string, members ...Z) (n int64, err error) {
+	ctx, done := c.start(ctx, "zadd")
+	defer func() { done(err) }()
+	return c.cache.ZAddCtx(ctx, key, members...)
+}
+
+// ZRemCtx 实现Cache接口
+func (c *observedCache) ZRemCtx(ctx context.Context, key string, members ...interface{}) (n int64, err error) {
+	ctx, done := c.start(ctx, "zrem")
+	defer func() { done(err) }()
+	return c.cache.ZRemCtx(ctx, key, members...)
+}
+
+// ZRangeCtx 实现Cache接口
+func (c *observedCache) ZRangeCtx(ctx context.Context, key string, start, stop int64) (members []string, err error) {
+	ctx, done := c.start(ctx, "zrange")
+	defer func() { done(err) }()
+	return c.cache.ZRangeCtx(ctx, key, start, stop)
+}
+
+// ZRangeWithScoresCtx 实现Cache接口
+func (c *observedCache) ZRangeWithScoresCtx(ctx context.Context, key string, start, stop int64) (members []Z, err error) {
+	ctx, done := c.start(ctx, "zrange")
+	defer func() { done(err) }()
+	return c.cache.ZRangeWithScoresCtx(ctx, key, start, stop)
+}
+
+// ZCardCtx 实现Cache接口
+func (c *observedCache) ZCardCtx(ctx context.Context, key string) (n int64, err error) {
+	ctx, done := c.start(ctx, "zcard")
+	defer func() { done(err) }()
+	return c.cache.ZCardCtx(ctx, key)
+}
+
+// ZScoreCtx 实现Cache接口
+func (c *observedCache) ZScoreCtx(ctx context.Context, key, member string) (score float64, err error) {
+	ctx, done := c.start(ctx, "zscore")
+	defer func() { done(err) }()
+	return c.cache.ZScoreCtx(ctx, key, member)
+}
+
+// KeysCtx 实现Cache接口
+func (c *observedCache) KeysCtx(ctx context.Context, pattern string) (keys []string, err error) {
+	ctx, done := c.start(ctx, "keys")
+	defer func() { done(err) }()
+	return c.cache.KeysCtx(ctx, pattern)
+}
+
+// PingCtx 实现Cache接口
+func (c *observedCache) PingCtx(ctx context.Context) (err error) {
+	ctx, done := c.start(ctx, "ping")
+	defer func() { done(err) }()
+	return c.cache.PingCtx(ctx)
+}
+
+// Close 实现Cache接口
+func (c *observedCache) Close() error {
+	return c.cache.Close()
+}
+
+// GetClient 实现Cache接口
+func (c *observedCache) GetClient() interface{} {
+	return c.cache.GetClient()
+}
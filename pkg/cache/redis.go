@@ -3,6 +3,8 @@ package cache
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -83,6 +85,18 @@ func (r *RedisCache) Set(key string, value interface{}, expiration time.Duration
 	return r.client.Set(context.Background(), r.buildKey(key), value, expiration).Err()
 }
 
+func (r *RedisCache) SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.SetNXCtx(context.Background(), key, value, expiration)
+}
+
+func (r *RedisCache) CompareAndDel(key, value string) (bool, error) {
+	return r.CompareAndDelCtx(context.Background(), key, value)
+}
+
+func (r *RedisCache) ZRemRangeByScore(key string, min, max float64) (int64, error) {
+	return r.ZRemRangeByScoreCtx(context.Background(), key, min, max)
+}
+
 func (r *RedisCache) Del(keys ...string) (int64, error) {
 	// 转换所有键为带前缀的键
 	prefixedKeys := make([]string, len(keys))
@@ -243,21 +257,7 @@ func (r *RedisCache) ZScore(key, member string) (float64, error) {
 
 // 其他操作
 func (r *RedisCache) Keys(pattern string) ([]string, error) {
-	// 对于 Keys 操作，我们需要添加前缀到模式中
-	prefixedPattern := r.buildKey(pattern)
-	keys, err := r.client.Keys(context.Background(), prefixedPattern).Result()
-	if err != nil {
-		return nil, err
-	}
-
-	// 移除前缀
-	if r.prefix != "" {
-		for i, key := range keys {
-			keys[i] = key[len(r.prefix):]
-		}
-	}
-
-	return keys, nil
+	return r.KeysCtx(context.Background(), pattern)
 }
 
 func (r *RedisCache) Ping() error {
@@ -275,6 +275,46 @@ func (r *RedisCache) SetCtx(ctx context.Context, key string, value interface{},
 	return r.client.Set(ctx, r.buildKey(key), value, expiration).Err()
 }
 
+func (r *RedisCache) SetNXCtx(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, r.buildKey(key), value, expiration).Result()
+}
+
+// compareAndDelScript 值相等时删除键，比较和删除在Redis中原子执行
+var compareAndDelScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (r *RedisCache) CompareAndDelCtx(ctx context.Context, key, value string) (bool, error) {
+	n, err := compareAndDelScript.Run(ctx, r.client, []string{r.buildKey(key)}, value).Int64()
+	return n == 1, err
+}
+
+func (r *RedisCache) ZRemRangeByScoreCtx(ctx context.Context, key string, min, max float64) (int64, error) {
+	return r.client.ZRemRangeByScore(ctx, r.buildKey(key), formatScore(min), formatScore(max)).Result()
+}
+
+// RunScriptCtx 执行Lua脚本，keys加上缓存键前缀
+func (r *RedisCache) RunScriptCtx(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.buildKey(key)
+	}
+	return script.Run(ctx, r.client, prefixed, args...).Result()
+}
+
+// formatScore 将分数转换为Redis的范围参数
+func formatScore(score float64) string {
+	switch {
+	case math.IsInf(score, -1):
+		return "-inf"
+	case math.IsInf(score, 1):
+		return "+inf"
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
 func (r *RedisCache) DelCtx(ctx context.Context, keys ...string) (int64, error) {
 	// 转换所有键为带前缀的键
 	prefixedKeys := make([]string, len(keys))
@@ -303,86 +343,86 @@ func (r *RedisCache) TTLCtx(ctx context.Context, key string) (time.Duration, err
 
 // 字符串操作
 func (r *RedisCache) IncrCtx(ctx context.Context, key string) (int64, error) {
-	return r.client.Incr(ctx, key).Result()
+	return r.client.Incr(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) DecrCtx(ctx context.Context, key string) (int64, error) {
-	return r.client.Decr(ctx, key).Result()
+	return r.client.Decr(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) IncrByCtx(ctx context.Context, key string, value int64) (int64, error) {
-	return r.client.IncrBy(ctx, key, value).Result()
+	return r.client.IncrBy(ctx, r.buildKey(key), value).Result()
 }
 
 // 哈希操作
 func (r *RedisCache) HGetCtx(ctx context.Context, key, field string) (string, error) {
-	return r.client.HGet(ctx, key, field).Result()
+	return r.client.HGet(ctx, r.buildKey(key), field).Result()
 }
 
 func (r *RedisCache) HSetCtx(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return r.client.HSet(ctx, key, values...).Result()
+	return r.client.HSet(ctx, r.buildKey(key), values...).Result()
 }
 
 func (r *RedisCache) HDelCtx(ctx context.Context, key string, fields ...string) (int64, error) {
-	return r.client.HDel(ctx, key, fields...).Result()
+	return r.client.HDel(ctx, r.buildKey(key), fields...).Result()
 }
 
 func (r *RedisCache) HGetAllCtx(ctx context.Context, key string) (map[string]string, error) {
-	return r.client.HGetAll(ctx, key).Result()
+	return r.client.HGetAll(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) HExistsCtx(ctx context.Context, key, field string) (bool, error) {
-	return r.client.HExists(ctx, key, field).Result()
+	return r.client.HExists(ctx, r.buildKey(key), field).Result()
 }
 
 func (r *RedisCache) HLenCtx(ctx context.Context, key string) (int64, error) {
-	return r.client.HLen(ctx, key).Result()
+	return r.client.HLen(ctx, r.buildKey(key)).Result()
 }
 
 // 列表操作
 func (r *RedisCache) LPushCtx(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return r.client.LPush(ctx, key, values...).Result()
+	return r.client.LPush(ctx, r.buildKey(key), values...).Result()
 }
 
 func (r *RedisCache) RPushCtx(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return r.client.RPush(ctx, key, values...).Result()
+	return r.client.RPush(ctx, r.buildKey(key), values...).Result()
 }
 
 func (r *RedisCache) LPopCtx(ctx context.Context, key string) (string, error) {
-	return r.client.LPop(ctx, key).Result()
+	return r.client.LPop(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) RPopCtx(ctx context.Context, key string) (string, error) {
-	return r.client.RPop(ctx, key).Result()
+	return r.client.RPop(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) LLenCtx(ctx context.Context, key string) (int64, error) {
-	return r.client.LLen(ctx, key).Result()
+	return r.client.LLen(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) LRangeCtx(ctx context.Context, key string, start, stop int64) ([]string, error) {
-	return r.client.LRange(ctx, key, start, stop).Result()
+	return r.client.LRange(ctx, r.buildKey(key), start, stop).Result()
 }
 
 // 集合操作
 func (r *RedisCache) SAddCtx(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return r.client.SAdd(ctx, key, members...).Result()
+	return r.client.SAdd(ctx, r.buildKey(key), members...).Result()
 }
 
 func (r *RedisCache) SRemCtx(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return r.client.SRem(ctx, key, members...).Result()
+	return r.client.SRem(ctx, r.buildKey(key), members...).Result()
 }
 
 func (r *RedisCache) SMembersCtx(ctx context.Context, key string) ([]string, error) {
-	return r.client.SMembers(ctx, key).Result()
+	return r.client.SMembers(ctx, r.buildKey(key)).Result()
 }
 
 func (r *RedisCache) SIsMemberCtx(ctx context.Context, key string, member interface{}) (bool, error) {
-	return r.client.SIsMember(ctx, key, member).Result()
+	return r.client.SIsMember(ctx, r.buildKey(key), member).Result()
 }
 
 func (r *RedisCache) SCardCtx(ctx context.Context, key string) (int64, error) {
-	return r.client.SCard(ctx, key).Result()
+	return r.client.SCard(ctx, r.buildKey(key)).Result()
 }
 
 // 有序集合操作
@@ -435,7 +475,21 @@ func (r *RedisCache) ZScoreCtx(ctx context.Context, key, member string) (float64
 
 // 其他操作
 func (r *RedisCache) KeysCtx(ctx context.Context, pattern string) ([]string, error) {
-	return r.client.Keys(ctx, pattern).Result()
+	// 对于 Keys 操作，我们需要添加前缀到模式中
+	prefixedPattern := r.buildKey(pattern)
+	keys, err := r.client.Keys(ctx, prefixedPattern).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// 移除前缀
+	if r.prefix != "" {
+		for i, key := range keys {
+			keys[i] = key[len(r.prefix):]
+		}
+	}
+
+	return keys, nil
 }
 
 func (r *RedisCache) PingCtx(ctx context.Context) error {
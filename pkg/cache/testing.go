@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// TB 测试断言接口，*testing.T和*testing.B都实现了此接口
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	TempDir() string
+	Cleanup(func())
+}
+
+// NewTestMemory 创建测试使用的内存缓存，不输出日志，测试结束时关闭
+func NewTestMemory(t TB) Cache {
+	t.Helper()
+	c, err := NewMemoryCache(&config.Config{}, log.NewDiscardLogger())
+	if err != nil {
+		t.Fatalf("创建内存缓存失败: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// NewTestFile 在测试的临时目录中创建文件缓存，不输出日志，测试结束时关闭
+func NewTestFile(t TB) Cache {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Cache.FilePath = t.TempDir()
+	c, err := NewFileCache(cfg, log.NewDiscardLogger())
+	if err != nil {
+		t.Fatalf("创建文件缓存失败: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -17,6 +18,11 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Log      LogConfig      `mapstructure:"log"`
 	Cache    CacheConfig    `mapstructure:"cache"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Session  SessionConfig  `mapstructure:"session"`
+	Health   HealthConfig   `mapstructure:"health"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
 }
 
 // AppConfig 应用配置
@@ -30,11 +36,56 @@ type AppConfig struct {
 type HTTPConfig struct {
 	Host           string
 	Port           int
-	Engine         string // 引擎类型："gin" 或 "fiber"
+	Engine         string // 引擎类型："gin"、"fiber" 或 "std"
 	ReadTimeout    time.Duration
 	WriteTimeout   time.Duration
 	MaxHeaderBytes int
-	MaxBodySize    int // 请求体大小限制
+	MaxBodySize    int      // 请求体大小限制
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信代理的IP或CIDR，只有来自可信代理的请求才读取X-Forwarded-For
+	OpenAPI        OpenAPIConfig
+	Cookie         CookieConfig
+	CORS           CORSConfig
+	Compression    CompressionConfig
+}
+
+// OpenAPIConfig OpenAPI文档配置
+type OpenAPIConfig struct {
+	Enable      bool   // 是否提供在线文档
+	Path        string // 文档访问路径
+	Title       string // 文档标题，默认使用应用名称
+	Description string // 文档描述
+	Version     string // 文档版本，默认使用应用版本
+}
+
+// CookieConfig Cookie配置
+type CookieConfig struct {
+	Path       string // 默认路径
+	Domain     string // 默认域名
+	Secure     bool   // 是否只通过HTTPS发送
+	HTTPOnly   bool   `mapstructure:"http_only"`   // 是否禁止脚本访问
+	SameSite   string `mapstructure:"same_site"`   // SameSite属性：lax、strict 或 none
+	SignKey    string `mapstructure:"sign_key"`    // 签名Cookie的密钥
+	EncryptKey string `mapstructure:"encrypt_key"` // 加密Cookie的密钥
+}
+
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	Enable           bool          // 是否启用全局跨域中间件
+	AllowOrigins     []string      `mapstructure:"allow_origins"`     // 允许的来源，支持"*"和"https://*.example.com"
+	AllowMethods     []string      `mapstructure:"allow_methods"`     // 允许的请求方法
+	AllowHeaders     []string      `mapstructure:"allow_headers"`     // 允许的请求头，为空时允许预检请求声明的所有请求头
+	ExposeHeaders    []string      `mapstructure:"expose_headers"`    // 允许浏览器读取的响应头
+	AllowCredentials bool          `mapstructure:"allow_credentials"` // 是否允许携带凭证
+	MaxAge           time.Duration `mapstructure:"max_age"`           // 预检结果的缓存时间
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	Enable       bool     // 是否启用全局压缩中间件
+	Level        int      // 压缩级别，0使用默认级别
+	MinSize      int      `mapstructure:"min_size"`      // 压缩的最小响应体大小（字节）
+	ContentTypes []string `mapstructure:"content_types"` // 压缩的内容类型，支持"text/*"
+	Encodings    []string // 支持的编码，按优先顺序排列：br、gzip、deflate
 }
 
 // DatabaseConfig 数据库配置
@@ -65,6 +116,66 @@ type CacheConfig struct {
 	FilePath string // 文件缓存路径，仅当 Type 为 file 时使用
 }
 
+// JWTConfig JWT认证配置
+type JWTConfig struct {
+	Keys       []JWTKeyConfig // 签名密钥，轮换密钥时保留旧密钥用于验证未过期的token
+	SigningKey string         `mapstructure:"signing_key"` // 签发token使用的密钥ID，默认使用第一个密钥
+	Issuer     string         // 签发者（iss）
+	Audience   []string       // 接收者（aud）
+	AccessTTL  time.Duration  `mapstructure:"access_ttl"`  // 访问token有效期
+	RefreshTTL time.Duration  `mapstructure:"refresh_ttl"` // 刷新token有效期
+	Leeway     time.Duration  // 验证过期时间时允许的时钟误差
+	Header     string         // 读取token的请求头
+	Scheme     string         // 请求头中token的前缀，默认Bearer
+	Query      string         // 读取token的查询参数，为空时不从查询参数读取
+	Cookie     string         // 读取token的Cookie，为空时不从Cookie读取
+}
+
+// JWTKeyConfig JWT签名密钥配置
+type JWTKeyConfig struct {
+	ID         string // 密钥ID（kid）
+	Algorithm  string // 签名算法：HS256、RS256 或 EdDSA
+	Secret     string // HS256的密钥
+	PrivateKey string `mapstructure:"private_key"` // RS256和EdDSA的PEM私钥或私钥文件路径，只用于验证时可以为空
+	PublicKey  string `mapstructure:"public_key"`  // RS256和EdDSA的PEM公钥或公钥文件路径，为空时从私钥生成
+}
+
+// SessionConfig 会话配置
+type SessionConfig struct {
+	Cookie string        // 保存会话ID的Cookie，为空时不使用Cookie
+	Header string        // 读取和返回会话ID的请求头，为空时不使用请求头，例如X-Session-ID
+	TTL    time.Duration // 会话空闲过期时间，每次请求后重新计算
+}
+
+// HealthConfig 健康检查配置
+type HealthConfig struct {
+	LivenessPath  string        `mapstructure:"liveness_path"`  // 存活检查路径
+	ReadinessPath string        `mapstructure:"readiness_path"` // 就绪检查路径
+	Timeout       time.Duration // 就绪检查的超时时间
+	DrainDelay    time.Duration `mapstructure:"drain_delay"`   // 就绪检查失败后等待多久再关闭服务器，应大于负载均衡的检查间隔，最大15s
+	DiskMinFree   int64         `mapstructure:"disk_min_free"` // 文件缓存目录所在磁盘的最小剩余空间（字节）
+}
+
+// MetricsConfig 指标配置
+type MetricsConfig struct {
+	Enable    bool      // 是否收集HTTP、数据库和缓存指标并提供指标路由
+	Path      string    // 指标访问路径
+	Namespace string    // 指标名称前缀，例如goframe
+	Buckets   []float64 // 耗时直方图的分桶（秒），为空时使用默认分桶
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enable      bool              // 是否启用链路追踪
+	ServiceName string            `mapstructure:"service_name"` // 服务名称，默认使用应用名称
+	Exporter    string            // 导出方式: otlp、stdout 或 file
+	Endpoint    string            // OTLP/HTTP接收端地址，例如localhost:4318，为空时使用OTEL_EXPORTER_OTLP_ENDPOINT环境变量
+	Insecure    bool              // OTLP是否使用HTTP而不是HTTPS
+	Headers     map[string]string // OTLP请求头，例如认证信息
+	FilePath    string            `mapstructure:"file_path"`    // file导出方式的文件路径
+	SampleRatio float64           `mapstructure:"sample_ratio"` // 采样比例，取值(0,1]，默认1，上游已采样的请求总是采样
+}
+
 // NewConfig 创建配置
 // 修改 pkg/config/config.go 中的 NewConfig 函数
 func NewConfig() (*Config, error) {
@@ -107,10 +218,32 @@ func NewConfig() (*Config, error) {
 	if err := v.Unmarshal(config); err != nil {
 		return nil, fmt.Errorf("解析配置错误: %w", err)
 	}
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
 
+// validateConfig 检查不安全或互相矛盾的配置
+func validateConfig(config *Config) error {
+	cors := config.HTTP.CORS
+	if cors.AllowCredentials {
+		for _, origin := range cors.AllowOrigins {
+			// 允许所有来源的同时允许携带凭证，任何网站都能以用户身份读取响应
+			if origin = strings.TrimSpace(origin); origin == "*" || strings.HasSuffix(origin, "*") {
+				return fmt.Errorf("http.cors.allow_credentials为true时allow_origins不能包含%q，需要列出具体的来源", origin)
+			}
+		}
+	}
+
+	// 等待时间加上HTTP服务10秒的关闭超时需要在应用30秒的关闭超时内完成，并给其他组件留出关闭时间
+	if config.Health.DrainDelay < 0 || config.Health.DrainDelay > 15*time.Second {
+		return fmt.Errorf("health.drain_delay必须在0到15s之间，当前为%s", config.Health.DrainDelay)
+	}
+	return nil
+}
+
 // 设置默认值
 func setDefaultConfig(config *Config) {
 	if config.App.Name == "" {
@@ -145,6 +278,15 @@ func setDefaultConfig(config *Config) {
 	if config.HTTP.MaxBodySize == 0 {
 		config.HTTP.MaxBodySize = 4 << 20 // 4MB
 	}
+	if config.HTTP.OpenAPI.Path == "" {
+		config.HTTP.OpenAPI.Path = "/openapi.json"
+	}
+	if config.HTTP.Cookie.Path == "" {
+		config.HTTP.Cookie.Path = "/"
+	}
+	if config.HTTP.Cookie.SameSite == "" {
+		config.HTTP.Cookie.SameSite = "lax"
+	}
 
 	// 日志默认配置
 	if config.Log.Level == "" {
@@ -192,6 +334,61 @@ func setDefaultConfig(config *Config) {
 		cwd, _ := os.Getwd()
 		config.Cache.FilePath = filepath.Join(cwd, "storage", "cache")
 	}
+
+	// JWT默认配置
+	if config.JWT.AccessTTL == 0 {
+		config.JWT.AccessTTL = 15 * time.Minute
+	}
+	if config.JWT.RefreshTTL == 0 {
+		config.JWT.RefreshTTL = 7 * 24 * time.Hour
+	}
+	if config.JWT.Header == "" {
+		config.JWT.Header = "Authorization"
+	}
+	if config.JWT.Scheme == "" {
+		config.JWT.Scheme = "Bearer"
+	}
+
+	// 会话默认配置
+	if config.Session.Cookie == "" && config.Session.Header == "" {
+		config.Session.Cookie = "session_id"
+	}
+	if config.Session.TTL == 0 {
+		config.Session.TTL = 2 * time.Hour
+	}
+
+	// 健康检查默认配置
+	if config.Health.LivenessPath == "" {
+		config.Health.LivenessPath = "/healthz"
+	}
+	if config.Health.ReadinessPath == "" {
+		config.Health.ReadinessPath = "/readyz"
+	}
+	if config.Health.Timeout == 0 {
+		config.Health.Timeout = 3 * time.Second
+	}
+	if config.Health.DrainDelay == 0 {
+		config.Health.DrainDelay = 5 * time.Second
+	}
+	if config.Health.DiskMinFree == 0 {
+		config.Health.DiskMinFree = 100 << 20 // 100MB
+	}
+
+	// 指标默认配置
+	if config.Metrics.Path == "" {
+		config.Metrics.Path = "/metrics"
+	}
+
+	// 链路追踪默认配置
+	if config.Tracing.Exporter == "" {
+		config.Tracing.Exporter = "otlp"
+	}
+	if config.Tracing.FilePath == "" {
+		config.Tracing.FilePath = "logs/traces.jsonl"
+	}
+	if config.Tracing.SampleRatio <= 0 || config.Tracing.SampleRatio > 1 {
+		config.Tracing.SampleRatio = 1
+	}
 }
 
 // Module 提供配置模块
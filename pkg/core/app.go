@@ -67,6 +67,19 @@ func (a *App) WithOptions(opts ...fx.Option) *App {
 	return a
 }
 
+// Name 获取应用名称
+func (a *App) Name() string {
+	return a.name
+}
+
+// Options 获取应用的全部fx选项
+// 命令行工具可以借此构建应用容器（不启动服务）以读取路由等信息
+func (a *App) Options() []fx.Option {
+	opts := make([]fx.Option, len(a.opts))
+	copy(opts, a.opts)
+	return opts
+}
+
 // Run 运行应用
 func (a *App) Run() {
 	a.RunWithOptions(true)
@@ -106,7 +119,8 @@ func (a *App) RunWithOptions(blocking bool) {
 		a.logger.Info("正在关闭应用...")
 
 		// 关闭应用
-		stopCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		// 包含就绪检查失败后的等待时间和HTTP服务的关闭超时
+		stopCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
 		if err := fxApp.Stop(stopCtx); err != nil {
@@ -0,0 +1,34 @@
+package database
+
+import "gorm.io/gorm"
+
+// Operations 注册回调的语句类型，与gorm的回调处理器一一对应
+var Operations = []string{"create", "query", "update", "delete", "row", "raw"}
+
+// RegisterCallbacks 在每种语句执行前后注册回调，用于统计指标和链路追踪，name区分不同的注册方，例如:
+//
+//	database.RegisterCallbacks(db, "metrics", func(op string) func(*gorm.DB) {...}, func(op string) func(*gorm.DB) {...})
+//
+// 回调名称为name:before_<op>和name:after_<op>，在gorm:<op>之前和之后执行
+func RegisterCallbacks(db *gorm.DB, name string, before, after func(operation string) func(tx *gorm.DB)) error {
+	// gorm的回调处理器类型未导出，这里使用Register方法值
+	callback := db.Callback()
+	processors := map[string][2]func(name string, fn func(*gorm.DB)) error{
+		"create": {callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		"query":  {callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		"update": {callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		"delete": {callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		"row":    {callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		"raw":    {callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+	for _, operation := range Operations {
+		register := processors[operation]
+		if err := register[0](name+":before_"+operation, before(operation)); err != nil {
+			return err
+		}
+		if err := register[1](name+":after_"+operation, after(operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
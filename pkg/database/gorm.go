@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/glebarez/sqlite" // 纯Go的SQLite实现，不需要CGO
+	"github.com/sirupsen/logrus"
 	"github.com/zhoudm1743/go-frame/pkg/config"
 	"github.com/zhoudm1743/go-frame/pkg/log"
 	"go.uber.org/fx"
@@ -60,16 +61,16 @@ func NewDB(p DBParams) (*gorm.DB, error) {
 		logLevel = logger.Silent
 	}
 
-	// 自定义GORM日志适配器
-	gormLogger := logger.New(
-		&logWriter{p.Logger},
-		logger.Config{
+	// 自定义GORM日志适配器，通过db.WithContext传入的上下文中的请求ID会加入日志字段
+	gormLogger := &contextLogger{
+		logger: p.Logger,
+		config: logger.Config{
 			SlowThreshold:             time.Second, // 慢查询阈值
 			LogLevel:                  logLevel,
 			IgnoreRecordNotFoundError: true,  // 忽略记录未找到错误
 			Colorful:                  false, // 禁用彩色打印
 		},
-	)
+	}
 
 	// 打开数据库连接
 	db, err := gorm.Open(dialector, &gorm.Config{
@@ -95,13 +96,55 @@ func NewDB(p DBParams) (*gorm.DB, error) {
 
 // logWriter 日志写入器，将GORM日志适配到我们的Logger接口
 type logWriter struct {
-	Logger log.Logger
+	Logger *logrus.Entry
 }
 
 func (w *logWriter) Printf(format string, args ...interface{}) {
 	w.Logger.Infof(format, args...)
 }
 
+// contextLogger GORM日志记录器，每条日志使用调用时的上下文创建日志条目
+type contextLogger struct {
+	logger log.Logger
+	config logger.Config
+}
+
+// with 创建关联上下文的GORM日志记录器
+func (l *contextLogger) with(ctx context.Context) logger.Interface {
+	return logger.New(&logWriter{l.logger.WithContext(ctx)}, l.config)
+}
+
+// LogMode 实现logger.Interface接口
+func (l *contextLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.config.LogLevel = level
+	return &newLogger
+}
+
+// Info 实现logger.Interface接口
+func (l *contextLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.with(ctx).Info(ctx, msg, data...)
+}
+
+// Warn 实现logger.Interface接口
+func (l *contextLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.with(ctx).Warn(ctx, msg, data...)
+}
+
+// Error 实现logger.Interface接口
+func (l *contextLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.with(ctx).Error(ctx, msg, data...)
+}
+
+// Trace 实现logger.Interface接口
+func (l *contextLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	// 未达到日志级别时不创建日志条目
+	if l.config.LogLevel <= logger.Silent {
+		return
+	}
+	l.with(ctx).Trace(ctx, begin, fc, err)
+}
+
 // OnStop 数据库关闭钩子
 func OnStop(db *gorm.DB) error {
 	sqlDB, err := db.DB()
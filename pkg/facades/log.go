@@ -1,6 +1,8 @@
 package facades
 
 import (
+	"context"
+
 	"github.com/sirupsen/logrus"
 	"github.com/zhoudm1743/go-frame/pkg/log"
 )
@@ -65,6 +67,11 @@ func (l *LogFacade) WithFields(fields logrus.Fields) *logrus.Entry {
 	return GetLogger().WithFields(fields)
 }
 
+// WithContext 创建关联context.Context的日志条目
+func (l *LogFacade) WithContext(ctx context.Context) *logrus.Entry {
+	return GetLogger().WithContext(ctx)
+}
+
 // Instance 获取原始日志实例
 func (l *LogFacade) Instance() log.Logger {
 	return GetLogger()
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"gorm.io/gorm"
+)
+
+// NewDBChecker 数据库检查器，检查连接池能否连接数据库
+func NewDBChecker(db *gorm.DB) Checker {
+	return NewChecker("database", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+}
+
+// NewCacheChecker 缓存检查器
+func NewCacheChecker(c cache.Cache) Checker {
+	return NewChecker("cache", c.PingCtx)
+}
+
+// NewDiskChecker 磁盘检查器，path所在磁盘的剩余空间小于minFree字节时失败
+func NewDiskChecker(path string, minFree uint64) Checker {
+	return NewChecker("disk", func(ctx context.Context) error {
+		free, err := freeSpace(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s 所在磁盘剩余空间不足: %d MB，最少需要 %d MB", path, free>>20, minFree>>20)
+		}
+		return nil
+	})
+}
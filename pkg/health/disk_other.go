@@ -0,0 +1,10 @@
+//go:build !unix
+
+package health
+
+import "math"
+
+// freeSpace 当前平台不支持检查磁盘空间，检查总是通过
+func freeSpace(path string) (uint64, error) {
+	return math.MaxUint64, nil
+}
@@ -0,0 +1,181 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"go.uber.org/fx"
+)
+
+// CheckersGroup 健康检查器所在的fx值组名称
+const CheckersGroup = "health_checkers"
+
+// 检查状态
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker 健康检查器，模块通过ProvideChecker加入就绪检查
+type Checker interface {
+	// Name 检查项名称，在检查结果中区分各个检查器
+	Name() string
+	// Check 执行检查，返回错误表示不可用，ctx带有超时时间
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 函数形式的检查器
+type CheckerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// NewChecker 使用函数创建检查器，例如:
+//
+//	health.NewChecker("payment", func(ctx context.Context) error { return client.Ping(ctx) })
+func NewChecker(name string, fn func(ctx context.Context) error) *CheckerFunc {
+	return &CheckerFunc{name: name, fn: fn}
+}
+
+// Name 实现Checker接口
+func (c *CheckerFunc) Name() string {
+	return c.name
+}
+
+// Check 实现Checker接口
+func (c *CheckerFunc) Check(ctx context.Context) error {
+	return c.fn(ctx)
+}
+
+// ProvideChecker 将构造函数返回的检查器加入health_checkers组，构造函数的参数从容器中获取，例如:
+//
+//	health.ProvideChecker(func(client *payment.Client) health.Checker {
+//	    return health.NewChecker("payment", client.Ping)
+//	}),
+func ProvideChecker(constructor interface{}) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			constructor,
+			fx.As(new(Checker)),
+			fx.ResultTags(`group:"`+CheckersGroup+`"`),
+		),
+	)
+}
+
+// Result 单个检查项的结果
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report 就绪检查结果
+type Report struct {
+	Status       string            `json:"status"`
+	ShuttingDown bool              `json:"shutting_down,omitempty"`
+	Checks       map[string]Result `json:"checks,omitempty"`
+}
+
+// Up 是否所有检查都通过
+func (r *Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Health 汇总所有检查器，应用开始关闭后就绪检查立即失败，负载均衡在连接关闭前停止转发流量
+type Health struct {
+	checkers     []Checker
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// Params 健康检查参数
+type Params struct {
+	fx.In
+	Config   *config.Config
+	Checkers []Checker `group:"health_checkers"`
+}
+
+// New 创建健康检查
+func New(p Params) *Health {
+	checkers := make([]Checker, 0, len(p.Checkers))
+	for _, checker := range p.Checkers {
+		if checker != nil {
+			checkers = append(checkers, checker)
+		}
+	}
+	// fx值组的顺序不固定，按名称排序保证结果稳定
+	sort.SliceStable(checkers, func(i, j int) bool {
+		return checkers[i].Name() < checkers[j].Name()
+	})
+	timeout := p.Config.Health.Timeout
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &Health{checkers: checkers, timeout: timeout}
+}
+
+// Check 并发执行所有检查器，任一检查失败或应用正在关闭时状态为down
+func (h *Health) Check(ctx context.Context) *Report {
+	if h.shuttingDown.Load() {
+		return &Report{Status: StatusDown, ShuttingDown: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := &Report{Status: StatusUp, Checks: make(map[string]Result, len(h.checkers))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, checker := range h.checkers {
+		wg.Add(1)
+		go func(checker Checker) {
+			defer wg.Done()
+			start := time.Now()
+			err := check(ctx, checker)
+			result := Result{Status: StatusUp, Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.Name()] = result
+			if err != nil {
+				report.Status = StatusDown
+			}
+		}(checker)
+	}
+	wg.Wait()
+	return report
+}
+
+// Shutdown 标记应用正在关闭，之后的就绪检查都失败
+func (h *Health) Shutdown() {
+	h.shuttingDown.Store(true)
+}
+
+// ShuttingDown 应用是否正在关闭
+func (h *Health) ShuttingDown() bool {
+	return h.shuttingDown.Load()
+}
+
+// check 执行检查，超时后不再等待检查器返回
+func check(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
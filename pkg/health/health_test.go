@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+// newHealth 使用指定的超时时间和检查器创建健康检查
+func newHealth(timeout time.Duration, checkers ...Checker) *Health {
+	cfg := &config.Config{}
+	cfg.Health.Timeout = timeout
+	return New(Params{Config: cfg, Checkers: checkers})
+}
+
+// up 总是通过的检查器
+func up(name string) Checker {
+	return NewChecker(name, func(ctx context.Context) error { return nil })
+}
+
+func TestCheck(t *testing.T) {
+	errDown := errors.New("connection refused")
+	h := newHealth(time.Second,
+		up("cache"),
+		nil,
+		NewChecker("database", func(ctx context.Context) error { return errDown }),
+	)
+
+	report := h.Check(context.Background())
+	if report.Up() || report.Status != StatusDown {
+		t.Errorf("任一检查失败时状态 %q，期望 down", report.Status)
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("检查结果 %v，期望 cache 和 database", report.Checks)
+	}
+	if r := report.Checks["cache"]; r.Status != StatusUp || r.Error != "" {
+		t.Errorf("cache: %+v", r)
+	}
+	if r := report.Checks["database"]; r.Status != StatusDown || r.Error != errDown.Error() {
+		t.Errorf("database: %+v", r)
+	}
+
+	if report := newHealth(time.Second, up("a"), up("b")).Check(context.Background()); !report.Up() {
+		t.Errorf("所有检查通过时状态 %q，期望 up", report.Status)
+	}
+	if report := newHealth(time.Second).Check(context.Background()); !report.Up() {
+		t.Errorf("没有检查器时状态 %q，期望 up", report.Status)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	// 忽略ctx的检查器，超时后不再等待它返回
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	h := newHealth(50*time.Millisecond,
+		up("cache"),
+		NewChecker("payment", func(ctx context.Context) error {
+			<-block
+			return nil
+		}),
+	)
+
+	start := time.Now()
+	report := h.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("检查耗时 %s，超时后仍在等待检查器", elapsed)
+	}
+	if r := report.Checks["payment"]; r.Status != StatusDown || r.Error != context.DeadlineExceeded.Error() {
+		t.Errorf("超时的检查: %+v", r)
+	}
+	if report.Up() || report.Checks["cache"].Status != StatusUp {
+		t.Errorf("检查结果 %+v", report)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	var calls int32
+	h := newHealth(time.Second, NewChecker("cache", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}))
+	if !h.Check(context.Background()).Up() {
+		t.Fatal("关闭前就绪检查失败")
+	}
+
+	h.Shutdown()
+	report := h.Check(context.Background())
+	if report.Up() || !report.ShuttingDown || !h.ShuttingDown() {
+		t.Errorf("关闭后的检查结果 %+v", report)
+	}
+	if calls != 1 {
+		t.Errorf("关闭后仍然执行检查器，执行 %d 次", calls)
+	}
+}
+
+func TestDiskChecker(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewDiskChecker(dir, 1).Check(context.Background()); err != nil {
+		t.Errorf("剩余空间充足: %v", err)
+	}
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free == math.MaxUint64 {
+		t.Skip("当前平台不支持检查磁盘空间")
+	}
+	if err := NewDiskChecker(dir, free+1<<30).Check(context.Background()); err == nil {
+		t.Error("剩余空间不足时检查通过")
+	}
+	if err := NewDiskChecker(dir+"/missing", 1).Check(context.Background()); err == nil {
+		t.Error("目录不存在时检查通过")
+	}
+}
+
+// 模块提供的内置检查器：缓存总是检查，使用文件缓存时检查缓存目录所在的磁盘，没有数据库时不检查数据库
+func TestModule(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cache.Type = "file"
+	cfg.Cache.FilePath = t.TempDir()
+
+	var h *Health
+	app := fxtest.New(t,
+		fx.Supply(cfg),
+		fx.Provide(func() cache.Cache { return cache.NewTestMemory(t) }),
+		Module,
+		fx.Populate(&h),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	report := h.Check(context.Background())
+	if !report.Up() {
+		t.Errorf("就绪检查失败: %+v", report)
+	}
+	for _, name := range []string{"cache", "disk"} {
+		if _, ok := report.Checks[name]; !ok {
+			t.Errorf("缺少检查项 %s: %v", name, report.Checks)
+		}
+	}
+	if _, ok := report.Checks["database"]; ok {
+		t.Error("没有数据库时检查了数据库")
+	}
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Module 健康检查模块，提供*Health和内置的数据库、缓存、磁盘检查器
+var Module = fx.Options(
+	fx.Provide(New),
+	ProvideChecker(databaseChecker),
+	ProvideChecker(NewCacheChecker),
+	ProvideChecker(diskChecker),
+)
+
+// databaseParams 数据库检查器参数，应用没有使用数据库时不检查
+type databaseParams struct {
+	fx.In
+	DB *gorm.DB `optional:"true"`
+}
+
+// databaseChecker 创建内置的数据库检查器
+func databaseChecker(p databaseParams) Checker {
+	if p.DB == nil {
+		return nil
+	}
+	return NewDBChecker(p.DB)
+}
+
+// diskChecker 创建内置的磁盘检查器，只在使用文件缓存时检查缓存目录所在的磁盘
+func diskChecker(cfg *config.Config) Checker {
+	if cfg.Cache.Type != "file" {
+		return nil
+	}
+	return NewDiskChecker(cfg.Cache.FilePath, uint64(cfg.Health.DiskMinFree))
+}
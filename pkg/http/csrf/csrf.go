@@ -0,0 +1,282 @@
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/auth/jwt"
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// Mode token的保存方式
+type Mode string
+
+const (
+	// DoubleSubmit 双重提交Cookie，token保存在Cookie中，请求需要通过请求头或表单提交相同的token，服务端不保存状态，
+	// 配置了http.cookie.sign_key时Cookie使用SetSignedCookie签名
+	DoubleSubmit Mode = "double_submit"
+	// Synchronizer 同步token，token保存在服务端，使用会话中间件时保存在会话中，否则保存在缓存中并通过Cookie关联
+	Synchronizer Mode = "synchronizer"
+)
+
+// TokenKey CSRF token在上下文数据中的键
+const TokenKey = "csrf_token"
+
+const (
+	// fieldKey 表单字段名在上下文数据中的键，供模板函数生成隐藏字段
+	fieldKey = "csrf_field"
+	// sessionField token在会话中的字段
+	sessionField = "_csrf_token"
+	// keyPrefix 不使用会话时token的缓存键前缀
+	keyPrefix = "csrf:"
+)
+
+// ErrTokenInvalid 请求没有提交CSRF token或token不匹配
+var ErrTokenInvalid = response.NoPermission.Make("CSRF token无效或缺失")
+
+// Config CSRF中间件配置
+type Config struct {
+	// Mode token的保存方式，默认DoubleSubmit
+	Mode Mode
+	// Cookie 双重提交模式保存token的Cookie，同步token模式不使用会话时保存缓存键的Cookie，默认csrf_token
+	Cookie string
+	// Header 提交token的请求头，同时通过该响应头返回token，默认X-CSRF-Token
+	Header string
+	// Field 提交token的表单字段，默认_csrf
+	Field string
+	// TTL token有效期，默认12小时，使用会话时与会话的有效期一致
+	TTL time.Duration
+	// Skip 返回true的请求不检查token，例如SkipBearer
+	Skip func(c unified.Context) bool
+}
+
+// Protector CSRF防护，GET、HEAD、OPTIONS、TRACE请求生成token，其他请求检查token
+type Protector struct {
+	cache  cache.Cache
+	logger log.Logger
+}
+
+// NewProtector 创建CSRF防护
+func NewProtector(cache cache.Cache, logger log.Logger) *Protector {
+	return &Protector{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Middleware CSRF中间件，保护使用Cookie或会话认证、通过表单提交的路由，例如:
+//
+//	admin := router.Group("/admin", sessionManager.Middleware(), protector.Middleware(csrf.Config{Mode: csrf.Synchronizer}))
+//	admin.GET("/users/new", func(c unified.Context) error {
+//	    return c.HTML(http.StatusOK, `<form method="post">`+string(csrf.Field(c))+`...</form>`)
+//	})
+//
+// token依次从请求头和表单字段读取，不匹配时返回ErrTokenInvalid，同步token模式使用会话时需要在会话中间件之后执行
+func (p *Protector) Middleware(config ...Config) unified.MiddlewareFunc {
+	cfg := Config{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = DoubleSubmit
+	}
+	if cfg.Cookie == "" {
+		cfg.Cookie = "csrf_token"
+	}
+	if cfg.Header == "" {
+		cfg.Header = "X-CSRF-Token"
+	}
+	if cfg.Field == "" {
+		cfg.Field = "_csrf"
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 12 * time.Hour
+	}
+
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return next(c)
+			}
+
+			token, err := p.load(c, cfg)
+			if err != nil {
+				return err
+			}
+			if !safeMethod(c.Method()) {
+				if token == "" || !equal(submitted(c, cfg), token) {
+					return ErrTokenInvalid
+				}
+			}
+			if token == "" {
+				if token, err = p.save(c, cfg); err != nil {
+					return err
+				}
+			}
+
+			c.Set(TokenKey, token)
+			c.Set(fieldKey, cfg.Field)
+			c.SetHeader(cfg.Header, token)
+			return next(c)
+		}
+	}
+}
+
+// SkipBearer 跳过已由jwt认证中间件通过请求头中的token认证的请求，浏览器跨站请求不会自动携带该请求头，
+// 同时提供页面和JSON接口的路由组可以使用Config{Skip: csrf.SkipBearer}，jwt中间件需要在CSRF中间件之前执行；
+// 只携带Authorization请求头而没有通过认证，或者token来自Cookie的请求仍然检查CSRF token
+func SkipBearer(c unified.Context) bool {
+	return jwt.HeaderAuthenticated(c)
+}
+
+// load 读取当前的token，没有或格式不合法时返回空字符串
+func (p *Protector) load(c unified.Context, cfg Config) (string, error) {
+	var token string
+	switch {
+	case cfg.Mode == DoubleSubmit:
+		token = readDoubleSubmit(c, cfg.Cookie)
+	case hasSession(c):
+		token, _ = c.Session().Get(sessionField)
+	default:
+		id, _ := c.Cookie(cfg.Cookie)
+		if !validToken(id) {
+			return "", nil
+		}
+		value, err := p.cache.GetCtx(c.Context(), keyPrefix+id)
+		if err != nil {
+			if cache.IsMiss(err) {
+				return "", nil
+			}
+			p.logger.WithContext(c.Context()).WithError(err).Warn("读取CSRF token失败")
+			return "", err
+		}
+		token = value
+	}
+	if !validToken(token) {
+		return "", nil
+	}
+	// Fiber返回的Cookie引用请求缓冲区，复制后保存到上下文数据
+	return strings.Clone(token), nil
+}
+
+// save 生成新的token并保存，需要在写入响应之前调用
+func (p *Protector) save(c unified.Context, cfg Config) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case cfg.Mode == DoubleSubmit:
+		if err := writeDoubleSubmit(c, &http.Cookie{
+			Name:   cfg.Cookie,
+			Value:  token,
+			MaxAge: int(cfg.TTL / time.Second),
+		}); err != nil {
+			return "", err
+		}
+	case hasSession(c):
+		if err := c.Session().Set(sessionField, token); err != nil {
+			return "", err
+		}
+	default:
+		id, err := newToken()
+		if err != nil {
+			return "", err
+		}
+		if err := p.cache.SetCtx(c.Context(), keyPrefix+id, token, cfg.TTL); err != nil {
+			p.logger.WithContext(c.Context()).WithError(err).Warn("保存CSRF token失败")
+			return "", err
+		}
+		c.SetCookie(&http.Cookie{
+			Name:     cfg.Cookie,
+			Value:    id,
+			MaxAge:   int(cfg.TTL / time.Second),
+			HttpOnly: true,
+		})
+	}
+	return token, nil
+}
+
+// readDoubleSubmit 读取双重提交模式的Cookie，配置了Cookie签名密钥时只接受本服务签发的签名Cookie，
+// 其他子域名不能写入任意构造的token，签名无效时视为没有token
+func readDoubleSubmit(c unified.Context, name string) string {
+	token, err := c.SignedCookie(name)
+	if errors.Is(err, unified.ErrCookieKeyMissing) {
+		token, _ = c.Cookie(name)
+	}
+	return token
+}
+
+// writeDoubleSubmit 写入双重提交模式的Cookie，配置了Cookie签名密钥时写入签名Cookie
+func writeDoubleSubmit(c unified.Context, cookie *http.Cookie) error {
+	err := c.SetSignedCookie(cookie)
+	if errors.Is(err, unified.ErrCookieKeyMissing) {
+		c.SetCookie(cookie)
+		return nil
+	}
+	return err
+}
+
+// hasSession 判断是否使用了会话中间件
+func hasSession(c unified.Context) bool {
+	_, ok := c.Get(unified.SessionKey)
+	return ok
+}
+
+// submitted 读取请求提交的token，优先使用请求头，只有表单请求才读取表单字段，避免读取其他类型的请求体
+func submitted(c unified.Context, cfg Config) string {
+	if token := c.GetHeader(cfg.Header); token != "" {
+		return token
+	}
+	contentType := strings.ToLower(c.GetHeader("Content-Type"))
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") || strings.HasPrefix(contentType, "multipart/form-data") {
+		return c.FormValue(cfg.Field)
+	}
+	return ""
+}
+
+// safeMethod 判断是否是不修改数据的请求方法
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// equal 以固定时间比较token
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// newToken 生成256位随机token
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成CSRF token失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validToken 检查token格式，只接受newToken生成的格式
+func validToken(token string) bool {
+	if len(token) != 43 {
+		return false
+	}
+	for i := 0; i < len(token); i++ {
+		ch := token[i]
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+package csrf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/auth/jwt"
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/session"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// testEnv 使用内存缓存的测试环境
+type testEnv struct {
+	cache     cache.Cache
+	logger    log.Logger
+	protector *Protector
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	c := cache.NewTestMemory(t)
+	logger := log.NewDiscardLogger()
+	return &testEnv{cache: c, logger: logger, protector: NewProtector(c, logger)}
+}
+
+// serve 依次执行中间件和处理函数，返回响应、处理函数是否执行和错误
+func serve(r *http.Request, middlewares ...unified.MiddlewareFunc) (*httptest.ResponseRecorder, bool, error) {
+	w := httptest.NewRecorder()
+	called := false
+	handler := unified.NewChain(middlewares...).Then(func(c unified.Context) error {
+		called = true
+		return nil
+	})
+	err := handler(unified.NewStdContext(w, r))
+	return w, called, err
+}
+
+// post 创建携带Cookie和token请求头的POST请求
+func post(cookies []*http.Cookie, token string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	for _, ck := range cookies {
+		r.AddCookie(ck)
+	}
+	if token != "" {
+		r.Header.Set("X-CSRF-Token", token)
+	}
+	return r
+}
+
+// expectRejected 检查请求被拒绝且处理函数没有执行
+func expectRejected(t *testing.T, name string, called bool, err error) {
+	t.Helper()
+	if !errors.Is(err, ErrTokenInvalid) || called {
+		t.Errorf("%s: 错误 %v，处理函数执行 %v，期望 ErrTokenInvalid", name, err, called)
+	}
+}
+
+func TestDoubleSubmit(t *testing.T) {
+	mw := newTestEnv(t).protector.Middleware()
+
+	w, called, err := serve(httptest.NewRequest(http.MethodGet, "/", nil), mw)
+	if err != nil || !called {
+		t.Fatalf("GET: %v", err)
+	}
+	token := w.Header().Get("X-CSRF-Token")
+	cookies := w.Result().Cookies()
+	if !validToken(token) || len(cookies) != 1 || cookies[0].Value != token {
+		t.Fatalf("GET返回的token %q，Cookie %v", token, cookies)
+	}
+
+	// 已有token的GET请求不重新生成
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(cookies[0])
+	if w, _, _ := serve(r, mw); w.Header().Get("X-CSRF-Token") != token || len(w.Result().Cookies()) != 0 {
+		t.Errorf("已有token的GET请求重新生成了token")
+	}
+
+	if _, called, err := serve(post(cookies, token), mw); err != nil || !called {
+		t.Errorf("提交正确的token: %v", err)
+	}
+
+	_, called, err = serve(post(cookies, ""), mw)
+	expectRejected(t, "没有提交token", called, err)
+	_, called, err = serve(post(cookies, strings.Repeat("A", 43)), mw)
+	expectRejected(t, "token不匹配", called, err)
+	_, called, err = serve(post(nil, token), mw)
+	expectRejected(t, "没有Cookie", called, err)
+
+	// 表单请求从表单字段读取token，JSON请求只读取请求头
+	form := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url.Values{"_csrf": {token}}.Encode()))
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	form.AddCookie(cookies[0])
+	if _, called, err := serve(form, mw); err != nil || !called {
+		t.Errorf("表单提交token: %v", err)
+	}
+	body := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"_csrf":"`+token+`"}`))
+	body.Header.Set("Content-Type", "application/json")
+	body.AddCookie(cookies[0])
+	_, called, err = serve(body, mw)
+	expectRejected(t, "JSON请求体中的token", called, err)
+}
+
+func TestDoubleSubmitSigned(t *testing.T) {
+	if err := unified.SetCookieConfig(unified.CookieConfig{SignKey: "sign-key"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { unified.SetCookieConfig(unified.CookieConfig{}) })
+	mw := newTestEnv(t).protector.Middleware()
+
+	w, _, err := serve(httptest.NewRequest(http.MethodGet, "/", nil), mw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := w.Header().Get("X-CSRF-Token")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == token {
+		t.Fatalf("Cookie应保存签名后的token: %v", cookies)
+	}
+	if _, called, err := serve(post(cookies, token), mw); err != nil || !called {
+		t.Errorf("提交正确的token: %v", err)
+	}
+
+	// 写入未签名的Cookie并提交相同的token
+	forged := strings.Repeat("A", 43)
+	_, called, err := serve(post([]*http.Cookie{{Name: "csrf_token", Value: forged}}, forged), mw)
+	expectRejected(t, "未签名的Cookie", called, err)
+}
+
+func TestSynchronizerCache(t *testing.T) {
+	env := newTestEnv(t)
+	mw := env.protector.Middleware(Config{Mode: Synchronizer})
+
+	w, _, err := serve(httptest.NewRequest(http.MethodGet, "/", nil), mw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := w.Header().Get("X-CSRF-Token")
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == token || !cookies[0].HttpOnly {
+		t.Fatalf("Cookie应只保存缓存键: %v", cookies)
+	}
+	if stored, err := env.cache.Get(keyPrefix + cookies[0].Value); err != nil || stored != token {
+		t.Fatalf("缓存中的token %q, %v", stored, err)
+	}
+
+	if _, called, err := serve(post(cookies, token), mw); err != nil || !called {
+		t.Errorf("提交正确的token: %v", err)
+	}
+	_, called, err := serve(post(cookies, cookies[0].Value), mw)
+	expectRejected(t, "提交Cookie中的缓存键", called, err)
+
+	// 缓存中的token过期或被删除后拒绝请求
+	if _, err := env.cache.Del(keyPrefix + cookies[0].Value); err != nil {
+		t.Fatal(err)
+	}
+	_, called, err = serve(post(cookies, token), mw)
+	expectRejected(t, "token已删除", called, err)
+}
+
+func TestSynchronizerSession(t *testing.T) {
+	env := newTestEnv(t)
+	sessions := session.New(session.Options{Header: "X-Session-ID"}, env.cache, env.logger)
+	chain := []unified.MiddlewareFunc{sessions.Middleware(), env.protector.Middleware(Config{Mode: Synchronizer})}
+
+	w, _, err := serve(httptest.NewRequest(http.MethodGet, "/", nil), chain...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := w.Header().Get("X-CSRF-Token")
+	id := w.Header().Get("X-Session-ID")
+	if !validToken(token) || id == "" || len(w.Result().Cookies()) != 0 {
+		t.Fatalf("token %q，会话ID %q，Cookie %v", token, id, w.Result().Cookies())
+	}
+
+	r := post(nil, token)
+	r.Header.Set("X-Session-ID", id)
+	if _, called, err := serve(r, chain...); err != nil || !called {
+		t.Errorf("提交正确的token: %v", err)
+	}
+	_, called, err := serve(post(nil, token), chain...)
+	expectRejected(t, "没有会话", called, err)
+}
+
+func TestSkipBearer(t *testing.T) {
+	env := newTestEnv(t)
+	key, err := jwt.NewHMACKey("hs", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager, err := jwt.New(jwt.Options{Keys: []*jwt.Key{key}}, env.cache, env.logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pair, err := manager.IssuePair("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protect := env.protector.Middleware(Config{Skip: SkipBearer})
+
+	r := post(nil, "")
+	r.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+	if _, called, err := serve(r, manager.Middleware(), protect); err != nil || !called {
+		t.Errorf("jwt中间件通过请求头认证的请求: %v", err)
+	}
+
+	// 没有经过jwt认证，只携带Authorization请求头
+	r = post(nil, "")
+	r.Header.Set("Authorization", "Bearer anything")
+	_, called, err := serve(r, protect)
+	expectRejected(t, "未认证的Bearer请求头", called, err)
+}
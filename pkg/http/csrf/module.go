@@ -0,0 +1,8 @@
+package csrf
+
+import "go.uber.org/fx"
+
+// Module CSRF防护模块，提供*Protector
+var Module = fx.Options(
+	fx.Provide(NewProtector),
+)
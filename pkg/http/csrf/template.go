@@ -0,0 +1,45 @@
+package csrf
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// Token 获取当前请求的CSRF token，未使用CSRF中间件时返回空字符串
+func Token(c unified.Context) string {
+	if v, ok := c.Get(TokenKey); ok {
+		if token, ok := v.(string); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// Field 生成提交token的隐藏表单字段，放在通过Context.HTML返回的表单中
+func Field(c unified.Context) template.HTML {
+	field := "_csrf"
+	if v, ok := c.Get(fieldKey); ok {
+		field, _ = v.(string)
+	}
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+		html.EscapeString(field), html.EscapeString(Token(c))))
+}
+
+// Meta 生成包含token的meta标签，页面脚本读取后通过请求头提交
+func Meta(c unified.Context) template.HTML {
+	return template.HTML(fmt.Sprintf(`<meta name="csrf-token" content="%s">`, html.EscapeString(Token(c))))
+}
+
+// FuncMap 当前请求的模板函数，使用html/template渲染页面时传入，例如:
+//
+//	tmpl := template.Must(template.New("form").Funcs(csrf.FuncMap(c)).Parse(`<form method="post">{{ csrfField }}...</form>`))
+func FuncMap(c unified.Context) template.FuncMap {
+	return template.FuncMap{
+		"csrfToken": func() string { return Token(c) },
+		"csrfField": func() template.HTML { return Field(c) },
+		"csrfMeta":  func() template.HTML { return Meta(c) },
+	}
+}
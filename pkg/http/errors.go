@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+	"github.com/zhoudm1743/go-frame/pkg/validate"
+	"go.uber.org/fx"
+)
+
+// ErrorsGroup 错误映射所在的fx值组名称
+const ErrorsGroup = "errors"
+
+// ErrorMapping 错误映射，将特定类型的错误转换为响应
+type ErrorMapping struct {
+	match  func(err error) bool
+	handle func(c ctx.Context, err error) error
+}
+
+// OnError 为错误链中类型为T的错误注册处理函数，通过errors.As匹配，例如:
+//
+//	http.OnError(func(c unified.Context, err *OrderError) error {
+//		return response.UnifiedFailWithStatus(c, http.StatusConflict, response.Failed.Make(err.Reason), nil)
+//	})
+//
+// 处理函数返回的错误继续交给内置的映射处理
+func OnError[T error](handle func(c ctx.Context, err T) error) ErrorMapping {
+	return ErrorMapping{
+		match: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		handle: func(c ctx.Context, err error) error {
+			var target T
+			errors.As(err, &target)
+			return handle(c, target)
+		},
+	}
+}
+
+// OnErrorIs 为错误链中与target相同的错误注册处理函数，通过errors.Is匹配，例如gorm.ErrRecordNotFound
+func OnErrorIs(target error, handle func(c ctx.Context, err error) error) ErrorMapping {
+	return ErrorMapping{
+		match: func(err error) bool {
+			return errors.Is(err, target)
+		},
+		handle: handle,
+	}
+}
+
+// ProvideErrorMapping 将错误映射加入errors组，服务器启动前注册到错误处理流程中
+func ProvideErrorMapping(mapping ErrorMapping) fx.Option {
+	return fx.Supply(
+		fx.Annotate(
+			mapping,
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, ErrorsGroup)),
+		),
+	)
+}
+
+// ErrorPipeline 统一错误处理流程
+// 处理函数返回的错误先交给注册的错误映射（后注册的优先），未处理的错误按内置规则映射：
+// RespType使用对应的HTTP状态码，参数绑定、解析和校验错误返回4xx，超时返回504，上下文取消返回503，
+// 其他错误记录日志后返回SystemError
+type ErrorPipeline struct {
+	logger   log.Logger
+	mu       sync.RWMutex
+	mappings []ErrorMapping
+}
+
+// NewErrorPipeline 创建统一错误处理流程
+func NewErrorPipeline(logger log.Logger) *ErrorPipeline {
+	return &ErrorPipeline{logger: logger}
+}
+
+// Register 注册错误映射
+func (p *ErrorPipeline) Register(mappings ...ErrorMapping) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mappings = append(p.mappings, mappings...)
+}
+
+// Handle 处理错误，实现unified.ErrorHandler
+func (p *ErrorPipeline) Handle(c ctx.Context, err error) {
+	c.Error(err)
+	// 响应已经输出（例如处理函数已写入错误响应后再返回错误），只记录错误
+	if ctx.ResponseWritten(c) {
+		return
+	}
+
+	p.mu.RLock()
+	mappings := p.mappings
+	p.mu.RUnlock()
+	for i := len(mappings) - 1; i >= 0; i-- {
+		if !mappings[i].match(err) {
+			continue
+		}
+		if err = mappings[i].handle(c, err); err == nil || ctx.ResponseWritten(c) {
+			return
+		}
+		break
+	}
+
+	if err := p.render(c, err); err != nil {
+		p.logger.WithContext(c.Context()).Errorf("输出错误响应失败: %v", err)
+	}
+}
+
+// render 按内置规则输出错误响应
+func (p *ErrorPipeline) render(c ctx.Context, err error) error {
+	var (
+		resp      response.RespType
+		validErrs validator.ValidationErrors
+		bindErr   *ctx.BindError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+		fiberErr  *fiber.Error
+	)
+	switch {
+	case errors.As(err, &resp):
+		return response.UnifiedFailWithStatus(c, resp.HTTPStatus(), resp, resp.Data())
+	case errors.Is(err, context.DeadlineExceeded):
+		// 处理超时，例如Timeout中间件设置的截止时间已到或数据库调用超时
+		p.logger.WithContext(c.Context()).WithFields(map[string]interface{}{
+			"method": c.Method(),
+			"path":   c.Path(),
+			"error":  err.Error(),
+		}).Warn("请求处理超时")
+		return response.UnifiedFailWithStatus(c, http.StatusGatewayTimeout, response.RequestTimeout, nil)
+	case errors.Is(err, context.Canceled):
+		// 客户端断开连接或服务器正在关闭
+		return response.UnifiedFailWithStatus(c, http.StatusServiceUnavailable, response.ServiceUnavailable, nil)
+	case errors.As(err, &validErrs):
+		return response.UnifiedFailWithStatus(c, http.StatusBadRequest, response.ParamsValidError, validate.TranslateError(validErrs))
+	case errors.Is(err, ctx.ErrUnsupportedMediaType):
+		return response.UnifiedFailWithStatus(c, http.StatusUnsupportedMediaType, response.ParamsTypeError.Make(err.Error()), nil)
+	case errors.As(err, &maxErr):
+		return response.UnifiedFailWithStatus(c, http.StatusRequestEntityTooLarge, response.Failed.Make(http.StatusText(http.StatusRequestEntityTooLarge)), nil)
+	case errors.As(err, &bindErr):
+		return response.UnifiedFailWithStatus(c, http.StatusBadRequest, response.ParamsValidError, err.Error())
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		// 处理函数直接调用Bind时返回的请求体解析错误
+		return response.UnifiedFailWithStatus(c, http.StatusBadRequest, response.ParamsTypeError, err.Error())
+	case errors.As(err, &fiberErr):
+		// Fiber自身产生的错误，例如请求体超过大小限制
+		switch {
+		case fiberErr.Code == http.StatusNotFound:
+			return response.UnifiedFailWithStatus(c, fiberErr.Code, response.Request404Error, nil)
+		case fiberErr.Code == http.StatusMethodNotAllowed:
+			return response.UnifiedFailWithStatus(c, fiberErr.Code, response.Request405Error, nil)
+		case fiberErr.Code < http.StatusInternalServerError:
+			return response.UnifiedFailWithStatus(c, fiberErr.Code, response.Failed.Make(fiberErr.Message), nil)
+		}
+	}
+
+	// 未知错误不向客户端暴露详细信息
+	p.logger.WithContext(c.Context()).WithFields(map[string]interface{}{
+		"method": c.Method(),
+		"path":   c.Path(),
+		"ip":     c.ClientIP(),
+	}).Errorf("请求处理失败: %v", err)
+	return response.UnifiedFailWithStatus(c, http.StatusInternalServerError, response.SystemError, nil)
+}
+
+// RegisterErrorMappingsParams 错误映射注册参数
+type RegisterErrorMappingsParams struct {
+	fx.In
+	Server   Server
+	Mappings []ErrorMapping `group:"errors"`
+}
+
+// RegisterErrorMappings 将errors组中的错误映射注册到服务器
+func RegisterErrorMappings(p RegisterErrorMappingsParams) {
+	p.Server.OnError(p.Mappings...)
+}
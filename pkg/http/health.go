@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/health"
+	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// RegisterHealth 注册存活检查和就绪检查路由
+// 存活检查在进程能处理请求时总是返回200，就绪检查执行所有检查器，任一检查失败或应用正在关闭时返回503
+func RegisterHealth(server Server, h *health.Health, cfg *config.Config) {
+	router := server.Router()
+	router.GET(cfg.Health.LivenessPath, func(c ctx.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": health.StatusUp})
+	}).Doc(ctx.RouteDoc{Hidden: true})
+	router.GET(cfg.Health.ReadinessPath, func(c ctx.Context) error {
+		report := h.Check(c.Context())
+		c.SetHeader("Cache-Control", "no-store")
+		if !report.Up() {
+			return c.JSON(http.StatusServiceUnavailable, report)
+		}
+		return c.JSON(http.StatusOK, report)
+	}).Doc(ctx.RouteDoc{Hidden: true})
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/health"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"go.uber.org/fx/fxtest"
+)
+
+// 应用开始关闭时就绪检查立即失败，等待DrainDelay后才关闭服务器
+func TestReadinessDrain(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Health.LivenessPath = "/livez"
+	cfg.Health.ReadinessPath = "/readyz"
+	cfg.Health.Timeout = time.Second
+	cfg.Health.DrainDelay = 300 * time.Millisecond
+
+	logger := log.NewDiscardLogger()
+	server, err := NewUnifiedServer(&ServerConfig{Engine: "std", Addr: "127.0.0.1:0"}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := health.New(health.Params{Config: cfg})
+	RegisterHealth(server, h, cfg)
+
+	lc := fxtest.NewLifecycle(t)
+	StartUnifiedHTTPServer(lc, server, h, cfg, logger)
+	lc.RequireStart()
+
+	get := func(path string) int {
+		w := httptest.NewRecorder()
+		server.stdMux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("启动后就绪检查 %d，期望 200", code)
+	}
+
+	start := time.Now()
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- lc.Stop(context.Background())
+	}()
+	for get("/readyz") == http.StatusOK {
+		if time.Since(start) > cfg.Health.DrainDelay/2 {
+			t.Fatal("开始关闭后就绪检查没有立即失败")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if code := get("/livez"); code != http.StatusOK {
+		t.Errorf("关闭期间存活检查 %d，期望 200", code)
+	}
+
+	select {
+	case <-stopped:
+		t.Fatal("没有等待DrainDelay就关闭了服务器")
+	default:
+	}
+	if err := <-stopped; err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < cfg.Health.DrainDelay {
+		t.Errorf("关闭耗时 %s，小于DrainDelay %s", elapsed, cfg.Health.DrainDelay)
+	}
+}
@@ -0,0 +1,286 @@
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// 缓存键前缀
+const (
+	entryPrefix = "httpcache:entry:"
+	tagPrefix   = "httpcache:tag:"
+)
+
+// storedHeaders 随响应体一起缓存的响应头，其他响应头（如X-Request-ID、Set-Cookie）属于单个请求，不缓存
+var storedHeaders = []string{
+	"Content-Type",
+	"Content-Language",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Cache-Control",
+	"Expires",
+	"Vary",
+}
+
+// Rule 响应缓存规则
+type Rule struct {
+	// Name 规则名称，同一路由使用多个规则时用于区分缓存，默认为路由模板
+	Name string
+	// TTL 缓存时长，默认1分钟
+	TTL time.Duration
+	// VaryHeaders 参与缓存键的请求头，例如Accept、Accept-Language，同时写入Vary响应头
+	VaryHeaders []string
+	// IgnoreQuery 不参与缓存键的查询参数，例如utm_source
+	IgnoreQuery []string
+	// Scope 缓存范围，返回值参与缓存键，默认按用户ID、Authorization请求头、会话和Cookie区分，
+	// 没有携带凭证的请求共享缓存；响应不包含用户数据时使用Shared让所有用户共享缓存
+	Scope func(c unified.Context) string
+	// Tags 缓存标签，通过Purge按标签清除缓存
+	Tags []string
+	// TagFunc 根据请求生成缓存标签，例如按资源ID生成标签
+	TagFunc func(c unified.Context) []string
+	// Headers 额外缓存的响应头
+	Headers []string
+	// Skip 返回true的请求不使用缓存
+	Skip func(c unified.Context) bool
+}
+
+// entry 缓存的响应
+type entry struct {
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	ETag         string      `json:"etag"`
+	LastModified int64       `json:"last_modified"`
+}
+
+// ResponseCache 基于缓存的HTTP响应缓存，内存、文件和Redis缓存的行为一致
+type ResponseCache struct {
+	cache  cache.Cache
+	logger log.Logger
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(cache cache.Cache, logger log.Logger) *ResponseCache {
+	return &ResponseCache{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Middleware 按规则创建响应缓存中间件，只缓存GET请求的200响应，例如:
+//
+//	router.GET("/articles/:id", handler, responseCache.Middleware(httpcache.Rule{
+//		TTL:     5 * time.Minute,
+//		TagFunc: func(c unified.Context) []string { return []string{"article:" + c.Param("id")} },
+//	}))
+//
+// 响应带有ETag、Last-Modified和X-Cache（HIT或MISS）头，If-None-Match或If-Modified-Since匹配时返回304；
+// 带有Set-Cookie或Cache-Control: no-store/private的响应不缓存；缓存不可用时记录日志并直接执行处理函数。
+// 默认携带凭证的请求各自使用独立的缓存，不会将一个用户的响应返回给其他用户
+func (rc *ResponseCache) Middleware(rule Rule) unified.MiddlewareFunc {
+	if rule.TTL <= 0 {
+		rule.TTL = time.Minute
+	}
+	if rule.Scope == nil {
+		rule.Scope = byCredential
+	}
+	stored := append(append([]string{}, storedHeaders...), rule.Headers...)
+
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			if c.Method() != http.MethodGet || (rule.Skip != nil && rule.Skip(c)) {
+				return next(c)
+			}
+
+			key := entryKey(c, rule)
+			if e, ok := rc.load(c, key); ok {
+				return serve(c, e, "HIT")
+			}
+
+			resp, err := unified.BufferResponse(c, next)
+			if err != nil || resp == nil {
+				return err
+			}
+			if !cacheable(resp) {
+				return unified.WriteResponse(c, resp)
+			}
+
+			e := newEntry(resp, stored, rule.VaryHeaders)
+			rc.store(c, key, e, rule, tags(c, rule))
+			return serve(c, e, "MISS")
+		}
+	}
+}
+
+// Invalidate 创建在请求成功处理后按标签清除缓存的中间件，用于修改数据的路由
+func (rc *ResponseCache) Invalidate(tags ...string) unified.MiddlewareFunc {
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			if err := next(c); err != nil {
+				return err
+			}
+			if err := rc.Purge(c.Context(), tags...); err != nil {
+				rc.logger.WithContext(c.Context()).WithFields(map[string]interface{}{
+					"tags":  tags,
+					"error": err.Error(),
+				}).Warn("清除响应缓存失败")
+			}
+			return nil
+		}
+	}
+}
+
+// Purge 清除带有任一标签的缓存，在服务修改数据后调用，使用共享缓存时对所有节点生效
+func (rc *ResponseCache) Purge(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		key := tagPrefix + tag
+		keys, err := rc.cache.SMembersCtx(ctx, key)
+		if err != nil && !cache.IsMiss(err) {
+			return err
+		}
+		if _, err := rc.cache.DelCtx(ctx, append(keys, key)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load 读取缓存的响应，缓存不存在或读取失败时返回false
+func (rc *ResponseCache) load(c unified.Context, key string) (*entry, bool) {
+	data, err := rc.cache.GetCtx(c.Context(), key)
+	if err != nil {
+		if !cache.IsMiss(err) {
+			rc.logger.WithContext(c.Context()).WithError(err).Warn("读取响应缓存失败")
+		}
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		rc.logger.WithContext(c.Context()).WithError(err).Warn("响应缓存无效")
+		return nil, false
+	}
+	return &e, true
+}
+
+// store 保存响应并记录到各个标签，标签的过期时间不短于其中任一缓存
+func (rc *ResponseCache) store(c unified.Context, key string, e *entry, rule Rule, tags []string) {
+	ctx := c.Context()
+	data, err := json.Marshal(e)
+	if err != nil {
+		rc.logger.WithContext(ctx).WithError(err).Warn("保存响应缓存失败")
+		return
+	}
+	if err := rc.cache.SetCtx(ctx, key, string(data), rule.TTL); err != nil {
+		rc.logger.WithContext(ctx).WithError(err).Warn("保存响应缓存失败")
+		return
+	}
+	for _, tag := range tags {
+		tagKey := tagPrefix + tag
+		if _, err := rc.cache.SAddCtx(ctx, tagKey, key); err != nil {
+			rc.logger.WithContext(ctx).WithError(err).Warn("保存缓存标签失败")
+			continue
+		}
+		if ttl, err := rc.cache.TTLCtx(ctx, tagKey); err != nil || ttl < rule.TTL {
+			if err := rc.cache.ExpireCtx(ctx, tagKey, rule.TTL); err != nil {
+				rc.logger.WithContext(ctx).WithError(err).Warn("保存缓存标签失败")
+			}
+		}
+	}
+}
+
+// cacheable 判断响应是否可以缓存
+func cacheable(resp *unified.BufferedResponse) bool {
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Set-Cookie") != "" {
+		return false
+	}
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-store", "private":
+			return false
+		}
+	}
+	return true
+}
+
+// newEntry 从响应生成缓存，处理函数设置了ETag或Last-Modified时使用其值
+func newEntry(resp *unified.BufferedResponse, stored, vary []string) *entry {
+	e := &entry{Header: http.Header{}, Body: resp.Body}
+	for _, name := range stored {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			e.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	for _, name := range vary {
+		if !headerContains(e.Header, "Vary", name) {
+			e.Header.Add("Vary", name)
+		}
+	}
+
+	e.ETag = resp.Header.Get("ETag")
+	if e.ETag == "" {
+		sum := sha256.Sum256(resp.Body)
+		e.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	lastModified := time.Now()
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		lastModified = t
+	}
+	e.LastModified = lastModified.Unix()
+	return e
+}
+
+// serve 输出缓存的响应，条件请求匹配时返回304
+func serve(c unified.Context, e *entry, status string) error {
+	lastModified := time.Unix(e.LastModified, 0)
+	resp := &unified.BufferedResponse{
+		StatusCode: http.StatusOK,
+		Header:     e.Header.Clone(),
+		Body:       e.Body,
+	}
+	resp.Header.Set("ETag", e.ETag)
+	resp.Header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	resp.Header.Set("X-Cache", status)
+	if notModified(c, e.ETag, lastModified) {
+		resp.StatusCode = http.StatusNotModified
+		resp.Body = nil
+	}
+	return unified.WriteResponse(c, resp)
+}
+
+// notModified 判断条件请求是否匹配，If-None-Match优先于If-Modified-Since
+func notModified(c unified.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// headerContains 判断以逗号分隔的响应头中是否包含指定的值
+func headerContains(header http.Header, key, value string) bool {
+	for _, v := range header.Values(key) {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package httpcache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// profile 返回请求凭证的处理函数，模拟包含用户数据的响应
+func profile(c unified.Context) error {
+	return c.String(http.StatusOK, "profile of %s%s", c.GetHeader("Authorization"), c.GetHeader("Cookie"))
+}
+
+// get 使用中间件处理GET请求，header依次为请求头名称和值
+func get(t *testing.T, mw unified.MiddlewareFunc, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/me", nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	if err := unified.NewChain(mw).Then(profile)(unified.NewStdContext(w, r)); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestScopeByCredential(t *testing.T) {
+	mw := NewResponseCache(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+
+	alice := get(t, mw, "Authorization", "Bearer alice")
+	bob := get(t, mw, "Authorization", "Bearer bob")
+	if bob.Header().Get("X-Cache") != "MISS" || bob.Body.String() == alice.Body.String() {
+		t.Errorf("不同的Bearer共享了缓存: %s %q", bob.Header().Get("X-Cache"), bob.Body.String())
+	}
+	if again := get(t, mw, "Authorization", "Bearer alice"); again.Header().Get("X-Cache") != "HIT" || again.Body.String() != alice.Body.String() {
+		t.Errorf("相同的Bearer: %s %q", again.Header().Get("X-Cache"), again.Body.String())
+	}
+
+	// Cookie和没有凭证的请求
+	if w := get(t, mw, "Cookie", "session_id=a"); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("携带Cookie的请求使用了其他用户的缓存")
+	}
+	get(t, mw)
+	if w := get(t, mw); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "profile of " {
+		t.Errorf("没有凭证的请求: %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+}
+
+func TestScopeByUser(t *testing.T) {
+	mw := NewResponseCache(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+	as := func(id string) unified.MiddlewareFunc {
+		return func(next unified.HandlerFunc) unified.HandlerFunc {
+			return func(c unified.Context) error {
+				c.Set(unified.UserIDKey, id)
+				return next(c)
+			}
+		}
+	}
+
+	// 认证中间件保存的用户ID优先于请求头，同一用户的不同token共享缓存
+	get(t, unified.Compose(as("1"), mw), "Authorization", "Bearer old")
+	if w := get(t, unified.Compose(as("1"), mw), "Authorization", "Bearer new"); w.Header().Get("X-Cache") != "HIT" {
+		t.Error("同一用户的不同token没有命中缓存")
+	}
+	if w := get(t, unified.Compose(as("2"), mw), "Authorization", "Bearer old"); w.Header().Get("X-Cache") != "MISS" {
+		t.Error("其他用户使用了缓存")
+	}
+}
+
+func TestScopeShared(t *testing.T) {
+	mw := NewResponseCache(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{Scope: Shared})
+
+	first := get(t, mw, "Authorization", "Bearer alice")
+	if w := get(t, mw, "Cookie", "session_id=b"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != first.Body.String() {
+		t.Errorf("Shared范围: %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+}
@@ -0,0 +1,90 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// 保存请求中添加的缓存标签的上下文数据键
+const tagsKey = "_httpcache_tags"
+
+// Tag 为当前请求的缓存添加标签，在处理函数中调用，例如:
+//
+//	httpcache.Tag(c, "article:"+strconv.Itoa(article.ID), "author:"+strconv.Itoa(article.AuthorID))
+func Tag(c unified.Context, tags ...string) {
+	var current []string
+	if v, ok := c.Get(tagsKey); ok {
+		current, _ = v.([]string)
+	}
+	for _, tag := range tags {
+		// Fiber的请求参数引用请求缓冲区，复制后才能在请求结束后使用
+		current = append(current, strings.Clone(tag))
+	}
+	c.Set(tagsKey, current)
+}
+
+// tags 获取规则和请求中的所有缓存标签
+func tags(c unified.Context, rule Rule) []string {
+	result := append([]string{}, rule.Tags...)
+	if rule.TagFunc != nil {
+		for _, tag := range rule.TagFunc(c) {
+			result = append(result, strings.Clone(tag))
+		}
+	}
+	if v, ok := c.Get(tagsKey); ok {
+		if added, ok := v.([]string); ok {
+			result = append(result, added...)
+		}
+	}
+	return result
+}
+
+// Shared 所有用户共享缓存的范围，只用于不包含用户数据的响应
+func Shared(c unified.Context) string {
+	return ""
+}
+
+// byCredential 默认的缓存范围，认证中间件保存了用户ID时按用户区分，
+// 否则按Authorization请求头、会话ID和Cookie区分，缓存键经过摘要，凭证不会出现在键中
+func byCredential(c unified.Context) string {
+	if v, ok := c.Get(unified.UserIDKey); ok && v != nil {
+		return "user:" + fmt.Sprint(v)
+	}
+	var parts []string
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		parts = append(parts, "authorization:"+auth)
+	}
+	if id := c.Session().ID(); id != "" {
+		parts = append(parts, "session:"+id)
+	}
+	if cookie := c.GetHeader("Cookie"); cookie != "" {
+		parts = append(parts, "cookie:"+cookie)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// entryKey 生成缓存键，由规则名称、请求路径、规范化的查询参数、VaryHeaders请求头和缓存范围组成
+// 查询参数按名称排序并去除IgnoreQuery中的参数，参数顺序不同的请求共享缓存
+func entryKey(c unified.Context, rule Rule) string {
+	name := rule.Name
+	if name == "" {
+		name = c.Path()
+	}
+
+	query := c.URL().Query()
+	for _, ignored := range rule.IgnoreQuery {
+		query.Del(ignored)
+	}
+	parts := []string{c.URL().Path, query.Encode()}
+	for _, header := range rule.VaryHeaders {
+		parts = append(parts, strings.ToLower(header)+"="+c.GetHeader(header))
+	}
+	parts = append(parts, rule.Scope(c))
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return entryPrefix + name + ":" + hex.EncodeToString(sum[:])
+}
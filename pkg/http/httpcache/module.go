@@ -0,0 +1,8 @@
+package httpcache
+
+import "go.uber.org/fx"
+
+// Module 响应缓存模块，提供*ResponseCache
+var Module = fx.Options(
+	fx.Provide(NewResponseCache),
+)
@@ -0,0 +1,272 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// 缓存键前缀
+const (
+	entryPrefix = "idempotency:"
+	lockPrefix  = "idempotency"
+)
+
+// maxKeyLength 接受的幂等键的最大长度
+const maxKeyLength = 255
+
+// ReplayedHeader 重放的响应带有该响应头
+const ReplayedHeader = "Idempotent-Replayed"
+
+// storedHeaders 随响应体一起保存的响应头，其他响应头（如X-Request-ID、Set-Cookie、跨域响应头）属于单个请求，不保存
+var storedHeaders = []string{
+	"Content-Type",
+	"Content-Language",
+	"Content-Disposition",
+	"Location",
+	"ETag",
+	"Last-Modified",
+	"Cache-Control",
+}
+
+// ErrKeyInvalid 幂等键过长
+var ErrKeyInvalid = response.ParamsValidError.Make(fmt.Sprintf("幂等键长度不能超过%d", maxKeyLength))
+
+// ErrKeyRequired 要求幂等键的路由没有携带幂等键
+var ErrKeyRequired = response.ParamsValidError.Make("缺少幂等键")
+
+// Rule 幂等规则
+type Rule struct {
+	// Header 读取幂等键的请求头，默认Idempotency-Key
+	Header string
+	// TTL 响应的保存时长，在此期间相同幂等键的请求都返回第一次的响应，默认24小时
+	TTL time.Duration
+	// LockTTL 处理请求期间锁的过期时间，应大于处理函数的最长执行时间，默认1分钟
+	LockTTL time.Duration
+	// Required 为true时没有幂等键的请求返回ErrKeyRequired，否则直接执行处理函数
+	Required bool
+	// Scope 幂等键的范围，返回值参与缓存键，默认使用上下文数据user_id，不同用户的相同幂等键互不影响
+	Scope func(c unified.Context) string
+	// Headers 额外保存的响应头
+	Headers []string
+	// Skip 返回true的请求不处理幂等键
+	Skip func(c unified.Context) bool
+}
+
+// entry 保存的响应
+type entry struct {
+	Fingerprint string      `json:"fingerprint"`
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// Manager 基于缓存的幂等请求处理，幂等键和保存的响应保存在缓存中
+type Manager struct {
+	cache  cache.Cache
+	helper *cache.CacheHelper
+	logger log.Logger
+}
+
+// NewManager 创建幂等请求处理
+func NewManager(c cache.Cache, logger log.Logger) *Manager {
+	return &Manager{
+		cache:  c,
+		helper: cache.NewCacheHelper(c, logger, lockPrefix),
+		logger: logger,
+	}
+}
+
+// Middleware 按规则创建幂等中间件，用于创建订单、支付等客户端可能重试的POST、PUT、PATCH和DELETE路由，例如:
+//
+//	router.POST("/orders", handler, idempotencyManager.Middleware(idempotency.Rule{Required: true}))
+//
+// 第一次请求执行时持有锁，处理完成后保存状态码、响应头和响应体，相同幂等键的请求直接返回保存的响应并带有Idempotent-Replayed头；
+// 请求方法、路径、查询参数或请求体不同时返回response.IdempotencyKeyConflict，第一次请求仍在处理时返回response.IdempotencyKeyInProgress；
+// 处理函数返回错误或状态码为5xx时不保存响应，客户端可以使用相同的幂等键重试；缓存不可用时返回response.ServiceUnavailable
+func (m *Manager) Middleware(rule Rule) unified.MiddlewareFunc {
+	if rule.Header == "" {
+		rule.Header = "Idempotency-Key"
+	}
+	if rule.TTL <= 0 {
+		rule.TTL = 24 * time.Hour
+	}
+	if rule.LockTTL <= 0 {
+		rule.LockTTL = time.Minute
+	}
+	if rule.Scope == nil {
+		rule.Scope = byUser
+	}
+	stored := append(append([]string{}, storedHeaders...), rule.Headers...)
+
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			if !unsafeMethod(c.Method()) || (rule.Skip != nil && rule.Skip(c)) {
+				return next(c)
+			}
+			idempotencyKey := c.GetHeader(rule.Header)
+			if idempotencyKey == "" {
+				if rule.Required {
+					return ErrKeyRequired
+				}
+				return next(c)
+			}
+			if len(idempotencyKey) > maxKeyLength {
+				return ErrKeyInvalid
+			}
+
+			fingerprint, err := requestFingerprint(c)
+			if err != nil {
+				return err
+			}
+			id := keyID(rule.Scope(c), idempotencyKey)
+			key := entryPrefix + id
+
+			if e, err := m.load(c, key); err != nil {
+				return response.ServiceUnavailable
+			} else if e != nil {
+				return replay(c, e, fingerprint)
+			}
+
+			token, err := m.helper.TryLockCtx(c.Context(), id, rule.LockTTL)
+			if err != nil {
+				m.logger.WithContext(c.Context()).WithError(err).Warn("获取幂等锁失败")
+				return response.ServiceUnavailable
+			}
+			if token == "" {
+				return response.IdempotencyKeyInProgress
+			}
+			defer func() {
+				// 超时中间件可能已经取消了请求上下文，释放锁不能受其影响
+				ctx := context.WithoutCancel(c.Context())
+				if err := m.helper.ReleaseCtx(ctx, id, token); err != nil {
+					m.logger.WithContext(ctx).WithError(err).Warn("释放幂等锁失败")
+				}
+			}()
+
+			// 获取锁之前第一次请求可能刚好完成
+			if e, err := m.load(c, key); err != nil {
+				return response.ServiceUnavailable
+			} else if e != nil {
+				return replay(c, e, fingerprint)
+			}
+
+			resp, err := unified.BufferResponse(c, next)
+			if err != nil || resp == nil {
+				return err
+			}
+			if resp.StatusCode < http.StatusInternalServerError {
+				m.store(c, key, newEntry(resp, fingerprint, stored), rule.TTL)
+			}
+			return unified.WriteResponse(c, resp)
+		}
+	}
+}
+
+// load 读取保存的响应，不存在时返回nil
+func (m *Manager) load(c unified.Context, key string) (*entry, error) {
+	data, err := m.cache.GetCtx(c.Context(), key)
+	if err != nil {
+		if cache.IsMiss(err) {
+			return nil, nil
+		}
+		m.logger.WithContext(c.Context()).WithError(err).Warn("读取幂等响应失败")
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		m.logger.WithContext(c.Context()).WithError(err).Warn("幂等响应无效")
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// store 保存响应，失败时只记录日志，当前请求的响应正常返回
+func (m *Manager) store(c unified.Context, key string, e *entry, ttl time.Duration) {
+	data, err := json.Marshal(e)
+	if err == nil {
+		err = m.cache.SetCtx(c.Context(), key, string(data), ttl)
+	}
+	if err != nil {
+		m.logger.WithContext(c.Context()).WithError(err).Warn("保存幂等响应失败")
+	}
+}
+
+// replay 返回保存的响应，请求与第一次请求不同时返回response.IdempotencyKeyConflict
+func replay(c unified.Context, e *entry, fingerprint string) error {
+	if e.Fingerprint != fingerprint {
+		return response.IdempotencyKeyConflict
+	}
+	resp := &unified.BufferedResponse{
+		StatusCode: e.StatusCode,
+		Header:     e.Header.Clone(),
+		Body:       e.Body,
+	}
+	resp.Header.Set(ReplayedHeader, "true")
+	return unified.WriteResponse(c, resp)
+}
+
+// newEntry 从响应生成保存的响应
+func newEntry(resp *unified.BufferedResponse, fingerprint string, stored []string) *entry {
+	e := &entry{
+		Fingerprint: fingerprint,
+		StatusCode:  resp.StatusCode,
+		Header:      http.Header{},
+		Body:        resp.Body,
+	}
+	for _, name := range stored {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			e.Header[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return e
+}
+
+// requestFingerprint 由请求方法、路径、查询参数和请求体生成请求指纹，读取后恢复请求体供处理函数使用
+func requestFingerprint(c unified.Context) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + "\n" + c.URL().RequestURI() + "\n"))
+	if r := c.GetRequest(); r != nil && r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// keyID 生成保存响应和加锁使用的键，幂等键由客户端生成，使用摘要避免特殊字符
+func keyID(scope, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(scope + "\n" + idempotencyKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// byUser 默认的幂等键范围，使用认证中间件保存的用户ID
+func byUser(c unified.Context) string {
+	if v, ok := c.Get(unified.UserIDKey); ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// unsafeMethod 判断是否是修改数据的请求方法
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
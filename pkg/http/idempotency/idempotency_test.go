@@ -0,0 +1,180 @@
+package idempotency
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// serve 使用中间件处理请求，返回响应和错误
+func serve(mw unified.MiddlewareFunc, r *http.Request, handler unified.HandlerFunc) (*httptest.ResponseRecorder, error) {
+	w := httptest.NewRecorder()
+	err := unified.NewChain(mw).Then(handler)(unified.NewStdContext(w, r))
+	return w, err
+}
+
+// order 携带幂等键的创建订单请求
+func order(key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	r.Header.Set("Idempotency-Key", key)
+	return r
+}
+
+// counter 记录执行次数并返回201的处理函数
+func counter(calls *int32) unified.HandlerFunc {
+	return func(c unified.Context) error {
+		n := atomic.AddInt32(calls, 1)
+		body, _ := io.ReadAll(c.GetRequest().Body)
+		c.SetHeader("Location", "/orders/1")
+		c.SetHeader("X-Request-ID", "first")
+		return c.String(http.StatusCreated, "%d:%s", n, body)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	mw := NewManager(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+	var calls int32
+
+	first, err := serve(mw, order("k1", `{"sku":1}`), counter(&calls))
+	if err != nil || first.Code != http.StatusCreated {
+		t.Fatalf("第一次请求: %d, %v", first.Code, err)
+	}
+	second, err := serve(mw, order("k1", `{"sku":1}`), counter(&calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("处理函数执行了 %d 次，期望 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("重放的响应 %d %q，期望 %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(ReplayedHeader) != "true" || second.Header().Get("Location") != "/orders/1" {
+		t.Errorf("重放的响应头 %v", second.Header())
+	}
+	if second.Header().Get("X-Request-ID") != "" {
+		t.Error("重放了属于单个请求的响应头")
+	}
+
+	// 没有幂等键和安全方法的请求不处理
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	if _, err := serve(mw, r, counter(&calls)); err != nil || calls != 2 {
+		t.Errorf("没有幂等键的请求: %v，执行 %d 次", err, calls)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("Idempotency-Key", "k1")
+	if _, err := serve(mw, r, counter(&calls)); err != nil || calls != 3 {
+		t.Errorf("GET请求: %v，执行 %d 次", err, calls)
+	}
+}
+
+func TestConflict(t *testing.T) {
+	mw := NewManager(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+	var calls int32
+
+	if _, err := serve(mw, order("k1", `{"sku":1}`), counter(&calls)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := serve(mw, order("k1", `{"sku":2}`), counter(&calls)); !errors.Is(err, response.IdempotencyKeyConflict) {
+		t.Errorf("请求体不同: 错误 %v，期望 IdempotencyKeyConflict", err)
+	}
+	r := order("k1", `{"sku":1}`)
+	r.URL.RawQuery = "coupon=1"
+	if _, err := serve(mw, r, counter(&calls)); !errors.Is(err, response.IdempotencyKeyConflict) {
+		t.Errorf("查询参数不同: 错误 %v，期望 IdempotencyKeyConflict", err)
+	}
+	if calls != 1 {
+		t.Errorf("处理函数执行了 %d 次，期望 1", calls)
+	}
+
+	// 不同用户的相同幂等键互不影响
+	r = order("k1", `{"sku":2}`)
+	other := func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			c.Set(unified.UserIDKey, "2")
+			return next(c)
+		}
+	}
+	if _, err := serve(unified.Compose(other, mw), r, counter(&calls)); err != nil || calls != 2 {
+		t.Errorf("其他用户的相同幂等键: %v，执行 %d 次", err, calls)
+	}
+}
+
+func TestInProgress(t *testing.T) {
+	mw := NewManager(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+
+	go func() {
+		_, err := serve(mw, order("k1", "{}"), func(c unified.Context) error {
+			close(started)
+			<-release
+			return c.String(http.StatusCreated, "ok")
+		})
+		done <- err
+	}()
+	<-started
+
+	if _, err := serve(mw, order("k1", "{}"), counter(new(int32))); !errors.Is(err, response.IdempotencyKeyInProgress) {
+		t.Errorf("第一次请求处理中: 错误 %v，期望 IdempotencyKeyInProgress", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// 第一次请求完成后返回保存的响应
+	w, err := serve(mw, order("k1", "{}"), counter(new(int32)))
+	if err != nil || w.Body.String() != "ok" {
+		t.Errorf("第一次请求完成后: %q, %v", w.Body.String(), err)
+	}
+}
+
+func TestServerErrorNotStored(t *testing.T) {
+	mw := NewManager(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{})
+	var calls int32
+	fail := func(c unified.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return c.String(http.StatusBadGateway, "upstream")
+	}
+
+	for i := 0; i < 2; i++ {
+		w, err := serve(mw, order("k1", "{}"), fail)
+		if err != nil || w.Code != http.StatusBadGateway {
+			t.Fatalf("第%d次请求: %d, %v", i+1, w.Code, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("5xx响应被保存，处理函数执行了 %d 次，期望 2", calls)
+	}
+
+	// 处理函数返回错误时同样不保存，重试使用相同的幂等键
+	errFailed := errors.New("failed")
+	if _, err := serve(mw, order("k2", "{}"), func(c unified.Context) error { return errFailed }); !errors.Is(err, errFailed) {
+		t.Fatalf("处理函数的错误 %v", err)
+	}
+	if w, err := serve(mw, order("k2", "{}"), counter(&calls)); err != nil || w.Code != http.StatusCreated {
+		t.Errorf("出错后重试: %d, %v", w.Code, err)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	mw := NewManager(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{Required: true})
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	if _, err := serve(mw, r, counter(new(int32))); !errors.Is(err, ErrKeyRequired) {
+		t.Errorf("没有幂等键: 错误 %v，期望 ErrKeyRequired", err)
+	}
+	if _, err := serve(mw, order(strings.Repeat("k", maxKeyLength+1), "{}"), counter(new(int32))); !errors.Is(err, ErrKeyInvalid) {
+		t.Errorf("幂等键过长: 错误 %v，期望 ErrKeyInvalid", err)
+	}
+}
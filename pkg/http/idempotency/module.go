@@ -0,0 +1,8 @@
+package idempotency
+
+import "go.uber.org/fx"
+
+// Module 幂等请求模块，提供*Manager
+var Module = fx.Options(
+	fx.Provide(NewManager),
+)
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/metrics"
+)
+
+// RegisterMetrics 注册指标路由和HTTP指标中间件，需要在挂载业务路由之前执行，之后注册的路由才会记录指标
+func RegisterMetrics(server Server, registry *metrics.Registry, httpMetrics *metrics.HTTPMetrics, cfg *config.Config) {
+	if !cfg.Metrics.Enable {
+		return
+	}
+	// 指标路由在中间件之前注册，抓取请求不计入HTTP指标
+	server.Router().GET(cfg.Metrics.Path, registry.Handler()).Doc(ctx.RouteDoc{Hidden: true})
+	server.Use(httpMetrics.Middleware())
+}
@@ -83,16 +83,17 @@ func FiberLogrusLogger(logger log.Logger) fiber.Handler {
 			"path":      fullPath,
 		}
 
-		// 使用不同级别记录日志
+		// 使用不同级别记录日志，请求上下文中的请求ID会加入日志字段
+		entry := logger.WithContext(c.UserContext()).WithFields(fields)
 		switch {
 		case statusCode >= 500:
-			logger.WithFields(fields).Error(msg)
+			entry.Error(msg)
 		case statusCode >= 400:
-			logger.WithFields(fields).Warn(msg)
+			entry.Warn(msg)
 		case statusCode >= 300:
-			logger.WithFields(fields).Info(msg)
+			entry.Info(msg)
 		default:
-			logger.WithFields(fields).Info(msg)
+			entry.Info(msg)
 		}
 
 		return err
@@ -44,14 +44,15 @@ func Logger(logger log.Logger) gin.HandlerFunc {
 			reqURI,
 		)
 
-		// 根据状态码确定日志级别
+		// 根据状态码确定日志级别，请求上下文中的请求ID会加入日志字段
+		entry := logger.WithContext(c.Request.Context())
 		switch {
 		case statusCode >= 500:
-			logger.Errorf(logMsg)
+			entry.Error(logMsg)
 		case statusCode >= 400:
-			logger.Warnf(logMsg)
+			entry.Warn(logMsg)
 		default:
-			logger.Infof(logMsg)
+			entry.Info(logMsg)
 		}
 	}
 }
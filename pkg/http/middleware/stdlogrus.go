@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// statusRecorder 记录标准库响应的状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader 记录状态码
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write 实现io.Writer接口
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// Flush 实现http.Flusher接口
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现http.Hijacker接口，连接被接管（如WebSocket升级）时记录101状态码
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap 返回原始的ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// StdLogrusLogger 使用logrus作为标准库net/http的日志输出
+func StdLogrusLogger(logger log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 开始时间
+			startTime := time.Now()
+
+			// 请求路径
+			path := r.URL.Path
+
+			// 获取原始查询参数
+			query := r.URL.RawQuery
+
+			// 处理请求
+			recorder := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+
+			// 执行时间
+			latency := time.Since(startTime)
+
+			// 如果执行时间大于1秒，则改用秒为单位显示
+			if latency > time.Second {
+				latency = latency.Round(time.Second / 10)
+			}
+
+			// 状态码
+			statusCode := recorder.status
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+
+			// 请求方法
+			method := r.Method
+
+			// 构建彩色日志消息
+			statusColor := getStatusColor(statusCode)
+			methodColor := getMethodColor(method)
+
+			// 构建状态码和方法的彩色输出
+			coloredStatus := fmt.Sprintf("%s%3d%s", statusColor, statusCode, resetColor)
+			coloredMethod := fmt.Sprintf("%s%s%s", methodColor, method, resetColor)
+
+			// 构建URL路径（包含查询参数）
+			fullPath := path
+			if query != "" {
+				fullPath = path + "?" + query
+			}
+
+			// 构建完整日志消息
+			msg := fmt.Sprintf("[STD] %s | %s | %12v | %s",
+				coloredStatus,
+				coloredMethod,
+				latency,
+				fullPath,
+			)
+
+			// 构建日志字段
+			fields := logrus.Fields{
+				"status":    statusCode,
+				"method":    method,
+				"latency":   latency,
+				"client_ip": r.RemoteAddr,
+				"path":      fullPath,
+			}
+
+			// 使用不同级别记录日志，请求上下文中的请求ID会加入日志字段
+			entry := logger.WithContext(r.Context()).WithFields(fields)
+			switch {
+			case statusCode >= 500:
+				entry.Error(msg)
+			case statusCode >= 400:
+				entry.Warn(msg)
+			default:
+				entry.Info(msg)
+			}
+		})
+	}
+}
+
+// StdRecovery 标准库net/http的恢复中间件，捕获panic并返回500
+func StdRecovery(logger log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if err := recover(); err != nil {
+					// http.ErrAbortHandler 用于主动中断请求，直接向上抛出
+					if err == http.ErrAbortHandler {
+						panic(err)
+					}
+					logger.WithContext(r.Context()).WithFields(logrus.Fields{
+						"method": r.Method,
+						"path":   r.URL.Path,
+						"panic":  err,
+					}).Errorf("请求处理发生panic: %v\n%s", err, debug.Stack())
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,12 +2,34 @@ package http
 
 import (
 	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/health"
+	"github.com/zhoudm1743/go-frame/pkg/http/csrf"
+	"github.com/zhoudm1743/go-frame/pkg/http/httpcache"
+	"github.com/zhoudm1743/go-frame/pkg/http/idempotency"
+	"github.com/zhoudm1743/go-frame/pkg/http/ratelimit"
+	"github.com/zhoudm1743/go-frame/pkg/http/session"
+	"github.com/zhoudm1743/go-frame/pkg/metrics"
+	"github.com/zhoudm1743/go-frame/pkg/tracing"
 	"go.uber.org/fx"
 )
 
 // UnifiedModule 提供统一的HTTP模块
 var UnifiedModule = fx.Options(
+	ratelimit.Module,
+	httpcache.Module,
+	session.Module,
+	csrf.Module,
+	idempotency.Module,
+	health.Module,
+	metrics.Module,
+	tracing.Module,
 	fx.Provide(NewUnifiedHTTPServer),
+	fx.Invoke(RegisterMetrics),
+	fx.Invoke(RegisterTracing),
+	fx.Invoke(MountRoutes),
+	fx.Invoke(RegisterErrorMappings),
+	fx.Invoke(RegisterOpenAPI),
+	fx.Invoke(RegisterHealth),
 	fx.Invoke(StartUnifiedHTTPServer),
 )
 
@@ -21,7 +43,21 @@ func ConfigurableModule(configFunc func(*config.Config)) fx.Option {
 			}
 		}),
 		// 再创建服务器
+		ratelimit.Module,
+		httpcache.Module,
+		session.Module,
+		csrf.Module,
+		idempotency.Module,
+		health.Module,
+		metrics.Module,
+		tracing.Module,
 		fx.Provide(NewUnifiedHTTPServer),
+		fx.Invoke(RegisterMetrics),
+		fx.Invoke(RegisterTracing),
+		fx.Invoke(MountRoutes),
+		fx.Invoke(RegisterErrorMappings),
+		fx.Invoke(RegisterOpenAPI),
+		fx.Invoke(RegisterHealth),
 		fx.Invoke(StartUnifiedHTTPServer),
 	)
 }
@@ -38,3 +74,6 @@ var GinEngine = WithEngine("gin")
 
 // FiberEngine 使用Fiber引擎
 var FiberEngine = WithEngine("fiber")
+
+// StdEngine 使用标准库net/http引擎
+var StdEngine = WithEngine("std")
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/http/openapi"
+	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// OpenAPIInfo 根据配置生成文档基本信息
+func OpenAPIInfo(cfg *config.Config) openapi.Info {
+	info := openapi.Info{
+		Title:       cfg.HTTP.OpenAPI.Title,
+		Description: cfg.HTTP.OpenAPI.Description,
+		Version:     cfg.HTTP.OpenAPI.Version,
+	}
+	if info.Title == "" {
+		info.Title = cfg.App.Name
+	}
+	if info.Version == "" {
+		info.Version = cfg.App.Version
+	}
+	return info
+}
+
+// OpenAPIDocument 根据服务器已注册的路由生成OpenAPI文档
+func OpenAPIDocument(server Server, cfg *config.Config) *openapi.Document {
+	return openapi.Generate(server.Routes(), OpenAPIInfo(cfg))
+}
+
+// RegisterOpenAPI 在配置的路径上提供OpenAPI文档
+func RegisterOpenAPI(server Server, cfg *config.Config) {
+	if !cfg.HTTP.OpenAPI.Enable {
+		return
+	}
+	server.Router().
+		GET(cfg.HTTP.OpenAPI.Path, openapi.Handler(server.Routes, OpenAPIInfo(cfg))).
+		Doc(ctx.RouteDoc{Hidden: true})
+}
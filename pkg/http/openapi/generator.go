@@ -0,0 +1,296 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+var (
+	pathParamPattern = regexp.MustCompile(`[:*]([^/]+)`)
+	closurePattern   = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+	operationChar    = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// Generate 根据路由表生成OpenAPI文档
+// 静态文件路由和设置了Hidden的路由不会出现在文档中
+func Generate(routes []unified.RouteInfo, info Info, servers ...Server) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Servers: servers,
+		Paths:   make(map[string]*PathItem),
+	}
+
+	registry := newSchemaRegistry()
+	operationIDs := make(map[string]bool)
+	tags := make(map[string]bool)
+
+	for _, route := range routes {
+		if strings.HasPrefix(route.Handler, "static:") {
+			continue
+		}
+		routeDoc := unified.RouteDoc{}
+		if route.Doc != nil {
+			routeDoc = *route.Doc
+		}
+		if routeDoc.Hidden {
+			continue
+		}
+
+		op := buildOperation(registry, route, routeDoc)
+
+		// 保证operationId唯一，重复时使用方法和路径生成
+		if op.OperationID == "" || operationIDs[op.OperationID] {
+			op.OperationID = fallbackOperationID(route.Method, route.Path)
+		}
+		operationIDs[op.OperationID] = true
+
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+
+		path := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = op
+	}
+
+	names := make([]string, 0, len(tags))
+	for tag := range tags {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+
+	if schemas := registry.components(); len(schemas) > 0 {
+		doc.Components = &Components{Schemas: schemas}
+	}
+	return doc
+}
+
+// buildOperation 生成单个接口操作
+func buildOperation(registry *schemaRegistry, route unified.RouteInfo, routeDoc unified.RouteDoc) *Operation {
+	op := &Operation{
+		Tags:        routeDoc.Tags,
+		Summary:     routeDoc.Summary,
+		Description: routeDoc.Description,
+		OperationID: routeDoc.OperationID,
+		Deprecated:  routeDoc.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+	if len(op.Tags) == 0 {
+		if tag := groupTag(route.Group); tag != "" {
+			op.Tags = []string{tag}
+		}
+	}
+	if op.OperationID == "" {
+		op.OperationID = handlerOperationID(route.Handler)
+	}
+
+	var reqType reflect.Type
+	if routeDoc.Request != nil {
+		reqType = indirectType(reflect.TypeOf(routeDoc.Request))
+	}
+
+	op.Parameters = append(op.Parameters, pathParameters(registry, route.Path, reqType)...)
+	if reqType != nil && reqType.Kind() == reflect.Struct {
+		op.Parameters = append(op.Parameters, fieldParameters(registry, reqType, "header", "header")...)
+
+		switch route.Method {
+		case "GET", "HEAD", "DELETE", "OPTIONS":
+			op.Parameters = append(op.Parameters, fieldParameters(registry, reqType, "form", "query")...)
+		default:
+			op.RequestBody = requestBody(registry, reqType)
+			if op.RequestBody != nil && hasTag(reqType, "json") {
+				// JSON请求体之外仅声明了form标签的字段从查询参数读取
+				op.Parameters = append(op.Parameters, queryOnlyParameters(registry, reqType)...)
+			}
+		}
+	}
+
+	var dataSchema *Schema
+	if routeDoc.Response != nil {
+		dataSchema = registry.schemaOf(reflect.TypeOf(routeDoc.Response))
+	}
+	op.Responses["200"] = &Response{
+		Description: "成功",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: envelopeSchema(dataSchema)},
+		},
+	}
+	return op
+}
+
+// pathParameters 生成路径参数，类型取自请求结构体的uri标签，未声明时为字符串
+func pathParameters(registry *schemaRegistry, path string, reqType reflect.Type) []*Parameter {
+	uriFields := make(map[string]reflect.StructField)
+	if reqType != nil {
+		for _, f := range structFields(reqType, "uri") {
+			uriFields[f.name] = f.field
+		}
+	}
+
+	var params []*Parameter
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		param := &Parameter{Name: match[1], In: "path", Required: true}
+		if f, ok := uriFields[match[1]]; ok {
+			param.Schema = registry.schemaOf(f.Type)
+			applyRules(param.Schema, f)
+			param.Description = fieldDescription(f)
+		} else {
+			param.Schema = &Schema{Type: "string"}
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// fieldParameters 根据指定标签生成参数
+func fieldParameters(registry *schemaRegistry, reqType reflect.Type, tag, in string) []*Parameter {
+	var params []*Parameter
+	for _, f := range structFields(reqType, tag) {
+		params = append(params, newParameter(registry, f, in))
+	}
+	return params
+}
+
+// queryOnlyParameters 生成只声明了form标签而没有json标签的查询参数
+func queryOnlyParameters(registry *schemaRegistry, reqType reflect.Type) []*Parameter {
+	var params []*Parameter
+	for _, f := range structFields(reqType, "form") {
+		if _, ok := f.field.Tag.Lookup("json"); ok {
+			continue
+		}
+		if _, ok := f.field.Tag.Lookup("uri"); ok {
+			continue
+		}
+		params = append(params, newParameter(registry, f, "query"))
+	}
+	return params
+}
+
+// newParameter 根据字段创建参数
+func newParameter(registry *schemaRegistry, f namedField, in string) *Parameter {
+	schema := registry.schemaOf(f.field.Type)
+	required := applyRules(schema, f.field)
+	return &Parameter{
+		Name:        f.name,
+		In:          in,
+		Description: fieldDescription(f.field),
+		Required:    required,
+		Schema:      schema,
+	}
+}
+
+// requestBody 生成请求体，存在json标签时使用JSON，否则根据form标签使用表单
+func requestBody(registry *schemaRegistry, reqType reflect.Type) *RequestBody {
+	if hasTag(reqType, "json") {
+		return &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				"application/json": {Schema: registry.schemaOf(reqType)},
+			},
+		}
+	}
+
+	if !hasTag(reqType, "form") {
+		return nil
+	}
+	contentType := "application/x-www-form-urlencoded"
+	for _, f := range structFields(reqType, "form") {
+		if isFileType(f.field.Type) {
+			contentType = "multipart/form-data"
+			break
+		}
+	}
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			contentType: {Schema: registry.structSchema(reqType, "form")},
+		},
+	}
+}
+
+// envelopeSchema 生成统一响应结构的Schema
+func envelopeSchema(data *Schema) *Schema {
+	if data == nil {
+		data = &Schema{}
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "integer", Description: "业务状态码", Example: 200},
+			"message": {Type: "string", Description: "提示信息", Example: "成功"},
+			"data":    data,
+		},
+		Required: []string{"code", "message", "data"},
+	}
+}
+
+// convertPath 将":id"和"*filepath"形式的路径参数转换为"{id}"和"{filepath}"
+func convertPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+// groupTag 使用分组前缀的最后一段作为标签
+func groupTag(group string) string {
+	group = strings.Trim(group, "/")
+	if group == "" {
+		return ""
+	}
+	return group[strings.LastIndex(group, "/")+1:]
+}
+
+// handlerOperationID 根据处理函数名生成operationId，如"controller.(*DemoHandler).List"生成"DemoHandler.List"
+// 匿名函数无法得到有意义的名称，返回空字符串
+func handlerOperationID(handler string) string {
+	if handler == "" || closurePattern.MatchString(handler) {
+		return ""
+	}
+	if idx := strings.LastIndex(handler, "/"); idx >= 0 {
+		handler = handler[idx+1:]
+	}
+	parts := strings.Split(handler, ".")
+	if len(parts) > 1 {
+		// 去掉包名
+		parts = parts[1:]
+	}
+	name := strings.Join(parts, ".")
+	name = strings.NewReplacer("(*", "", "(", "", ")", "").Replace(name)
+	return name
+}
+
+// fallbackOperationID 根据方法和路径生成operationId，如"GET /api/demo/:id"生成"get_api_demo_id"
+func fallbackOperationID(method, path string) string {
+	id := strings.ToLower(method) + "_" + strings.Trim(operationChar.ReplaceAllString(path, "_"), "_")
+	return strings.TrimSuffix(id, "_")
+}
+
+// indirectType 获取指针指向的类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isFileType 判断字段是否为上传文件
+func isFileType(t reflect.Type) bool {
+	t = indirectType(t)
+	if t.Kind() == reflect.Slice {
+		t = indirectType(t.Elem())
+	}
+	return t == fileHeaderType
+}
@@ -0,0 +1,23 @@
+package openapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// Handler 返回输出OpenAPI文档的处理函数
+// 文档在首次请求时生成，此时所有模块的路由都已注册完成
+func Handler(routes func() []unified.RouteInfo, info Info, servers ...Server) unified.HandlerFunc {
+	var (
+		once sync.Once
+		doc  *Document
+	)
+	return func(c unified.Context) error {
+		once.Do(func() {
+			doc = Generate(routes(), info, servers...)
+		})
+		return c.JSON(http.StatusOK, doc)
+	}
+}
@@ -0,0 +1,382 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+	fileHeaderType  = reflect.TypeOf(multipart.FileHeader{})
+	jsonMarshaler   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	pkgPathPattern  = regexp.MustCompile(`(?:[\w\-.]+/)*[\w\-]+\.`)
+	invalidNameChar = regexp.MustCompile(`[^A-Za-z0-9_.\-]+`)
+)
+
+// 校验规则所在的标签，兼容gin的binding和validator的validate
+var validateTags = []string{"binding", "validate"}
+
+// schemaRegistry 根据Go类型生成Schema，具名结构体注册为可复用组件
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+// newSchemaRegistry 创建Schema注册表
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf 获取类型对应的Schema，具名结构体返回$ref引用
+func (r *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "string", Example: "1s"}
+	case t == fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	case t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler),
+		t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler):
+		// 自定义序列化的类型（如types.TsTime）通常输出字符串
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float64Ptr(0)}
+	case reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float64Ptr(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t, "json")
+		}
+		return &Schema{Ref: "#/components/schemas/" + r.register(t)}
+	}
+
+	// interface{}等无法确定的类型
+	return &Schema{}
+}
+
+// register 注册具名结构体组件并返回组件名
+func (r *schemaRegistry) register(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := componentName(t)
+	// 不同包的同名类型加上包名区分
+	if _, exists := r.schemas[name]; exists {
+		pkg := t.PkgPath()
+		if idx := strings.LastIndex(pkg, "/"); idx >= 0 {
+			pkg = pkg[idx+1:]
+		}
+		name = pkg + "." + name
+		for i := 2; ; i++ {
+			if _, exists := r.schemas[name]; !exists {
+				break
+			}
+			name = componentName(t) + strconv.Itoa(i)
+		}
+	}
+
+	// 先占位，避免递归类型无限展开
+	r.names[t] = name
+	r.schemas[name] = &Schema{}
+	*r.schemas[name] = *r.structSchema(t, "json")
+	return name
+}
+
+// structSchema 生成结构体的对象Schema
+func (r *schemaRegistry) structSchema(t reflect.Type, tag string) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, f := range structFields(t, tag) {
+		fieldSchema := r.schemaOf(f.field.Type)
+		required := applyRules(fieldSchema, f.field)
+		if desc := fieldDescription(f.field); desc != "" && fieldSchema.Ref == "" {
+			fieldSchema.Description = desc
+		}
+		schema.Properties[f.name] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, f.name)
+		}
+	}
+	return schema
+}
+
+// components 获取已注册的组件
+func (r *schemaRegistry) components() map[string]*Schema {
+	return r.schemas
+}
+
+// namedField 带有标签名的结构体字段
+type namedField struct {
+	name  string
+	field reflect.StructField
+}
+
+// structFields 按照标签列出结构体字段，匿名嵌入的结构体会被展开
+func structFields(t reflect.Type, tag string) []namedField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []namedField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, structFields(ft, tag)...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			// json默认使用字段名，其余标签要求显式声明
+			if tag != "json" || isParamField(f) {
+				continue
+			}
+			name = f.Name
+		}
+		fields = append(fields, namedField{name: name, field: f})
+	}
+	return fields
+}
+
+// isParamField 判断字段是否只用于路径、查询或请求头参数
+func isParamField(f reflect.StructField) bool {
+	for _, tag := range []string{"uri", "form", "header"} {
+		if _, ok := f.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag 判断结构体是否存在带有指定标签的字段
+func hasTag(t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup(tag); ok {
+			return true
+		}
+		if f.Anonymous && hasTag(f.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldDescription 获取字段描述，依次读取description和label标签
+func fieldDescription(f reflect.StructField) string {
+	if desc := f.Tag.Get("description"); desc != "" {
+		return desc
+	}
+	return f.Tag.Get("label")
+}
+
+// applyRules 将校验标签转换为Schema约束，返回字段是否必填
+func applyRules(schema *Schema, f reflect.StructField) bool {
+	required := false
+	for _, tag := range validateTags {
+		rules := f.Tag.Get(tag)
+		if rules == "" {
+			continue
+		}
+		for _, rule := range strings.Split(rules, ",") {
+			key, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if key == "required" {
+				required = true
+				continue
+			}
+			if schema.Ref != "" {
+				continue
+			}
+			applyRule(schema, key, param)
+		}
+	}
+	if example := f.Tag.Get("example"); example != "" && schema.Ref == "" {
+		schema.Example = convertValue(schema.Type, example)
+	}
+	return required
+}
+
+// applyRule 应用单条校验规则
+func applyRule(schema *Schema, key, param string) {
+	switch key {
+	case "min", "gte":
+		setLowerBound(schema, param, false)
+	case "max", "lte":
+		setUpperBound(schema, param, false)
+	case "gt":
+		setLowerBound(schema, param, true)
+	case "lt":
+		setUpperBound(schema, param, true)
+	case "len":
+		setLowerBound(schema, param, false)
+		setUpperBound(schema, param, false)
+	case "oneof":
+		for _, v := range strings.Fields(param) {
+			schema.Enum = append(schema.Enum, convertValue(schema.Type, v))
+		}
+	case "email":
+		schema.Format = "email"
+	case "url", "uri":
+		schema.Format = "uri"
+	case "uuid", "uuid4":
+		schema.Format = "uuid"
+	case "ip", "ipv4":
+		schema.Format = "ipv4"
+	case "ipv6":
+		schema.Format = "ipv6"
+	case "date":
+		schema.Format = "date"
+	case "datetime":
+		schema.Format = "date-time"
+	case "phone":
+		schema.Pattern = `^1[3-9]\d{9}$`
+	case "zipcode":
+		schema.Pattern = `^\d{6}$`
+	}
+}
+
+// setLowerBound 设置下限，数值对应minimum，字符串对应minLength，数组对应minItems
+func setLowerBound(schema *Schema, param string, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if exclusive {
+			schema.ExclusiveMinimum = &n
+		} else {
+			schema.Minimum = &n
+		}
+	case "string":
+		if exclusive {
+			n++
+		}
+		schema.MinLength = intPtr(int(n))
+	case "array":
+		if exclusive {
+			n++
+		}
+		schema.MinItems = intPtr(int(n))
+	}
+}
+
+// setUpperBound 设置上限，数值对应maximum，字符串对应maxLength，数组对应maxItems
+func setUpperBound(schema *Schema, param string, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if exclusive {
+			schema.ExclusiveMaximum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	case "string":
+		if exclusive {
+			n--
+		}
+		schema.MaxLength = intPtr(int(n))
+	case "array":
+		if exclusive {
+			n--
+		}
+		schema.MaxItems = intPtr(int(n))
+	}
+}
+
+// convertValue 按照Schema类型转换字符串值
+func convertValue(typ, value string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// componentName 生成组件名，泛型参数去掉包路径
+func componentName(t reflect.Type) string {
+	name := pkgPathPattern.ReplaceAllString(t.Name(), "")
+	name = invalidNameChar.ReplaceAllString(name, "_")
+	return strings.Trim(name, "_")
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
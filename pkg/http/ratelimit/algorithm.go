@@ -0,0 +1,271 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+)
+
+// fixedWindow 固定窗口限流，每个窗口使用独立的计数键，窗口结束后计数键过期
+type fixedWindow struct {
+	cache  cache.Cache
+	prefix string
+	limit  int64
+	window time.Duration
+}
+
+// Allow 实现Limiter接口
+func (l *fixedWindow) Allow(ctx context.Context, key string) (Result, error) {
+	now := time.Now()
+	start := now.Truncate(l.window)
+	reset := start.Add(l.window).Sub(now)
+	counterKey := l.prefix + key + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+
+	count, err := l.cache.IncrByCtx(ctx, counterKey, 1)
+	if err != nil {
+		return Result{}, err
+	}
+	if count == 1 {
+		// 多保留一秒，文件缓存的过期时间精确到秒
+		if err := l.cache.ExpireCtx(ctx, counterKey, reset+time.Second); err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{
+		Allowed:   count <= l.limit,
+		Limit:     l.limit,
+		Remaining: max(l.limit-count, 0),
+		Reset:     reset,
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	return result, nil
+}
+
+// slidingWindow 滑动窗口限流，有序集合中每个成员是一次请求，分数为请求时间（毫秒）
+type slidingWindow struct {
+	cache  cache.Cache
+	prefix string
+	limit  int64
+	window time.Duration
+}
+
+// Allow 实现Limiter接口
+func (l *slidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	now := time.Now()
+	setKey := l.prefix + key
+	nowMs := float64(now.UnixMilli())
+	windowMs := float64(l.window.Milliseconds())
+
+	if err := l.trim(ctx, setKey, nowMs-windowMs); err != nil {
+		return Result{}, err
+	}
+
+	// 先记录请求再计数，并发请求不会同时通过最后一个名额
+	member := requestMember(now)
+	if _, err := l.cache.ZAddCtx(ctx, setKey, cache.Z{Score: nowMs, Member: member}); err != nil {
+		return Result{}, err
+	}
+	count, err := l.cache.ZCardCtx(ctx, setKey)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := l.cache.ExpireCtx(ctx, setKey, l.window+time.Second); err != nil {
+		return Result{}, err
+	}
+
+	// 最早的请求离开窗口后恢复名额
+	oldest := nowMs
+	if first, err := l.cache.ZRangeWithScoresCtx(ctx, setKey, 0, 0); err != nil {
+		return Result{}, err
+	} else if len(first) > 0 {
+		oldest = first[0].Score
+	}
+	reset := time.Duration(oldest+windowMs-nowMs) * time.Millisecond
+	result := Result{
+		Allowed:   count <= l.limit,
+		Limit:     l.limit,
+		Remaining: max(l.limit-count, 0),
+		Reset:     max(reset, 0),
+	}
+	if !result.Allowed {
+		// 被拒绝的请求不占用名额
+		if _, err := l.cache.ZRemCtx(ctx, setKey, member); err != nil {
+			return Result{}, err
+		}
+		result.RetryAfter = result.Reset
+	}
+	return result, nil
+}
+
+// trim 删除分数不大于cutoff的请求，即窗口之外的请求
+func (l *slidingWindow) trim(ctx context.Context, setKey string, cutoff float64) error {
+	if remover, ok := l.cache.(cache.ZRangeRemover); ok {
+		_, err := remover.ZRemRangeByScoreCtx(ctx, setKey, math.Inf(-1), cutoff)
+		return err
+	}
+
+	// 缓存不支持按分数范围删除时读取所有成员
+	members, err := l.cache.ZRangeWithScoresCtx(ctx, setKey, 0, -1)
+	if err != nil && !cache.IsMiss(err) {
+		return err
+	}
+	var expired []interface{}
+	for _, m := range members {
+		if m.Score <= cutoff {
+			expired = append(expired, m.Member)
+		}
+	}
+	if len(expired) > 0 {
+		_, err = l.cache.ZRemCtx(ctx, setKey, expired...)
+	}
+	return err
+}
+
+// requestMember 生成有序集合成员，同一毫秒内的请求也不重复
+func requestMember(now time.Time) string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return strconv.FormatInt(now.UnixNano(), 10) + "-" + hex.EncodeToString(b)
+}
+
+// tokenBucket 令牌桶限流，哈希中保存剩余令牌数和上次更新时间（毫秒），令牌补满后状态过期。
+// 缓存实现了cache.Scripter接口（Redis）时通过Lua脚本原子地读取和更新状态，
+// 否则在当前进程内按键串行执行，内存和文件缓存只在当前进程内有效，因此同样不会超出限制
+type tokenBucket struct {
+	cache  cache.Cache
+	prefix string
+	limit  int64
+	window time.Duration
+}
+
+// tokenBucketScript 读取、补充并消耗令牌，返回是否允许和剩余令牌数
+var tokenBucketScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tokens = limit
+local state = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+if state[1] and state[2] then
+	local elapsed = math.max(now - tonumber(state[2]), 0)
+	tokens = math.min(limit, tonumber(state[1]) + elapsed * rate)
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}`)
+
+// bucketLocks 没有Lua脚本时按键串行执行令牌桶，使用固定数量的锁避免为每个键创建锁
+var bucketLocks [64]sync.Mutex
+
+// Allow 实现Limiter接口
+func (l *tokenBucket) Allow(ctx context.Context, key string) (Result, error) {
+	now := time.Now()
+	bucketKey := l.prefix + key
+	// 每毫秒补充的令牌数
+	rate := float64(l.limit) / float64(l.window.Milliseconds())
+
+	var (
+		allowed bool
+		tokens  float64
+		err     error
+	)
+	if scripter, ok := l.cache.(cache.Scripter); ok {
+		allowed, tokens, err = l.takeScript(ctx, scripter, bucketKey, rate, now)
+	} else {
+		h := fnv.New32a()
+		h.Write([]byte(bucketKey))
+		mu := &bucketLocks[h.Sum32()%uint32(len(bucketLocks))]
+		mu.Lock()
+		allowed, tokens, err = l.take(ctx, bucketKey, rate, now)
+		mu.Unlock()
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     l.limit,
+		Remaining: int64(tokens),
+		Reset:     time.Duration((float64(l.limit)-tokens)/rate) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1-tokens)/rate) * time.Millisecond
+	}
+	return result, nil
+}
+
+// takeScript 通过Lua脚本消耗令牌
+func (l *tokenBucket) takeScript(ctx context.Context, scripter cache.Scripter, bucketKey string, rate float64, now time.Time) (bool, float64, error) {
+	reply, err := scripter.RunScriptCtx(ctx, tokenBucketScript, []string{bucketKey},
+		l.limit,
+		strconv.FormatFloat(rate, 'f', -1, 64),
+		now.UnixMilli(),
+		(l.window + time.Second).Milliseconds(),
+	)
+	if err != nil {
+		return false, 0, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("令牌桶脚本返回值无效: %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("令牌桶脚本返回值无效: %v", reply)
+	}
+	return allowed == 1, tokens, nil
+}
+
+// take 读取状态、消耗令牌并写回，调用方需要按键串行执行
+func (l *tokenBucket) take(ctx context.Context, bucketKey string, rate float64, now time.Time) (bool, float64, error) {
+	state, err := l.cache.HGetAllCtx(ctx, bucketKey)
+	if err != nil && !cache.IsMiss(err) {
+		return false, 0, err
+	}
+	tokens := float64(l.limit)
+	if len(state) > 0 {
+		last, err1 := strconv.ParseInt(state["updated_at"], 10, 64)
+		saved, err2 := strconv.ParseFloat(state["tokens"], 64)
+		if err1 != nil || err2 != nil {
+			return false, 0, fmt.Errorf("令牌桶状态无效: %v", state)
+		}
+		elapsed := float64(max(now.UnixMilli()-last, 0))
+		tokens = math.Min(float64(l.limit), saved+elapsed*rate)
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	if _, err := l.cache.HSetCtx(ctx, bucketKey,
+		"tokens", strconv.FormatFloat(tokens, 'f', -1, 64),
+		"updated_at", strconv.FormatInt(now.UnixMilli(), 10),
+	); err != nil {
+		return false, 0, err
+	}
+	if err := l.cache.ExpireCtx(ctx, bucketKey, l.window+time.Second); err != nil {
+		return false, 0, err
+	}
+	return allowed, tokens, nil
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// KeyFunc 从请求中获取限流键，相同键的请求共享配额
+type KeyFunc func(c unified.Context) string
+
+// ByIP 按客户端IP限流，客户端IP来自unified.Context.ClientIP：只有连接来自http.trusted_proxies中的代理时才读取X-Forwarded-For，
+// 否则使用连接的远程地址，客户端无法通过伪造请求头重置配额；部署在反向代理之后却没有配置可信代理时，所有请求的IP都是代理的地址，共享同一份配额
+func ByIP(c unified.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByRoute 按路由限流，同一路由模板的所有请求共享配额，通常与其他键组合使用
+func ByRoute(c unified.Context) string {
+	return "route:" + c.Method() + " " + c.Path()
+}
+
+// ByUser 按用户ID限流，用户ID从上下文数据中读取，默认键为unified.UserIDKey，未登录的请求按客户端IP限流
+func ByUser(key ...string) KeyFunc {
+	name := unified.UserIDKey
+	if len(key) > 0 && key[0] != "" {
+		name = key[0]
+	}
+	return func(c unified.Context) string {
+		if v, ok := c.Get(name); ok && v != nil {
+			if id := fmt.Sprint(v); id != "" {
+				return "user:" + id
+			}
+		}
+		return ByIP(c)
+	}
+}
+
+// ByAPIKey 按请求头中的API Key限流，默认请求头为X-API-Key，没有API Key的请求按客户端IP限流；
+// 限流键使用API Key的SHA-256摘要，缓存中不保存API Key原文
+func ByAPIKey(header ...string) KeyFunc {
+	name := "X-API-Key"
+	if len(header) > 0 && header[0] != "" {
+		name = header[0]
+	}
+	return func(c unified.Context) string {
+		if apiKey := c.GetHeader(name); apiKey != "" {
+			sum := sha256.Sum256([]byte(apiKey))
+			return "key:" + hex.EncodeToString(sum[:])
+		}
+		return ByIP(c)
+	}
+}
+
+// Compose 组合多个限流键，例如Compose(ByRoute, ByIP)表示每个IP在每个路由上单独计数
+func Compose(keys ...KeyFunc) KeyFunc {
+	return func(c unified.Context) string {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, key(c))
+		}
+		return strings.Join(parts, "|")
+	}
+}
@@ -0,0 +1,8 @@
+package ratelimit
+
+import "go.uber.org/fx"
+
+// Module 限流模块，提供*RateLimiter
+var Module = fx.Options(
+	fx.Provide(NewRateLimiter),
+)
@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// Algorithm 限流算法
+type Algorithm string
+
+const (
+	// FixedWindow 固定窗口，每个窗口内最多Limit个请求，通过IncrBy和Expire计数
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindow 滑动窗口，任意Window时长内最多Limit个请求，通过有序集合记录请求时间
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket 令牌桶，容量为Limit，每个Window补满，允许突发请求
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Rule 限流规则
+type Rule struct {
+	// Name 规则名称，用于区分不同规则的计数，默认由算法、数量和窗口生成
+	Name string
+	// Algorithm 限流算法，默认FixedWindow
+	Algorithm Algorithm
+	// Limit 窗口内允许的请求数，令牌桶的容量
+	Limit int64
+	// Window 窗口时长，令牌桶从空到补满的时间，默认1秒
+	Window time.Duration
+	// Key 限流键，默认按客户端IP限流
+	Key KeyFunc
+	// Skip 返回true的请求不限流
+	Skip func(c unified.Context) bool
+}
+
+// Result 限流结果
+type Result struct {
+	// Allowed 是否允许请求
+	Allowed bool
+	// Limit 窗口内允许的请求数
+	Limit int64
+	// Remaining 当前剩余的请求数
+	Remaining int64
+	// Reset 配额完全恢复所需的时间
+	Reset time.Duration
+	// RetryAfter 请求被拒绝时需要等待的时间
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 记录一次请求并返回是否允许
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// RateLimiter 基于缓存的限流器工厂，内存、文件和Redis缓存的行为一致
+type RateLimiter struct {
+	cache  cache.Cache
+	logger log.Logger
+}
+
+// NewRateLimiter 创建限流器工厂
+func NewRateLimiter(cache cache.Cache, logger log.Logger) *RateLimiter {
+	return &RateLimiter{
+		cache:  cache,
+		logger: logger,
+	}
+}
+
+// Limiter 按规则创建限流器
+func (r *RateLimiter) Limiter(rule Rule) Limiter {
+	rule = normalize(rule)
+	prefix := "ratelimit:" + rule.Name + ":"
+	switch rule.Algorithm {
+	case SlidingWindow:
+		return &slidingWindow{cache: r.cache, prefix: prefix, limit: rule.Limit, window: rule.Window}
+	case TokenBucket:
+		return &tokenBucket{cache: r.cache, prefix: prefix, limit: rule.Limit, window: rule.Window}
+	default:
+		return &fixedWindow{cache: r.cache, prefix: prefix, limit: rule.Limit, window: rule.Window}
+	}
+}
+
+// Middleware 按规则创建限流中间件，例如:
+//
+//	router.POST("/login", handler, limiter.Middleware(ratelimit.Rule{
+//		Algorithm: ratelimit.SlidingWindow,
+//		Limit:     5,
+//		Window:    time.Minute,
+//		Key:       ratelimit.Compose(ratelimit.ByRoute, ratelimit.ByIP),
+//	}))
+//
+// 响应带有RateLimit-Limit、RateLimit-Remaining、RateLimit-Reset和RateLimit-Policy头，
+// 超出限制时返回TooManyRequests错误并带有Retry-After头；缓存不可用时记录日志并放行请求
+func (r *RateLimiter) Middleware(rule Rule) unified.MiddlewareFunc {
+	rule = normalize(rule)
+	limiter := r.Limiter(rule)
+	policy := fmt.Sprintf("%d;w=%d", rule.Limit, seconds(rule.Window))
+
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			if rule.Skip != nil && rule.Skip(c) {
+				return next(c)
+			}
+
+			result, err := limiter.Allow(c.Context(), rule.Key(c))
+			if err != nil {
+				r.logger.WithContext(c.Context()).WithFields(map[string]interface{}{
+					"rule":  rule.Name,
+					"path":  c.Path(),
+					"error": err.Error(),
+				}).Warn("限流失败，放行请求")
+				return next(c)
+			}
+
+			c.SetHeader("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			c.SetHeader("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			c.SetHeader("RateLimit-Reset", strconv.FormatInt(seconds(result.Reset), 10))
+			c.SetHeader("RateLimit-Policy", policy)
+			if !result.Allowed {
+				c.SetHeader("Retry-After", strconv.FormatInt(seconds(result.RetryAfter), 10))
+				return response.TooManyRequests
+			}
+			return next(c)
+		}
+	}
+}
+
+// normalize 填充规则的默认值
+func normalize(rule Rule) Rule {
+	if rule.Algorithm == "" {
+		rule.Algorithm = FixedWindow
+	}
+	if rule.Limit <= 0 {
+		rule.Limit = 1
+	}
+	if rule.Window < time.Millisecond {
+		rule.Window = time.Second
+	}
+	if rule.Key == nil {
+		rule.Key = ByIP
+	}
+	if rule.Name == "" {
+		rule.Name = fmt.Sprintf("%s:%d:%s", rule.Algorithm, rule.Limit, rule.Window)
+	}
+	return rule
+}
+
+// seconds 将时长向上取整为秒
+func seconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(d.Seconds()))
+}
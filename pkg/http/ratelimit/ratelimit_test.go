@@ -0,0 +1,257 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	unifiedtesting "github.com/zhoudm1743/go-frame/pkg/http/unified/testing"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"github.com/zhoudm1743/go-frame/pkg/response"
+)
+
+// algorithms 测试的限流算法
+var algorithms = []Algorithm{FixedWindow, SlidingWindow, TokenBucket}
+
+// testCaches 返回内存缓存和文件缓存
+func testCaches(t *testing.T) map[string]cache.Cache {
+	return map[string]cache.Cache{"memory": cache.NewTestMemory(t), "file": cache.NewTestFile(t)}
+}
+
+func TestAllow(t *testing.T) {
+	ctx := context.Background()
+	for name, c := range testCaches(t) {
+		for _, algorithm := range algorithms {
+			limiter := NewRateLimiter(c, log.NewDiscardLogger()).Limiter(Rule{Algorithm: algorithm, Limit: 3, Window: time.Hour})
+
+			for i, remaining := range []int64{2, 1, 0} {
+				result, err := limiter.Allow(ctx, "a")
+				if err != nil {
+					t.Fatalf("%s/%s: %v", name, algorithm, err)
+				}
+				if !result.Allowed || result.Limit != 3 || result.Remaining != remaining {
+					t.Errorf("%s/%s: 第%d次请求 %+v，期望允许并剩余 %d", name, algorithm, i+1, result, remaining)
+				}
+				if result.Reset <= 0 || result.Reset > time.Hour {
+					t.Errorf("%s/%s: 第%d次请求的Reset %s", name, algorithm, i+1, result.Reset)
+				}
+			}
+
+			result, err := limiter.Allow(ctx, "a")
+			if err != nil {
+				t.Fatalf("%s/%s: %v", name, algorithm, err)
+			}
+			if result.Allowed || result.Remaining != 0 || result.RetryAfter <= 0 {
+				t.Errorf("%s/%s: 超出限制 %+v，期望拒绝并带有RetryAfter", name, algorithm, result)
+			}
+
+			// 不同的键单独计数
+			if result, _ := limiter.Allow(ctx, "b"); !result.Allowed || result.Remaining != 2 {
+				t.Errorf("%s/%s: 其他键 %+v", name, algorithm, result)
+			}
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	ctx := context.Background()
+	for name, c := range testCaches(t) {
+		for _, algorithm := range algorithms {
+			limiter := NewRateLimiter(c, log.NewDiscardLogger()).Limiter(Rule{Algorithm: algorithm, Limit: 2, Window: 200 * time.Millisecond})
+
+			var rejected Result
+			for i := 0; i < 3; i++ {
+				result, err := limiter.Allow(ctx, "a")
+				if err != nil {
+					t.Fatalf("%s/%s: %v", name, algorithm, err)
+				}
+				rejected = result
+			}
+			if rejected.Allowed {
+				// 固定窗口可能恰好跨过窗口边界
+				continue
+			}
+			if rejected.RetryAfter > 200*time.Millisecond {
+				t.Errorf("%s/%s: RetryAfter %s 超过窗口", name, algorithm, rejected.RetryAfter)
+			}
+
+			// 等待RetryAfter后恢复名额
+			time.Sleep(rejected.RetryAfter + 20*time.Millisecond)
+			if result, err := limiter.Allow(ctx, "a"); err != nil || !result.Allowed {
+				t.Errorf("%s/%s: 等待 %s 后 %+v, %v", name, algorithm, rejected.RetryAfter, result, err)
+			}
+		}
+	}
+}
+
+// 同一个键的并发请求不会超出限制
+func TestTokenBucketConcurrent(t *testing.T) {
+	for name, c := range testCaches(t) {
+		limiter := NewRateLimiter(c, log.NewDiscardLogger()).Limiter(Rule{Algorithm: TokenBucket, Limit: 5, Window: time.Hour})
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			allowed int
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := limiter.Allow(context.Background(), "a")
+				if err != nil {
+					t.Errorf("%s: %v", name, err)
+					return
+				}
+				if result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if allowed != 5 {
+			t.Errorf("%s: 并发请求允许 %d 次，期望 5", name, allowed)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	h := unifiedtesting.New()
+	defer h.Close()
+
+	keys := map[string]KeyFunc{
+		"ip":      ByIP,
+		"user":    ByUser(),
+		"apikey":  ByAPIKey(),
+		"route":   ByRoute,
+		"compose": Compose(ByRoute, ByAPIKey("X-Token")),
+	}
+	h.Router().GET("/orders/:id", func(c unified.Context) error {
+		if c.GetHeader("X-User") != "" {
+			c.Set(unified.UserIDKey, c.GetHeader("X-User"))
+		}
+		return c.String(http.StatusOK, keys[c.Query("key")](c))
+	})
+
+	sum := sha256.Sum256([]byte("secret"))
+	hashed := "key:" + hex.EncodeToString(sum[:])
+	cases := []struct {
+		key    string
+		header []string
+		want   string
+	}{
+		{"ip", nil, "ip:127.0.0.1"},
+		{"user", []string{"X-User", "42"}, "user:42"},
+		{"user", nil, "ip:127.0.0.1"},
+		{"apikey", []string{"X-API-Key", "secret"}, hashed},
+		{"apikey", nil, "ip:127.0.0.1"},
+		{"route", nil, "route:GET /orders/:id"},
+		{"compose", []string{"X-Token", "secret"}, "route:GET /orders/:id|" + hashed},
+	}
+	for _, tc := range cases {
+		req := unifiedtesting.NewRequest(http.MethodGet, "/orders/1?key="+tc.key, nil)
+		for i := 0; i+1 < len(tc.header); i += 2 {
+			req = req.WithHeader(tc.header[i], tc.header[i+1])
+		}
+		responses, err := h.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, resp := range responses {
+			if got := string(resp.Body); got != tc.want {
+				t.Errorf("%s: %s %v 的限流键 %q，期望 %q", resp.Engine, tc.key, tc.header, got, tc.want)
+			}
+			if strings.Contains(string(resp.Body), "secret") {
+				t.Errorf("%s: 限流键包含API Key原文", resp.Engine)
+			}
+		}
+	}
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	mw := NewRateLimiter(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{Limit: 2, Window: time.Minute})
+	ok := func(c unified.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+	serve := func() (*httptest.ResponseRecorder, error) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		return w, unified.NewChain(mw).Then(ok)(unified.NewStdContext(w, r))
+	}
+
+	for _, remaining := range []string{"1", "0"} {
+		w, err := serve()
+		if err != nil || w.Code != http.StatusOK {
+			t.Fatalf("允许的请求: %d, %v", w.Code, err)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != remaining {
+			t.Errorf("RateLimit-Remaining %q，期望 %q", got, remaining)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("RateLimit-Limit %q，期望 2", got)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("RateLimit-Policy %q，期望 2;w=60", got)
+		}
+		if reset, err := strconv.Atoi(w.Header().Get("RateLimit-Reset")); err != nil || reset < 1 || reset > 60 {
+			t.Errorf("RateLimit-Reset %q", w.Header().Get("RateLimit-Reset"))
+		}
+		if w.Header().Get("Retry-After") != "" {
+			t.Error("允许的请求带有Retry-After")
+		}
+	}
+
+	w, err := serve()
+	if !errors.Is(err, response.TooManyRequests) {
+		t.Fatalf("超出限制: 错误 %v，期望 TooManyRequests", err)
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("RateLimit-Remaining %q，期望 0", w.Header().Get("RateLimit-Remaining"))
+	}
+	if retry, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || retry < 1 || retry > 60 {
+		t.Errorf("Retry-After %q", w.Header().Get("Retry-After"))
+	}
+}
+
+// brokenCache 计数总是失败的缓存
+type brokenCache struct {
+	cache.Cache
+}
+
+func (brokenCache) IncrByCtx(ctx context.Context, key string, value int64) (int64, error) {
+	return 0, errors.New("cache unavailable")
+}
+
+// 缓存不可用和Skip返回true时放行请求
+func TestMiddlewarePass(t *testing.T) {
+	limiter := NewRateLimiter(brokenCache{cache.NewTestMemory(t)}, log.NewDiscardLogger())
+	always := func(c unified.Context) bool { return true }
+
+	for name, mw := range map[string]unified.MiddlewareFunc{
+		"cache": limiter.Middleware(Rule{Limit: 1}),
+		"skip":  NewRateLimiter(cache.NewTestMemory(t), log.NewDiscardLogger()).Middleware(Rule{Limit: 1, Skip: always}),
+	} {
+		for i := 0; i < 3; i++ {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			err := unified.NewChain(mw).Then(func(c unified.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})(unified.NewStdContext(w, r))
+			if err != nil || w.Code != http.StatusOK {
+				t.Fatalf("%s: 第%d次请求 %d, %v", name, i+1, w.Code, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+
+	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"go.uber.org/fx"
+)
+
+// RoutesGroup 路由注册器所在的fx值组名称
+const RoutesGroup = "routes"
+
+// RoutePrefixer 路由前缀接口
+// 路由注册器实现此接口时，其路由挂载到返回的前缀下
+type RoutePrefixer interface {
+	RoutePrefix() string
+}
+
+// prefixedRoutes 带有模块路由前缀的路由注册器
+type prefixedRoutes struct {
+	prefix   string
+	register RouterRegister
+}
+
+// RegisterRoutes 实现RouterRegister接口
+func (p *prefixedRoutes) RegisterRoutes(router ctx.Router) {
+	p.register.RegisterRoutes(router)
+}
+
+// RoutePrefix 实现RoutePrefixer接口
+func (p *prefixedRoutes) RoutePrefix() string {
+	return p.prefix
+}
+
+// ProvideRoutes 将容器中类型为T的路由注册器加入routes组，路由挂载到prefix下
+// T需要由模块自行提供，例如:
+//
+//	fx.Provide(controller.NewProductRouter),
+//	http.ProvideRoutes[*controller.ProductRouter](m.RoutePrefix()),
+func ProvideRoutes[T RouterRegister](prefix string) fx.Option {
+	return fx.Provide(
+		fx.Annotate(
+			func(register T) RouterRegister {
+				return &prefixedRoutes{prefix: prefix, register: register}
+			},
+			fx.ResultTags(fmt.Sprintf(`group:"%s"`, RoutesGroup)),
+		),
+	)
+}
+
+// MountRoutesParams 路由挂载参数
+type MountRoutesParams struct {
+	fx.In
+	Server  Server
+	Logger  log.Logger
+	Routers []RouterRegister `group:"routes"`
+}
+
+// MountRoutes 挂载routes组中的所有路由注册器
+// fx值组的顺序不固定，按照路由前缀和注册器类型名排序后依次挂载，保证路由注册顺序稳定
+func MountRoutes(p MountRoutesParams) {
+	routers := make([]RouterRegister, len(p.Routers))
+	copy(routers, p.Routers)
+	sort.SliceStable(routers, func(i, j int) bool {
+		pi, pj := routePrefix(routers[i]), routePrefix(routers[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return registerName(routers[i]) < registerName(routers[j])
+	})
+
+	for _, register := range routers {
+		prefix := routePrefix(register)
+		router := p.Server.Router()
+		if prefix != "" {
+			router = router.Group(prefix)
+		}
+		register.RegisterRoutes(router)
+		p.Logger.Debugf("挂载路由注册器: %s，前缀: %s", registerName(register), prefix)
+	}
+}
+
+// routePrefix 获取路由注册器的前缀
+func routePrefix(register RouterRegister) string {
+	if p, ok := register.(RoutePrefixer); ok {
+		return p.RoutePrefix()
+	}
+	return ""
+}
+
+// registerName 获取路由注册器的类型名
+func registerName(register RouterRegister) string {
+	if p, ok := register.(*prefixedRoutes); ok {
+		return registerName(p.register)
+	}
+	return fmt.Sprintf("%T", register)
+}
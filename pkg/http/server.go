@@ -11,10 +11,12 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/health"
 	"github.com/zhoudm1743/go-frame/pkg/http/middleware"
 	ctx "github.com/zhoudm1743/go-frame/pkg/http/unified"
 	"github.com/zhoudm1743/go-frame/pkg/log"
 	"github.com/zhoudm1743/go-frame/pkg/response"
+	"github.com/zhoudm1743/go-frame/pkg/validate"
 	"go.uber.org/fx"
 )
 
@@ -120,6 +122,12 @@ func (r *routeLoggerDecorator) OPTIONS(path string, handler ctx.HandlerFunc, mid
 	return r.Router.OPTIONS(path, handler, middlewares...)
 }
 
+// WebSocket 实现Router接口
+func (r *routeLoggerDecorator) WebSocket(path string, handler ctx.WSHandler, middlewares ...ctx.MiddlewareFunc) ctx.Router {
+	r.logRoute("WS", path)
+	return r.Router.WebSocket(path, handler, middlewares...)
+}
+
 // Group 实现Router接口
 func (r *routeLoggerDecorator) Group(prefix string, middlewares ...ctx.MiddlewareFunc) ctx.Router {
 	// 构建新的前缀
@@ -159,28 +167,31 @@ type Server interface {
 	// 注册路由
 	RegisterRoutes(register RouterRegister) Server
 
-	// 设置错误处理器
-	SetErrorHandler(handler ctx.HandlerFunc) Server
+	// 设置错误处理器，取代统一错误处理流程
+	SetErrorHandler(handler ctx.ErrorHandler) Server
+
+	// 注册错误映射，按错误类型覆盖统一错误处理流程的默认映射
+	OnError(mappings ...ErrorMapping) Server
 
 	// 设置404处理器
 	SetNotFoundHandler(handler ctx.HandlerFunc) Server
 
 	// 设置405处理器
 	SetMethodNotAllowedHandler(handler ctx.HandlerFunc) Server
-}
 
-// RouterRegister 路由注册接口
-type RouterRegister interface {
-	// RegisterRoutes 注册路由到路由器
-	RegisterRoutes(router ctx.Router)
+	// 获取已注册的路由列表
+	Routes() []ctx.RouteInfo
 }
 
+// RouterRegister 路由注册接口，与unified.RouterRegister为同一类型
+type RouterRegister = ctx.RouterRegister
+
 // ServerConfig 服务器配置
 type ServerConfig struct {
 	// 服务器地址
 	Addr string
 
-	// 引擎类型: "gin" 或 "fiber"
+	// 引擎类型: "gin"、"fiber" 或 "std"
 	Engine string
 
 	// 是否启用调试模式
@@ -195,14 +206,32 @@ type ServerConfig struct {
 	// 请求体大小限制
 	BodyLimit int
 
-	// 是否启用CORS
+	// 可信代理的IP或CIDR，只有连接来自可信代理时才使用X-Forwarded-For和X-Real-IP中的客户端IP
+	TrustedProxies []string
+
+	// 是否启用全局跨域中间件，路由组和路由上可以使用ctx.CORS覆盖
 	EnableCORS bool
 
+	// 全局跨域配置
+	CORS ctx.CORSConfig
+
+	// 是否启用全局压缩中间件，路由组和路由上可以使用ctx.Compress覆盖
+	EnableCompression bool
+
+	// 全局压缩配置
+	Compression ctx.CompressConfig
+
 	// 是否启用请求日志
 	EnableRequestLog bool
 
+	// 是否为每个请求设置请求ID，请求ID通过X-Request-ID响应头返回并记录到日志中
+	EnableRequestID bool
+
 	// 是否启用恢复中间件
 	EnableRecover bool
+
+	// Cookie默认属性和密钥
+	Cookie ctx.CookieConfig
 }
 
 // UnifiedServer 统一的HTTP服务器实现
@@ -212,27 +241,63 @@ type UnifiedServer struct {
 	ginEngine  *gin.Engine
 	ginServer  *http.Server
 	fiberApp   *fiber.App
+	stdMux     *stdMuxHandler
+	stdServer  *http.Server
 	logger     log.Logger
+	errors     *ErrorPipeline
 	middleware []ctx.MiddlewareFunc
 }
 
-// NewUnifiedServer 创建统一的HTTP服务器
-func NewUnifiedServer(config *ServerConfig, logger log.Logger) *UnifiedServer {
+// NewUnifiedServer 创建统一的HTTP服务器，Cookie或可信代理配置无效时返回错误
+func NewUnifiedServer(config *ServerConfig, logger log.Logger) (*UnifiedServer, error) {
 	server := &UnifiedServer{
 		config:     config,
 		logger:     logger,
+		errors:     NewErrorPipeline(logger),
 		middleware: []ctx.MiddlewareFunc{},
 	}
 
+	// 所有引擎的处理函数返回的错误都交给统一错误处理流程
+	ctx.SetErrorHandler(server.errors.Handle)
+
+	// 类型化处理函数使用统一响应格式，校验错误翻译为中文
+	ctx.SetValidator(validate.ValidateStruct)
+	ctx.SetResponder(response.UnifiedResponder{})
+
+	if err := ctx.SetCookieConfig(config.Cookie); err != nil {
+		return nil, fmt.Errorf("Cookie配置无效: %w", err)
+	}
+
+	if err := ctx.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("可信代理配置无效: %w", err)
+	}
+
+	// 事件流开始后无法再输出错误响应，处理函数返回的错误记录到日志
+	ctx.SetSSEErrorHandler(func(path string, err error) {
+		logger.WithFields(map[string]interface{}{"path": path}).Errorf("事件流处理失败: %v", err)
+	})
+
 	// 根据配置创建引擎
 	switch config.Engine {
 	case "fiber":
 		server.initFiber()
+	case "std":
+		server.initStd()
 	default:
 		server.initGin()
 	}
 
-	return server
+	// 全局跨域中间件，注册路由时自动为路径添加OPTIONS路由处理预检请求
+	if config.EnableCORS {
+		server.Use(ctx.CORS(config.CORS))
+	}
+
+	// 全局压缩中间件，在跨域中间件之后最先执行
+	if config.EnableCompression {
+		server.Use(ctx.Compress(config.Compression))
+	}
+
+	return server, nil
 }
 
 // 初始化Gin引擎
@@ -260,11 +325,21 @@ func (s *UnifiedServer) initGin() {
 	// 创建Gin引擎
 	s.ginEngine = gin.New()
 
+	// 访问日志使用Gin自带的ClientIP，与统一上下文信任相同的代理，配置无效时不信任任何代理
+	if err := s.ginEngine.SetTrustedProxies(s.config.TrustedProxies); err != nil {
+		s.ginEngine.SetTrustedProxies(nil)
+	}
+
 	// 使用恢复中间件
 	if s.config.EnableRecover {
 		s.ginEngine.Use(gin.Recovery())
 	}
 
+	// 使用请求ID中间件，在日志中间件之前设置，访问日志才能记录请求ID
+	if s.config.EnableRequestID {
+		s.ginEngine.Use(ctx.ToGinMiddleware(ctx.RequestID()))
+	}
+
 	// 使用日志中间件
 	if s.config.EnableRequestLog {
 		s.ginEngine.Use(middleware.LogrusLogger(s.logger))
@@ -306,6 +381,11 @@ func (s *UnifiedServer) initFiber() {
 		ReadTimeout:  s.config.ReadTimeout,
 		WriteTimeout: s.config.WriteTimeout,
 		BodyLimit:    s.config.BodyLimit,
+		// 路由适配器已处理处理函数返回的错误，这里处理的是Fiber自身产生的错误，例如请求体超过大小限制
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			ctx.HandleError(ctx.NewFiberContext(c), err)
+			return nil
+		},
 		// 将Fiber的日志输出重定向到logrus
 		DisableStartupMessage: true, // 禁用默认的启动消息，由logrus处理
 	})
@@ -315,6 +395,11 @@ func (s *UnifiedServer) initFiber() {
 		s.fiberApp.Use(recover.New())
 	}
 
+	// 使用请求ID中间件，在日志中间件之前设置，访问日志才能记录请求ID
+	if s.config.EnableRequestID {
+		s.fiberApp.Use(ctx.ToFiberMiddleware(ctx.RequestID()))
+	}
+
 	// 使用日志中间件
 	if s.config.EnableRequestLog {
 		s.fiberApp.Use(middleware.FiberLogrusLogger(s.logger))
@@ -346,11 +431,126 @@ func (s *UnifiedServer) initFiber() {
 	s.logger.Info("Fiber服务器初始化完成")
 }
 
+// 初始化标准库net/http引擎
+func (s *UnifiedServer) initStd() {
+	// 创建路由分发器，未匹配的请求交给统一的404/405处理器
+	s.stdMux = &stdMuxHandler{
+		mux:      http.NewServeMux(),
+		notFound: response.StdNoRoute,
+		noMethod: response.StdNoMethod,
+	}
+
+	var handler http.Handler = s.stdMux
+
+	// 使用日志中间件
+	if s.config.EnableRequestLog {
+		handler = middleware.StdLogrusLogger(s.logger)(handler)
+	}
+
+	// 使用恢复中间件
+	if s.config.EnableRecover {
+		handler = middleware.StdRecovery(s.logger)(handler)
+	}
+
+	// 使用请求ID中间件，包装在日志和恢复中间件外层，访问日志和panic日志才能记录请求ID
+	if s.config.EnableRequestID {
+		handler = ctx.ToStdMiddleware(ctx.RequestID())(handler)
+	}
+
+	// 请求体大小限制
+	if s.config.BodyLimit > 0 {
+		handler = http.MaxBytesHandler(handler, int64(s.config.BodyLimit))
+	}
+
+	// 创建HTTP服务器
+	s.stdServer = &http.Server{
+		Addr:           s.config.Addr,
+		Handler:        handler,
+		ReadTimeout:    s.config.ReadTimeout,
+		WriteTimeout:   s.config.WriteTimeout,
+		MaxHeaderBytes: 1 << 20, // 1 MB
+	}
+
+	// 创建统一路由器
+	s.router = ctx.NewStdRouter(s.stdMux.mux)
+
+	// 包装路由器，增加日志记录功能
+	s.router = &routeLoggerDecorator{
+		Router: s.router,
+		logger: s.logger,
+		prefix: "", // 明确设置初始前缀为空
+	}
+
+	// 记录启动信息
+	s.logger.Info("标准库HTTP服务器初始化完成")
+}
+
+// stdMuxHandler 包装http.ServeMux，将未匹配的请求交给自定义的404/405处理器
+type stdMuxHandler struct {
+	mux      *http.ServeMux
+	notFound http.HandlerFunc
+	noMethod http.HandlerFunc
+}
+
+// ServeHTTP 实现http.Handler接口
+func (h *stdMuxHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, pattern := h.mux.Handler(r)
+	if pattern == "" {
+		// ServeMux对未匹配的请求返回内置处理器，通过探测其状态码区分404和405
+		probe := &stdStatusProbe{header: http.Header{}}
+		handler.ServeHTTP(probe, r)
+
+		switch probe.status {
+		case http.StatusNotFound:
+			h.notFound(w, r)
+			return
+		case http.StatusMethodNotAllowed:
+			if allow := probe.header.Get("Allow"); allow != "" {
+				w.Header().Set("Allow", allow)
+			}
+			h.noMethod(w, r)
+			return
+		}
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// stdStatusProbe 丢弃响应内容，仅记录状态码和响应头
+type stdStatusProbe struct {
+	header http.Header
+	status int
+}
+
+// Header 实现http.ResponseWriter接口
+func (p *stdStatusProbe) Header() http.Header {
+	return p.header
+}
+
+// Write 实现http.ResponseWriter接口
+func (p *stdStatusProbe) Write(data []byte) (int, error) {
+	if p.status == 0 {
+		p.status = http.StatusOK
+	}
+	return len(data), nil
+}
+
+// WriteHeader 实现http.ResponseWriter接口
+func (p *stdStatusProbe) WriteHeader(code int) {
+	if p.status == 0 {
+		p.status = code
+	}
+}
+
 // Router 实现Server接口
 func (s *UnifiedServer) Router() ctx.Router {
 	return s.router
 }
 
+// Routes 实现Server接口
+func (s *UnifiedServer) Routes() []ctx.RouteInfo {
+	return s.router.Routes()
+}
+
 // Use 实现Server接口
 func (s *UnifiedServer) Use(middlewares ...ctx.MiddlewareFunc) Server {
 	s.middleware = append(s.middleware, middlewares...)
@@ -368,23 +568,14 @@ func (s *UnifiedServer) RegisterRoutes(register RouterRegister) Server {
 }
 
 // SetErrorHandler 实现Server接口
-func (s *UnifiedServer) SetErrorHandler(handler ctx.HandlerFunc) Server {
-	switch s.config.Engine {
-	case "fiber":
-		if s.fiberApp != nil {
-			// 创建一个新的Fiber应用，保留原有配置但更新错误处理器
-			config := s.fiberApp.Config()
-			config.ErrorHandler = func(c *fiber.Ctx, err error) error {
-				ctx := ctx.NewFiberContext(c)
-				return handler(ctx)
-			}
+func (s *UnifiedServer) SetErrorHandler(handler ctx.ErrorHandler) Server {
+	ctx.SetErrorHandler(handler)
+	return s
+}
 
-			// 由于无法直接修改Config，我们只能记录这个情况
-			s.logger.Warn("Fiber不支持动态修改错误处理器，请在创建应用时设置")
-		}
-	default:
-		// Gin不支持全局错误处理器，需要在每个路由中处理
-	}
+// OnError 实现Server接口
+func (s *UnifiedServer) OnError(mappings ...ErrorMapping) Server {
+	s.errors.Register(mappings...)
 	return s
 }
 
@@ -402,13 +593,15 @@ func (s *UnifiedServer) SetNotFoundHandler(handler ctx.HandlerFunc) Server {
 				return c.Next()
 			})
 		}
+	case "std":
+		if s.stdMux != nil {
+			s.stdMux.notFound = ctx.ToStdHandler(handler)
+		}
 	default:
 		if s.ginEngine != nil {
 			s.ginEngine.NoRoute(func(c *gin.Context) {
-				ctx := ctx.NewGinContext(c)
-				if err := handler(ctx); err != nil {
-					c.Error(err)
-				}
+				uc := ctx.NewGinContext(c)
+				ctx.HandleError(uc, handler(uc))
 			})
 		}
 	}
@@ -420,13 +613,15 @@ func (s *UnifiedServer) SetMethodNotAllowedHandler(handler ctx.HandlerFunc) Serv
 	switch s.config.Engine {
 	case "fiber":
 		// Fiber不直接支持方法不允许处理器，需要自定义
+	case "std":
+		if s.stdMux != nil {
+			s.stdMux.noMethod = ctx.ToStdHandler(handler)
+		}
 	default:
 		if s.ginEngine != nil {
 			s.ginEngine.NoMethod(func(c *gin.Context) {
-				ctx := ctx.NewGinContext(c)
-				if err := handler(ctx); err != nil {
-					c.Error(err)
-				}
+				uc := ctx.NewGinContext(c)
+				ctx.HandleError(uc, handler(uc))
 			})
 		}
 	}
@@ -441,6 +636,8 @@ func (s *UnifiedServer) Start() error {
 	switch s.config.Engine {
 	case "fiber":
 		return s.fiberApp.Listen(s.config.Addr)
+	case "std":
+		return s.stdServer.ListenAndServe()
 	default:
 		return s.ginServer.ListenAndServe()
 	}
@@ -455,6 +652,8 @@ func (s *UnifiedServer) Shutdown(ctx context.Context) error {
 	switch s.config.Engine {
 	case "fiber":
 		err = s.fiberApp.Shutdown()
+	case "std":
+		err = s.stdServer.Shutdown(ctx)
 	default:
 		err = s.ginServer.Shutdown(ctx)
 	}
@@ -475,8 +674,8 @@ type UnifiedServerParams struct {
 	Logger log.Logger
 }
 
-// NewUnifiedHTTPServer 创建统一的HTTP服务器
-func NewUnifiedHTTPServer(p UnifiedServerParams) Server {
+// NewUnifiedHTTPServer 创建统一的HTTP服务器，配置无效时应用启动失败
+func NewUnifiedHTTPServer(p UnifiedServerParams) (Server, error) {
 	// 从配置文件获取HTTP引擎类型
 	engineType := p.Config.HTTP.Engine
 	if engineType == "" {
@@ -485,25 +684,84 @@ func NewUnifiedHTTPServer(p UnifiedServerParams) Server {
 
 	// 创建服务器配置
 	serverConfig := &ServerConfig{
-		Engine:           engineType,
-		Addr:             fmt.Sprintf("%s:%d", p.Config.HTTP.Host, p.Config.HTTP.Port),
-		Debug:            p.Config.App.Mode == "dev",
-		ReadTimeout:      p.Config.HTTP.ReadTimeout,
-		WriteTimeout:     p.Config.HTTP.WriteTimeout,
-		BodyLimit:        p.Config.HTTP.MaxBodySize,
-		EnableCORS:       true, // 默认启用CORS
-		EnableRequestLog: true,
-		EnableRecover:    true,
+		Engine:            engineType,
+		Addr:              fmt.Sprintf("%s:%d", p.Config.HTTP.Host, p.Config.HTTP.Port),
+		Debug:             p.Config.App.Mode == "dev",
+		ReadTimeout:       p.Config.HTTP.ReadTimeout,
+		WriteTimeout:      p.Config.HTTP.WriteTimeout,
+		BodyLimit:         p.Config.HTTP.MaxBodySize,
+		TrustedProxies:    p.Config.HTTP.TrustedProxies,
+		EnableCORS:        p.Config.HTTP.CORS.Enable,
+		EnableCompression: p.Config.HTTP.Compression.Enable,
+		EnableRequestLog:  true,
+		EnableRequestID:   true,
+		EnableRecover:     true,
+		Cookie:            cookieConfig(p.Config.HTTP.Cookie),
+		CORS:              corsConfig(p.Config.HTTP.CORS),
+		Compression:       compressConfig(p.Config.HTTP.Compression),
 	}
 
 	// 创建服务器
-	return NewUnifiedServer(serverConfig, p.Logger)
+	server, err := NewUnifiedServer(serverConfig, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// cookieConfig 将配置文件中的Cookie配置转换为统一上下文的Cookie配置
+func cookieConfig(cfg config.CookieConfig) ctx.CookieConfig {
+	sameSite := http.SameSiteDefaultMode
+	switch strings.ToLower(cfg.SameSite) {
+	case "lax":
+		sameSite = http.SameSiteLaxMode
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+	return ctx.CookieConfig{
+		Path:       cfg.Path,
+		Domain:     cfg.Domain,
+		Secure:     cfg.Secure,
+		HTTPOnly:   cfg.HTTPOnly,
+		SameSite:   sameSite,
+		SignKey:    cfg.SignKey,
+		EncryptKey: cfg.EncryptKey,
+	}
+}
+
+// corsConfig 将配置文件中的跨域配置转换为跨域中间件配置
+func corsConfig(cfg config.CORSConfig) ctx.CORSConfig {
+	return ctx.CORSConfig{
+		AllowOrigins:     cfg.AllowOrigins,
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+}
+
+// compressConfig 将配置文件中的压缩配置转换为压缩中间件配置
+func compressConfig(cfg config.CompressionConfig) ctx.CompressConfig {
+	return ctx.CompressConfig{
+		Level:        cfg.Level,
+		MinSize:      cfg.MinSize,
+		ContentTypes: cfg.ContentTypes,
+		Encodings:    cfg.Encodings,
+	}
 }
 
 // StartUnifiedHTTPServer 启动统一的HTTP服务器
-func StartUnifiedHTTPServer(lc fx.Lifecycle, server Server, logger log.Logger) {
+func StartUnifiedHTTPServer(lc fx.Lifecycle, server Server, h *health.Health, cfg *config.Config, logger log.Logger) {
 	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
+		OnStart: func(context.Context) error {
+			// 声明了权限却没有授权中间件的路由会被任何人访问，拒绝启动
+			if err := ctx.CheckAuthorization(server.Routes()); err != nil {
+				return err
+			}
+
 			// 非阻塞方式启动服务器
 			go func() {
 				if err := server.Start(); err != nil {
@@ -513,6 +771,16 @@ func StartUnifiedHTTPServer(lc fx.Lifecycle, server Server, logger log.Logger) {
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
+			// 就绪检查立即失败，等待负载均衡停止转发流量后再关闭连接
+			h.Shutdown()
+			if delay := cfg.Health.DrainDelay; delay > 0 {
+				logger.Infof("就绪检查已失败，%s后关闭HTTP服务", delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+				}
+			}
+
 			// 创建一个用于关闭的上下文，设置超时时间
 			stopCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			defer cancel()
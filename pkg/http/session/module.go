@@ -0,0 +1,8 @@
+package session
+
+import "go.uber.org/fx"
+
+// Module 会话模块，提供*Manager
+var Module = fx.Options(
+	fx.Provide(NewManager),
+)
@@ -0,0 +1,188 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// keyPrefix 会话的缓存键前缀，会话值保存为哈希表
+const keyPrefix = "session:"
+
+// Options 会话选项
+type Options struct {
+	// Cookie 保存会话ID的Cookie，Cookie的路径、域名等属性使用http.cookie配置
+	Cookie string
+	// Header 读取和返回会话ID的请求头，用于不支持Cookie的客户端
+	Header string
+	// TTL 会话空闲过期时间，默认2小时
+	TTL time.Duration
+	// UserKey 会话中保存用户ID的键，会话包含该值时写入上下文数据user_id，供限流和授权中间件使用，默认user_id
+	UserKey string
+}
+
+// Manager 基于缓存的服务端会话，会话值保存为哈希表，每次请求后重新计算过期时间
+type Manager struct {
+	options Options
+	cache   cache.Cache
+	logger  log.Logger
+}
+
+// NewManager 根据配置创建会话管理器
+func NewManager(config *config.Config, cache cache.Cache, logger log.Logger) *Manager {
+	return New(Options{
+		Cookie: config.Session.Cookie,
+		Header: config.Session.Header,
+		TTL:    config.Session.TTL,
+	}, cache, logger)
+}
+
+// New 创建会话管理器，Cookie和Header都为空时使用名为session_id的Cookie
+func New(options Options, cache cache.Cache, logger log.Logger) *Manager {
+	if options.Cookie == "" && options.Header == "" {
+		options.Cookie = "session_id"
+	}
+	if options.TTL <= 0 {
+		options.TTL = 2 * time.Hour
+	}
+	if options.UserKey == "" {
+		options.UserKey = unified.UserIDKey
+	}
+	return &Manager{
+		options: options,
+		cache:   cache,
+		logger:  logger,
+	}
+}
+
+// Middleware 会话中间件，加载请求携带的会话并保存到上下文数据，处理函数通过c.Session()读写会话，例如:
+//
+//	admin := router.Group("/admin", sessionManager.Middleware())
+//	admin.POST("/login", func(c unified.Context) error {
+//	    // 验证用户名和密码...
+//	    if err := c.Session().Regenerate(); err != nil {
+//	        return err
+//	    }
+//	    return c.Session().Set("user_id", user.ID)
+//	})
+//
+// 会话ID依次从Cookie和请求头读取，会话不存在或已过期时使用新会话，新会话在第一次写入时才保存
+func (m *Manager) Middleware() unified.MiddlewareFunc {
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			// 外层已经加载过会话，例如全局和路由组同时使用
+			if _, ok := c.Get(unified.SessionKey); ok {
+				return next(c)
+			}
+
+			s := &session{manager: m, c: c, values: map[string]string{}}
+			if id := m.readID(c); id != "" {
+				// 文件缓存的会话不存在时返回cache.ErrKeyNotFound，与空会话相同
+				values, err := m.cache.HGetAllCtx(c.Context(), m.key(id))
+				if err != nil && !cache.IsMiss(err) {
+					m.logger.WithContext(c.Context()).WithError(err).Warn("读取会话失败")
+				} else if len(values) > 0 {
+					s.id = id
+					s.values = values
+					if err := m.cache.ExpireCtx(c.Context(), m.key(id), m.options.TTL); err != nil {
+						m.logger.WithContext(c.Context()).WithError(err).Warn("更新会话过期时间失败")
+					}
+					if m.options.Header != "" {
+						c.SetHeader(m.options.Header, id)
+					}
+				}
+			}
+			if userID := s.values[m.options.UserKey]; userID != "" {
+				if _, ok := c.Get(unified.UserIDKey); !ok {
+					c.Set(unified.UserIDKey, userID)
+				}
+			}
+
+			c.Set(unified.SessionKey, s)
+			return next(c)
+		}
+	}
+}
+
+// Destroy 删除指定的会话，例如管理员强制用户下线
+func (m *Manager) Destroy(ctx context.Context, id string) error {
+	if !validID(id) {
+		return nil
+	}
+	_, err := m.cache.DelCtx(ctx, m.key(id))
+	return err
+}
+
+// readID 从Cookie或请求头读取会话ID，格式不合法时返回空字符串
+func (m *Manager) readID(c unified.Context) string {
+	var id string
+	if m.options.Cookie != "" {
+		id, _ = c.Cookie(m.options.Cookie)
+	}
+	if id == "" && m.options.Header != "" {
+		id = c.GetHeader(m.options.Header)
+	}
+	if !validID(id) {
+		return ""
+	}
+	// Fiber返回的值引用请求缓冲区，复制后保存
+	return strings.Clone(id)
+}
+
+// writeID 通过Cookie或响应头返回会话ID，需要在写入响应之前调用
+func (m *Manager) writeID(c unified.Context, id string) {
+	if m.options.Cookie != "" {
+		c.SetCookie(&http.Cookie{
+			Name:     m.options.Cookie,
+			Value:    id,
+			HttpOnly: true,
+		})
+	}
+	if m.options.Header != "" {
+		c.SetHeader(m.options.Header, id)
+	}
+}
+
+// clearID 删除保存会话ID的Cookie
+func (m *Manager) clearID(c unified.Context) {
+	if m.options.Cookie != "" {
+		c.ClearCookie(m.options.Cookie)
+	}
+}
+
+// key 会话的缓存键
+func (m *Manager) key(id string) string {
+	return keyPrefix + id
+}
+
+// newID 生成256位随机会话ID
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成会话ID失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// validID 检查会话ID格式，只接受newID生成的格式，避免客户端传入的值拼接出其他缓存键
+func validID(id string) bool {
+	if len(id) != 43 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		ch := id[i]
+		if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// serve 使用会话中间件处理请求，r为nil时发送不带会话的请求
+func serve(t *testing.T, m *Manager, r *http.Request, handler unified.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	if r == nil {
+		r = httptest.NewRequest(http.MethodGet, "/", nil)
+	}
+	w := httptest.NewRecorder()
+	if err := unified.NewChain(m.Middleware()).Then(handler)(unified.NewStdContext(w, r)); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// withCookie 携带会话Cookie的请求
+func withCookie(id string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "session_id", Value: id})
+	return r
+}
+
+// sessionCookie 返回响应设置的会话Cookie
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == "session_id" {
+			return ck
+		}
+	}
+	t.Fatalf("响应没有设置会话Cookie: %v", w.Header())
+	return nil
+}
+
+// login 创建保存了user_id的会话，返回会话ID
+func login(t *testing.T, m *Manager) string {
+	t.Helper()
+	w := serve(t, m, nil, func(c unified.Context) error {
+		return c.Session().Set("user_id", 42)
+	})
+	return sessionCookie(t, w).Value
+}
+
+func TestValidID(t *testing.T) {
+	id, err := newID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]bool{
+		id:                               true,
+		"":                               false,
+		id[:42]:                          false,
+		id + "A":                         false,
+		strings.Repeat("a", 42) + "=":    false,
+		strings.Repeat("a", 40) + "../":  false,
+		strings.Repeat("a", 39) + ":key": false,
+	}
+	for value, want := range cases {
+		if got := validID(value); got != want {
+			t.Errorf("validID(%q) = %v，期望 %v", value, got, want)
+		}
+	}
+
+	// 格式不合法的会话ID不读取缓存，使用新会话
+	store := cache.NewTestMemory(t)
+	m := New(Options{}, store, log.NewDiscardLogger())
+	if _, err := store.HSet(keyPrefix+"other", "user_id", "1"); err != nil {
+		t.Fatal(err)
+	}
+	serve(t, m, withCookie("other"), func(c unified.Context) error {
+		if c.Session().ID() != "" || len(c.Session().Values()) != 0 {
+			t.Errorf("读取了格式不合法的会话: %q %v", c.Session().ID(), c.Session().Values())
+		}
+		return nil
+	})
+}
+
+func TestCookieTransport(t *testing.T) {
+	store := cache.NewTestMemory(t)
+	m := New(Options{}, store, log.NewDiscardLogger())
+
+	// 只读取会话时不生成会话ID
+	w := serve(t, m, nil, func(c unified.Context) error {
+		if _, ok := c.Session().Get("user_id"); ok || c.Session().ID() != "" {
+			t.Error("新会话包含值")
+		}
+		return nil
+	})
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("只读取会话时设置了Cookie: %v", w.Result().Cookies())
+	}
+
+	w = serve(t, m, nil, func(c unified.Context) error {
+		return c.Session().Set("user_id", 42)
+	})
+	ck := sessionCookie(t, w)
+	if !validID(ck.Value) || !ck.HttpOnly {
+		t.Errorf("会话Cookie %v", ck)
+	}
+	if v, _ := store.HGet(keyPrefix+ck.Value, "user_id"); v != "42" {
+		t.Errorf("缓存中的会话值 %q，期望 42", v)
+	}
+
+	serve(t, m, withCookie(ck.Value), func(c unified.Context) error {
+		if v, _ := c.Session().Get("user_id"); v != "42" || c.Session().ID() != ck.Value {
+			t.Errorf("读取会话 %q %q", c.Session().ID(), v)
+		}
+		if v, _ := c.Get(unified.UserIDKey); v != "42" {
+			t.Errorf("上下文数据user_id %v，期望 42", v)
+		}
+		return nil
+	})
+}
+
+func TestHeaderTransport(t *testing.T) {
+	m := New(Options{Header: "X-Session-ID"}, cache.NewTestMemory(t), log.NewDiscardLogger())
+
+	w := serve(t, m, nil, func(c unified.Context) error {
+		return c.Session().Set("user_id", 42)
+	})
+	id := w.Header().Get("X-Session-ID")
+	if !validID(id) {
+		t.Fatalf("响应头中的会话ID %q", id)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("只使用请求头时设置了Cookie: %v", w.Result().Cookies())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Session-ID", id)
+	w = serve(t, m, r, func(c unified.Context) error {
+		if v, _ := c.Session().Get("user_id"); v != "42" {
+			t.Errorf("通过请求头读取会话 %q，期望 42", v)
+		}
+		return nil
+	})
+	if got := w.Header().Get("X-Session-ID"); got != id {
+		t.Errorf("响应头中的会话ID %q，期望 %q", got, id)
+	}
+
+	// 只使用请求头时忽略Cookie
+	serve(t, m, withCookie(id), func(c unified.Context) error {
+		if c.Session().ID() != "" {
+			t.Error("只使用请求头时读取了Cookie中的会话ID")
+		}
+		return nil
+	})
+}
+
+func TestSlidingExpiry(t *testing.T) {
+	store := cache.NewTestMemory(t)
+	m := New(Options{TTL: time.Hour}, store, log.NewDiscardLogger())
+	id := login(t, m)
+
+	// 每次请求重新计算过期时间
+	if err := store.Expire(keyPrefix+id, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	serve(t, m, withCookie(id), func(c unified.Context) error { return nil })
+	if ttl, err := store.TTL(keyPrefix + id); err != nil || ttl <= time.Minute || ttl > time.Hour {
+		t.Errorf("请求后的过期时间 %s, %v，期望接近1小时", ttl, err)
+	}
+
+	// 过期的会话不再读取
+	if err := store.Expire(keyPrefix+id, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	serve(t, m, withCookie(id), func(c unified.Context) error {
+		if c.Session().ID() != "" {
+			t.Error("读取了已过期的会话")
+		}
+		return nil
+	})
+}
+
+func TestRegenerate(t *testing.T) {
+	store := cache.NewTestMemory(t)
+	m := New(Options{}, store, log.NewDiscardLogger())
+	oldID := login(t, m)
+
+	w := serve(t, m, withCookie(oldID), func(c unified.Context) error {
+		return c.Session().Regenerate()
+	})
+	newID := sessionCookie(t, w).Value
+	if newID == oldID || !validID(newID) {
+		t.Fatalf("新的会话ID %q，旧的会话ID %q", newID, oldID)
+	}
+	if n, _ := store.Exists(keyPrefix + oldID); n != 0 {
+		t.Error("旧会话没有删除")
+	}
+
+	serve(t, m, withCookie(newID), func(c unified.Context) error {
+		if v, _ := c.Session().Get("user_id"); v != "42" {
+			t.Errorf("新会话的值 %q，期望 42", v)
+		}
+		return nil
+	})
+	serve(t, m, withCookie(oldID), func(c unified.Context) error {
+		if c.Session().ID() != "" {
+			t.Error("旧的会话ID仍然可用")
+		}
+		return nil
+	})
+}
+
+func TestFlash(t *testing.T) {
+	m := New(Options{}, cache.NewTestMemory(t), log.NewDiscardLogger())
+	w := serve(t, m, nil, func(c unified.Context) error {
+		if err := c.Session().AddFlash("notice", "已保存"); err != nil {
+			return err
+		}
+		return c.Session().AddFlash("notice", "已通知")
+	})
+	id := sessionCookie(t, w).Value
+
+	serve(t, m, withCookie(id), func(c unified.Context) error {
+		if len(c.Session().Values()) != 0 {
+			t.Errorf("Values包含闪存消息: %v", c.Session().Values())
+		}
+		messages, err := c.Session().Flashes("notice")
+		if err != nil || !slices.Equal(messages, []string{"已保存", "已通知"}) {
+			t.Errorf("闪存消息 %v, %v", messages, err)
+		}
+		return nil
+	})
+
+	// 闪存消息只能读取一次
+	serve(t, m, withCookie(id), func(c unified.Context) error {
+		if messages, err := c.Session().Flashes("notice"); err != nil || messages != nil {
+			t.Errorf("第二次读取闪存消息 %v, %v", messages, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,203 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// flashPrefix 闪存消息在会话哈希表中的字段前缀，消息列表保存为JSON数组
+const flashPrefix = "_flash:"
+
+// session 请求的会话，实现unified.Session，修改立即写入缓存
+type session struct {
+	manager *Manager
+	c       unified.Context
+	mu      sync.Mutex
+	id      string
+	values  map[string]string
+}
+
+// ID 实现unified.Session接口
+func (s *session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// Get 实现unified.Session接口
+func (s *session) Get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// Set 实现unified.Session接口
+func (s *session) Set(key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.set(key, toString(value))
+}
+
+// Delete 实现unified.Session接口
+func (s *session) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id != "" && len(keys) > 0 {
+		if _, err := s.manager.cache.HDelCtx(s.c.Context(), s.manager.key(s.id), keys...); err != nil && !cache.IsMiss(err) {
+			return err
+		}
+	}
+	for _, key := range keys {
+		delete(s.values, key)
+	}
+	return nil
+}
+
+// Values 实现unified.Session接口
+func (s *session) Values() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make(map[string]string, len(s.values))
+	for k, v := range s.values {
+		if !strings.HasPrefix(k, flashPrefix) {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// Regenerate 实现unified.Session接口，会话值复制到新的会话ID后删除旧会话
+func (s *session) Regenerate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := s.c.Context()
+	oldID := s.id
+	if len(s.values) == 0 {
+		// 没有会话值时下次写入再生成会话ID
+		s.id = ""
+	} else {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		if err := s.save(id, s.values); err != nil {
+			return err
+		}
+		s.id = id
+		s.manager.writeID(s.c, id)
+	}
+	if oldID != "" {
+		if _, err := s.manager.cache.DelCtx(ctx, s.manager.key(oldID)); err != nil {
+			s.manager.logger.WithContext(ctx).WithError(err).Warn("删除旧会话失败")
+		}
+	}
+	return nil
+}
+
+// Destroy 实现unified.Session接口
+func (s *session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id != "" {
+		if _, err := s.manager.cache.DelCtx(s.c.Context(), s.manager.key(s.id)); err != nil {
+			return err
+		}
+		s.manager.clearID(s.c)
+	}
+	s.id = ""
+	s.values = map[string]string{}
+	return nil
+}
+
+// AddFlash 实现unified.Session接口
+func (s *session) AddFlash(key, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := append(s.flashes(key), message)
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+	return s.set(flashPrefix+key, string(data))
+}
+
+// Flashes 实现unified.Session接口
+func (s *session) Flashes(key string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	field := flashPrefix + key
+	if _, ok := s.values[field]; !ok {
+		return nil, nil
+	}
+	messages := s.flashes(key)
+	if _, err := s.manager.cache.HDelCtx(s.c.Context(), s.manager.key(s.id), field); err != nil && !cache.IsMiss(err) {
+		return nil, err
+	}
+	delete(s.values, field)
+	return messages, nil
+}
+
+// flashes 解析闪存消息列表，调用方需要持有锁
+func (s *session) flashes(key string) []string {
+	var messages []string
+	if data, ok := s.values[flashPrefix+key]; ok {
+		_ = json.Unmarshal([]byte(data), &messages)
+	}
+	return messages
+}
+
+// set 写入一个会话值，新会话第一次写入时生成会话ID，调用方需要持有锁
+func (s *session) set(key, value string) error {
+	// Fiber的请求参数引用请求缓冲区，内存缓存直接保存字符串，复制后才能在请求结束后继续使用
+	key, value = strings.Clone(key), strings.Clone(value)
+	if s.id == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		if err := s.save(id, map[string]string{key: value}); err != nil {
+			return err
+		}
+		s.id = id
+		s.manager.writeID(s.c, id)
+	} else if err := s.save(s.id, map[string]string{key: value}); err != nil {
+		return err
+	}
+	s.values[key] = value
+	return nil
+}
+
+// save 将会话值写入哈希表并重新计算过期时间
+func (s *session) save(id string, values map[string]string) error {
+	ctx := s.c.Context()
+	key := s.manager.key(id)
+	args := make([]interface{}, 0, len(values)*2)
+	for _, k := range slices.Sorted(maps.Keys(values)) {
+		args = append(args, k, values[k])
+	}
+	if _, err := s.manager.cache.HSetCtx(ctx, key, args...); err != nil {
+		return err
+	}
+	return s.manager.cache.ExpireCtx(ctx, key, s.manager.options.TTL)
+}
+
+// toString 将会话值转换为字符串，与Redis保存的格式一致
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
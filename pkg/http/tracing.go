@@ -0,0 +1,15 @@
+package http
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RegisterTracing 启用链路追踪时注册请求span中间件，需要在挂载业务路由之前执行
+func RegisterTracing(server Server, provider trace.TracerProvider, cfg *config.Config) {
+	if !cfg.Tracing.Enable {
+		return
+	}
+	server.Use(tracing.Middleware(provider))
+}
@@ -0,0 +1,270 @@
+package unified
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	durationType    = reflect.TypeOf(time.Duration(0))
+	timeType        = reflect.TypeOf(time.Time{})
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindValues 按照tag将键值对绑定到结构体字段
+// values 为普通参数，files 为上传文件（可以为nil）
+func bindValues(obj interface{}, tag string, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("绑定目标必须是非空指针")
+	}
+	rv = rv.Elem()
+
+	// 支持直接绑定到 map[string]string 和 map[string][]string
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+		return bindMap(rv, values)
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("不支持绑定到类型 %s", rv.Type())
+	}
+	return bindStruct(rv, tag, values, files)
+}
+
+// bindMap 绑定到map
+func bindMap(rv reflect.Value, values map[string][]string) error {
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMap(rv.Type()))
+	}
+	elemType := rv.Type().Elem()
+	for k, v := range values {
+		switch {
+		case elemType.Kind() == reflect.String:
+			if len(v) > 0 {
+				rv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
+			}
+		case elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String:
+			rv.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v))
+		default:
+			return fmt.Errorf("不支持绑定到类型 %s", rv.Type())
+		}
+	}
+	return nil
+}
+
+// bindStruct 绑定到结构体
+func bindStruct(rv reflect.Value, tag string, values map[string][]string, files map[string][]*multipart.FileHeader) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, ok := fieldName(field, tag)
+		if !ok {
+			continue
+		}
+
+		// 匿名嵌入结构体递归绑定
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				continue
+			}
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(ft))
+				}
+				fv = fv.Elem()
+			}
+			if err := bindStruct(fv, tag, values, files); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		// 上传文件
+		if field.Type == fileHeaderType {
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem() == fileHeaderType {
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+
+		// 嵌套的具名结构体（非特殊类型）使用相同的值递归绑定
+		if isNestedStruct(field.Type) {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := bindStruct(fv, tag, values, files); err != nil {
+				return err
+			}
+			continue
+		}
+
+		vals, exists := values[name]
+		if !exists || len(vals) == 0 {
+			continue
+		}
+		if err := setField(fv, vals); err != nil {
+			return fmt.Errorf("字段 %s 绑定失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldName 解析字段在tag中声明的名称，返回false表示忽略该字段
+func fieldName(field reflect.StructField, tag string) (string, bool) {
+	value, ok := field.Tag.Lookup(tag)
+	if !ok {
+		return "", true
+	}
+	name := strings.Split(value, ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	return name, true
+}
+
+// isNestedStruct 判断是否是需要递归绑定的结构体
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshaler)
+}
+
+// setField 将字符串值设置到字段
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), vals)
+	}
+
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshaler) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(vals[0]))
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, v := range vals {
+			if err := setField(slice.Index(i), []string{v}); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < fv.Len() && i < len(vals); i++ {
+			if err := setField(fv.Index(i), []string{vals[i]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return setScalar(fv, vals[0])
+}
+
+// setScalar 设置标量值
+func setScalar(fv reflect.Value, val string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	if fv.Type() == timeType {
+		if val == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		if val == "" {
+			val = "false"
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val == "" {
+			val = "0"
+		}
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if val == "" {
+			val = "0"
+		}
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if val == "" {
+			val = "0"
+		}
+		f, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Interface:
+		fv.Set(reflect.ValueOf(val))
+	default:
+		return fmt.Errorf("不支持的字段类型 %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package unified
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BufferedResponse 缓冲的响应
+type BufferedResponse struct {
+	// StatusCode 响应状态码
+	StatusCode int
+	// Header 响应头，包括处理函数之前设置的响应头
+	Header http.Header
+	// Body 响应体
+	Body []byte
+}
+
+// BufferResponse 执行next并缓冲其输出，用于需要在发送前检查或修改响应的中间件，例如:
+//
+//	resp, err := unified.BufferResponse(c, next)
+//	if err != nil || resp == nil {
+//		return err
+//	}
+//	resp.Body = transform(resp.Body)
+//	return unified.WriteResponse(c, resp)
+//
+// 返回的响应尚未发送，调用方需要通过WriteResponse输出。以下情况返回nil，已输出的内容直接发送:
+// next返回错误、没有输出响应，以及流式响应（Stream、SSE、WebSocket、调用Flush或Hijack）
+func BufferResponse(c Context, next HandlerFunc) (*BufferedResponse, error) {
+	switch v := c.(type) {
+	case *GinContext:
+		origin := v.ctx.Writer
+		buffer := newResponseBuffer(origin)
+		writer := &ginBufferWriter{StdResponseWriter: newStdResponseWriter(buffer), origin: origin}
+		v.ctx.Writer = writer
+		err := next(c)
+		v.ctx.Writer = origin
+		return buffer.result(writer.StdResponseWriter, err)
+	case *StdContext:
+		origin := v.writer
+		buffer := newResponseBuffer(origin)
+		writer := newStdResponseWriter(buffer)
+		v.writer = writer
+		err := next(c)
+		v.writer = origin
+		return buffer.result(writer, err)
+	case *FiberContext:
+		return bufferFiberResponse(v, next)
+	}
+	return nil, next(c)
+}
+
+// WriteResponse 输出响应，响应头按键覆盖当前的响应头，Content-Length按响应体重新计算
+func WriteResponse(c Context, resp *BufferedResponse) error {
+	body := resp.Body
+	if !bodyAllowed(resp.StatusCode) {
+		body = nil
+	}
+
+	switch v := c.(type) {
+	case *GinContext:
+		return writeHTTPResponse(v.ctx.Writer, resp, body)
+	case *StdContext:
+		return writeHTTPResponse(v.writer, resp, body)
+	case *FiberContext:
+		// 先同步通过GetResponse修改的响应头，之后重新创建适配器，避免其中的响应头过期
+		if w, ok := v.ctx.Locals(responseWriterKey).(*fiberResponseWriter); ok {
+			w.syncHeader()
+			v.ctx.Locals(responseWriterKey, nil)
+		}
+		header := &v.ctx.Response().Header
+		for key, values := range resp.Header {
+			if http.CanonicalHeaderKey(key) == "Content-Length" {
+				continue
+			}
+			header.Del(key)
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+		v.ctx.Status(resp.StatusCode)
+		v.ctx.Response().SetBody(body)
+		return nil
+	}
+	return c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
+}
+
+// writeHTTPResponse 基于http.ResponseWriter输出响应（gin和标准库引擎）
+func writeHTTPResponse(w interface {
+	http.ResponseWriter
+	WriteHeaderNow()
+}, resp *BufferedResponse, body []byte) error {
+	header := w.Header()
+	for key, values := range resp.Header {
+		header[key] = slices.Clone(values)
+	}
+	header.Del("Content-Length")
+	w.WriteHeader(resp.StatusCode)
+	if len(body) == 0 {
+		w.WriteHeaderNow()
+		return nil
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// bodyAllowed 状态码是否允许响应体
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// responseBuffer 缓冲写入的状态码和响应体，Flush或Hijack后切换为直接写入原始响应
+// 缓冲期间的响应头直接修改原始响应的响应头
+type responseBuffer struct {
+	origin      http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	passthrough bool
+}
+
+// newResponseBuffer 创建响应缓冲
+func newResponseBuffer(origin http.ResponseWriter) *responseBuffer {
+	return &responseBuffer{origin: origin, status: http.StatusOK}
+}
+
+// Header 实现http.ResponseWriter接口
+func (b *responseBuffer) Header() http.Header {
+	return b.origin.Header()
+}
+
+// WriteHeader 实现http.ResponseWriter接口
+func (b *responseBuffer) WriteHeader(code int) {
+	if b.passthrough {
+		b.origin.WriteHeader(code)
+		return
+	}
+	if !b.wroteHeader {
+		b.status = code
+		b.wroteHeader = true
+	}
+}
+
+// Write 实现http.ResponseWriter接口
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.passthrough {
+		return b.origin.Write(p)
+	}
+	if !b.wroteHeader {
+		b.WriteHeader(http.StatusOK)
+	}
+	return b.body.Write(p)
+}
+
+// Flush 实现http.Flusher接口，输出已缓冲的内容，之后的内容直接写入原始响应
+func (b *responseBuffer) Flush() {
+	b.release()
+	_ = http.NewResponseController(b.origin).Flush()
+}
+
+// Hijack 实现http.Hijacker接口
+func (b *responseBuffer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	b.release()
+	return http.NewResponseController(b.origin).Hijack()
+}
+
+// Unwrap 返回原始的ResponseWriter，供http.ResponseController使用
+func (b *responseBuffer) Unwrap() http.ResponseWriter {
+	return b.origin
+}
+
+// release 输出已缓冲的内容并切换为直接写入
+func (b *responseBuffer) release() {
+	if b.passthrough {
+		return
+	}
+	b.passthrough = true
+	if !b.wroteHeader {
+		return
+	}
+	b.origin.WriteHeader(b.status)
+	if b.body.Len() > 0 {
+		_, _ = b.origin.Write(b.body.Bytes())
+	} else if w, ok := b.origin.(interface{ WriteHeaderNow() }); ok {
+		w.WriteHeaderNow()
+	}
+	b.body.Reset()
+}
+
+// result 处理函数返回后生成缓冲的响应，writer为缓冲期间使用的响应包装器
+func (b *responseBuffer) result(writer *StdResponseWriter, err error) (*BufferedResponse, error) {
+	if b.passthrough {
+		return nil, err
+	}
+	if !writer.Written() {
+		// 只设置了状态码而没有输出响应时，状态码交给外层
+		if writer.Status() != http.StatusOK {
+			b.origin.WriteHeader(writer.Status())
+		}
+		return nil, err
+	}
+	if err != nil {
+		b.release()
+		return nil, err
+	}
+	return &BufferedResponse{
+		StatusCode: b.status,
+		Header:     b.origin.Header().Clone(),
+		Body:       b.body.Bytes(),
+	}, nil
+}
+
+// streamResponse 流式响应不经过缓冲，所有层级的缓冲都切换为直接写入
+func streamResponse(w http.ResponseWriter) {
+	for w != nil {
+		if b, ok := w.(*responseBuffer); ok {
+			b.release()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
+
+// ginBufferWriter 缓冲期间代替Gin的ResponseWriter
+type ginBufferWriter struct {
+	*StdResponseWriter
+	origin gin.ResponseWriter
+}
+
+// CloseNotify 实现http.CloseNotifier接口
+func (w *ginBufferWriter) CloseNotify() <-chan bool {
+	return w.origin.CloseNotify()
+}
+
+// Pusher 实现gin.ResponseWriter接口
+func (w *ginBufferWriter) Pusher() http.Pusher {
+	return w.origin.Pusher()
+}
+
+// 用于标记流式响应的本地变量名
+const streamedKey = "_streamed"
+
+// bufferFiberResponse 缓冲fiber响应，fasthttp的响应体本身就在处理函数返回后才发送，这里只需取出并清空
+func bufferFiberResponse(c *FiberContext, next HandlerFunc) (*BufferedResponse, error) {
+	if err := next(c); err != nil {
+		return nil, err
+	}
+	resp := c.ctx.Response()
+	if resp.IsBodyStream() || c.ctx.Context().Hijacked() || c.ctx.Locals(streamedKey) != nil ||
+		resp.StatusCode() == http.StatusSwitchingProtocols {
+		return nil, nil
+	}
+	if len(resp.Body()) == 0 && resp.StatusCode() == http.StatusOK {
+		return nil, nil
+	}
+
+	if w, ok := c.ctx.Locals(responseWriterKey).(*fiberResponseWriter); ok {
+		w.syncHeader()
+	}
+	buffered := &BufferedResponse{
+		StatusCode: resp.StatusCode(),
+		Header:     newFiberResponseWriter(c.ctx).header,
+		Body:       bytes.Clone(resp.Body()),
+	}
+	// 未设置Content-Type时fasthttp发送默认值，例如String的响应
+	if buffered.Header.Get("Content-Type") == "" && len(buffered.Body) > 0 {
+		buffered.Header.Set("Content-Type", string(resp.Header.ContentType()))
+	}
+	resp.ResetBody()
+	return buffered, nil
+}
@@ -0,0 +1,95 @@
+package unified
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+var (
+	proxyMu        sync.RWMutex
+	trustedProxies []netip.Prefix
+)
+
+// SetTrustedProxies 设置可信代理的IP或CIDR，例如"10.0.0.0/8"、"127.0.0.1"，
+// 只有连接来自可信代理时ClientIP才读取X-Forwarded-For和X-Real-IP；默认不信任任何代理，ClientIP返回连接的远程地址
+func SetTrustedProxies(proxies []string) error {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if strings.Contains(proxy, "/") {
+			prefix, err := netip.ParsePrefix(proxy)
+			if err != nil {
+				return fmt.Errorf("无效的可信代理: %s", proxy)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return fmt.Errorf("无效的可信代理: %s", proxy)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	proxyMu.Lock()
+	defer proxyMu.Unlock()
+	trustedProxies = prefixes
+	return nil
+}
+
+// isTrustedProxy 判断地址是否是可信代理
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	proxyMu.RLock()
+	defer proxyMu.RUnlock()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 获取客户端IP，三种引擎使用相同的规则：连接来自可信代理时，从右向左取X-Forwarded-For中第一个不是可信代理的地址，
+// 没有X-Forwarded-For时使用X-Real-IP；连接不是来自可信代理时使用连接的远程地址，客户端自己发送的转发请求头不会生效
+func clientIP(forwardedFor, realIP, remoteAddr string) string {
+	remote := strings.TrimSpace(remoteAddr)
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+
+	if forwardedFor != "" {
+		// 每个代理把连接的远程地址追加在末尾，靠左的地址可能由客户端伪造
+		addrs := strings.Split(forwardedFor, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(addrs[i])
+			if _, err := netip.ParseAddr(ip); err != nil {
+				break
+			}
+			if i == 0 || !isTrustedProxy(ip) {
+				return ip
+			}
+		}
+	}
+	if ip := strings.TrimSpace(realIP); ip != "" {
+		if _, err := netip.ParseAddr(ip); err == nil {
+			return ip
+		}
+	}
+	return remote
+}
@@ -0,0 +1,302 @@
+package unified
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// 常用的MIME类型
+const (
+	MIMEJSON          = "application/json"
+	MIMEXML           = "application/xml"
+	MIMEXML2          = "text/xml"
+	MIMEForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartForm = "multipart/form-data"
+	MIMEMsgPack       = "application/msgpack"
+	MIMEMsgPack2      = "application/x-msgpack"
+	MIMEProtobuf      = "application/x-protobuf"
+	MIMEProtobuf2     = "application/protobuf"
+)
+
+// jsonContentType JSON响应的Content-Type，各引擎一致
+const jsonContentType = MIMEJSON + "; charset=utf-8"
+
+// ErrUnsupportedMediaType 请求体格式不支持
+var ErrUnsupportedMediaType = errors.New("不支持的请求体格式")
+
+// Codec 请求体解析和响应渲染使用的编解码器
+type Codec interface {
+	// ContentType 响应的Content-Type
+	ContentType() string
+	// Marshal 编码响应数据
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal 解码请求体
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	codecMu sync.RWMutex
+	// codecs MIME类型到编解码器的映射
+	codecs = map[string]Codec{}
+	// offers 内容协商时可选的MIME类型，按注册顺序排列，第一个为默认格式
+	offers []string
+)
+
+func init() {
+	RegisterCodec(jsonCodec{}, MIMEJSON)
+	RegisterCodec(xmlCodec{}, MIMEXML, MIMEXML2)
+	RegisterCodec(msgpackCodec{}, MIMEMsgPack, MIMEMsgPack2)
+	RegisterCodec(protobufCodec{}, MIMEProtobuf, MIMEProtobuf2)
+}
+
+// RegisterCodec 注册编解码器，mimeTypes为空时使用编解码器的ContentType
+// 已注册的MIME类型会被覆盖，例如注册YAML:
+//
+//	unified.RegisterCodec(yamlCodec{}, "application/yaml", "application/x-yaml")
+func RegisterCodec(c Codec, mimeTypes ...string) {
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{mediaType(c.ContentType())}
+	}
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	for _, mt := range mimeTypes {
+		mt = mediaType(mt)
+		if _, ok := codecs[mt]; !ok {
+			offers = append(offers, mt)
+		}
+		codecs[mt] = c
+	}
+}
+
+// LookupCodec 根据MIME类型获取编解码器，支持application/vnd.api+json这类结构化后缀
+func LookupCodec(contentType string) (Codec, bool) {
+	mt := mediaType(contentType)
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+	if c, ok := codecs[mt]; ok {
+		return c, true
+	}
+	if i := strings.LastIndex(mt, "+"); i >= 0 {
+		switch mt[i+1:] {
+		case "json":
+			return codecs[MIMEJSON], true
+		case "xml":
+			return codecs[MIMEXML], true
+		}
+	}
+	return nil, false
+}
+
+// NegotiateCodec 根据Accept请求头选择响应的编解码器
+// 只在优先级最高的一组类型中选择，都不支持时（例如浏览器的text/html）使用默认的JSON
+func NegotiateCodec(accept string) Codec {
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+
+	ranges := parseAccept(accept)
+	for i := 0; i < len(ranges) && ranges[i].q == ranges[0].q; i++ {
+		if mt, ok := matchOffer(ranges[i].mediaType); ok {
+			return codecs[mt]
+		}
+	}
+	return codecs[MIMEJSON]
+}
+
+// acceptRange Accept请求头中的一项
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept 解析Accept请求头，按q值从高到低排序，忽略q=0的项
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, acceptRange{mediaType: mt, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// matchOffer 获取与媒体范围匹配的第一个可选类型，调用方需要持有读锁
+func matchOffer(mediaRange string) (string, bool) {
+	if mediaRange == "*/*" {
+		return MIMEJSON, true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		prefix := strings.TrimSuffix(mediaRange, "*")
+		for _, mt := range offers {
+			if strings.HasPrefix(mt, prefix) {
+				return mt, true
+			}
+		}
+		return "", false
+	}
+	_, ok := codecs[mediaRange]
+	return mediaRange, ok
+}
+
+// mediaType 去掉Content-Type中的参数并转为小写
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// bind 按Content-Type解析请求体，没有请求体时绑定查询参数
+// 表单和JSON使用引擎自身的解析方式，其他格式读取原始请求体后交给编解码器
+func bind(c Context, obj interface{}, body func() ([]byte, error)) error {
+	if !hasRequestBody(c) {
+		return c.BindQuery(obj)
+	}
+
+	contentType := mediaType(c.GetHeader("Content-Type"))
+	switch contentType {
+	case MIMEForm, MIMEMultipartForm:
+		return c.BindForm(obj)
+	case MIMEJSON:
+		return c.BindJSON(obj)
+	}
+
+	cd, ok := LookupCodec(contentType)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+	data, err := body()
+	if err != nil {
+		return err
+	}
+	return cd.Unmarshal(data, obj)
+}
+
+// negotiate 按Accept请求头选择格式输出响应
+func negotiate(c Context, code int, data interface{}) error {
+	addVary(c, "Accept")
+	cd := NegotiateCodec(c.GetHeader("Accept"))
+	if _, ok := cd.(jsonCodec); ok {
+		return c.JSON(code, data)
+	}
+	return render(c, code, cd, data)
+}
+
+// render 使用编解码器输出响应
+func render(c Context, code int, cd Codec, data interface{}) error {
+	body, err := cd.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.Data(code, cd.ContentType(), body)
+}
+
+// jsonCodec JSON编解码器
+type jsonCodec struct{}
+
+// ContentType 实现Codec接口
+func (jsonCodec) ContentType() string {
+	return jsonContentType
+}
+
+// Marshal 实现Codec接口
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 实现Codec接口
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// msgpackHandle MessagePack编码配置，字段名与JSON一致
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := new(codec.MsgpackHandle)
+	h.WriteExt = true
+	h.RawToString = true
+	h.TypeInfos = codec.NewTypeInfos([]string{"msgpack", "codec", "json"})
+	return h
+}()
+
+// msgpackCodec MessagePack编解码器
+type msgpackCodec struct{}
+
+// ContentType 实现Codec接口
+func (msgpackCodec) ContentType() string {
+	return MIMEMsgPack
+}
+
+// Marshal 实现Codec接口
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	var data []byte
+	err := codec.NewEncoderBytes(&data, msgpackHandle).Encode(v)
+	return data, err
+}
+
+// Unmarshal 实现Codec接口
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return codec.NewDecoderBytes(data, msgpackHandle).Decode(v)
+}
+
+// protobufCodec Protocol Buffers编解码器
+type protobufCodec struct{}
+
+// ContentType 实现Codec接口
+func (protobufCodec) ContentType() string {
+	return MIMEProtobuf
+}
+
+// Marshal 实现Codec接口
+// proto.Message直接编码，其他类型按照JSON结构转换为google.protobuf.Struct（对象）或google.protobuf.Value
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Marshal(m)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	value, err := structpb.NewValue(generic)
+	if err != nil {
+		return nil, err
+	}
+	if s := value.GetStructValue(); s != nil {
+		return proto.Marshal(s)
+	}
+	return proto.Marshal(value)
+}
+
+// Unmarshal 实现Codec接口，目标必须实现proto.Message
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T 没有实现proto.Message，无法解析protobuf请求体", v)
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,257 @@
+package unified
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// 支持的压缩编码
+const (
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// CompressConfig 压缩中间件配置
+type CompressConfig struct {
+	// Level 压缩级别，gzip和deflate为1-9，brotli为0-11，超出范围时取最接近的级别，0使用各自的默认级别
+	Level int
+	// MinSize 压缩的最小响应体大小（字节），默认1024
+	MinSize int
+	// ContentTypes 压缩的内容类型，"text/*"匹配所有text类型，默认为文本、JSON、JavaScript、XML和SVG
+	ContentTypes []string
+	// Encodings 支持的编码，按优先顺序排列，客户端对多个编码的权重相同时使用靠前的编码，默认br、gzip、deflate
+	Encodings []string
+}
+
+// defaultCompressTypes 默认压缩的内容类型
+var defaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+}
+
+// defaultEncodings 默认支持的编码
+var defaultEncodings = []string{EncodingBrotli, EncodingGzip, EncodingDeflate}
+
+// Compress 响应压缩中间件，根据Accept-Encoding使用brotli、gzip或deflate压缩响应体，例如:
+//
+//	router.Group("/api", unified.Compress(unified.CompressConfig{MinSize: 512}))
+//
+// 路由上有多个压缩中间件时只使用最后注册的一个，压缩中间件在跨域中间件之后最先执行，
+// 缓存等中间件看到的都是未压缩的响应；小于MinSize、内容类型不在ContentTypes中、
+// 已经设置了Content-Encoding或Cache-Control: no-transform的响应，以及流式响应（Stream、SSE、WebSocket）不压缩
+func Compress(config ...CompressConfig) MiddlewareFunc {
+	cfg := CompressConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	return newCompressor(cfg).middleware
+}
+
+// compressMiddlewarePC compressor.middleware方法值的函数地址，路由器据此识别压缩中间件
+var compressMiddlewarePC = funcPC((*compressor)(nil).middleware)
+
+// splitCompress 从中间件列表中取出最后一个压缩中间件，返回压缩中间件和其余中间件
+func splitCompress(middlewares []MiddlewareFunc) (MiddlewareFunc, []MiddlewareFunc) {
+	var compress MiddlewareFunc
+	rest := make([]MiddlewareFunc, 0, len(middlewares))
+	for _, m := range middlewares {
+		if funcPC(m) == compressMiddlewarePC {
+			compress = m
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return compress, rest
+}
+
+// encodingWriter 可以重复使用的压缩写入器
+type encodingWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// compressor 预处理后的压缩配置
+type compressor struct {
+	minSize   int
+	types     []string
+	encodings []string
+	pools     map[string]*sync.Pool
+}
+
+// newCompressor 创建压缩器，每种编码使用独立的写入器池
+func newCompressor(cfg CompressConfig) *compressor {
+	cp := &compressor{
+		minSize:   cfg.MinSize,
+		types:     cfg.ContentTypes,
+		encodings: cfg.Encodings,
+		pools:     make(map[string]*sync.Pool),
+	}
+	if cp.minSize <= 0 {
+		cp.minSize = 1024
+	}
+	if len(cp.types) == 0 {
+		cp.types = defaultCompressTypes
+	}
+	if len(cp.encodings) == 0 {
+		cp.encodings = defaultEncodings
+	}
+
+	gzipLevel, brotliLevel := gzip.DefaultCompression, brotli.DefaultCompression
+	if cfg.Level != 0 {
+		gzipLevel = min(max(cfg.Level, gzip.BestSpeed), gzip.BestCompression)
+		brotliLevel = min(max(cfg.Level, brotli.BestSpeed), brotli.BestCompression)
+	}
+	encodings := make([]string, 0, len(cp.encodings))
+	for _, encoding := range cp.encodings {
+		var pool *sync.Pool
+		switch encoding = strings.ToLower(encoding); encoding {
+		case EncodingBrotli:
+			pool = &sync.Pool{New: func() interface{} {
+				return brotli.NewWriterLevel(nil, brotliLevel)
+			}}
+		case EncodingGzip:
+			pool = &sync.Pool{New: func() interface{} {
+				w, _ := gzip.NewWriterLevel(nil, gzipLevel)
+				return w
+			}}
+		case EncodingDeflate:
+			pool = &sync.Pool{New: func() interface{} {
+				w, _ := flate.NewWriter(nil, gzipLevel)
+				return w
+			}}
+		default:
+			continue
+		}
+		cp.pools[encoding] = pool
+		encodings = append(encodings, encoding)
+	}
+	cp.encodings = encodings
+	return cp
+}
+
+// middleware 压缩中间件
+func (cp *compressor) middleware(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		return cp.handle(c, next)
+	}
+}
+
+// handle 处理请求，客户端不接受任何支持的编码时不缓冲响应
+func (cp *compressor) handle(c Context, next HandlerFunc) error {
+	addVary(c, "Accept-Encoding")
+	encoding := cp.negotiate(c.GetHeader("Accept-Encoding"))
+	if encoding == "" || c.Method() == http.MethodHead {
+		return next(c)
+	}
+
+	resp, err := BufferResponse(c, next)
+	if err != nil || resp == nil {
+		return err
+	}
+	if cp.compressible(resp) {
+		body, err := cp.compress(encoding, resp.Body)
+		if err != nil {
+			return err
+		}
+		resp.Body = body
+		resp.Header.Set("Content-Encoding", encoding)
+		// 压缩后的响应体与原始响应体不再逐字节相同，强ETag改为弱ETag
+		if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			resp.Header.Set("ETag", "W/"+etag)
+		}
+	}
+	return WriteResponse(c, resp)
+}
+
+// negotiate 根据Accept-Encoding选择编码，权重最高的编码优先，权重相同时按配置的顺序
+func (cp *compressor) negotiate(accept string) string {
+	if accept == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		weights[name] = q
+	}
+
+	var (
+		best       string
+		bestWeight float64
+	)
+	for _, encoding := range cp.encodings {
+		q, ok := weights[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestWeight {
+			best, bestWeight = encoding, q
+		}
+	}
+	return best
+}
+
+// compressible 判断响应是否需要压缩
+func (cp *compressor) compressible(resp *BufferedResponse) bool {
+	if len(resp.Body) < cp.minSize || !bodyAllowed(resp.StatusCode) || resp.StatusCode == http.StatusPartialContent {
+		return false
+	}
+	if resp.Header.Get("Content-Encoding") != "" || headerContains(resp.Header.Values("Cache-Control"), "no-transform") {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, t := range cp.types {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// compress 使用池中的写入器压缩响应体
+func (cp *compressor) compress(encoding string, body []byte) ([]byte, error) {
+	pool := cp.pools[encoding]
+	w := pool.Get().(encodingWriter)
+	defer pool.Put(w)
+
+	var buf bytes.Buffer
+	w.Reset(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
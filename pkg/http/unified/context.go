@@ -1,6 +1,8 @@
 package unified
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -26,7 +28,8 @@ type Context interface {
 	ParamInt(key string) (int, error)
 	ParamUint(key string) (uint, error)
 
-	// 请求体解析
+	// 请求体解析，Bind按Content-Type选择解析方式，没有请求体时绑定查询参数
+	Bind(obj interface{}) error
 	BindJSON(obj interface{}) error
 	BindQuery(obj interface{}) error
 	BindForm(obj interface{}) error
@@ -38,15 +41,35 @@ type Context interface {
 	JSON(code int, obj interface{}) error
 	String(code int, format string, values ...interface{}) error
 	HTML(code int, html string) error
+	XML(code int, obj interface{}) error
+	Data(code int, contentType string, data []byte) error
+	Negotiate(code int, data interface{}) error
 	Redirect(code int, url string) error
 	File(filepath string) error
 	Stream(contentType string, r io.Reader) error
+	SSE(handler SSEHandler) error
+
+	// Cookie，签名和加密Cookie的密钥通过SetCookieConfig设置
+	Cookie(name string) (string, error)
+	SetCookie(cookie *http.Cookie)
+	ClearCookie(name string)
+	SignedCookie(name string) (string, error)
+	SetSignedCookie(cookie *http.Cookie) error
+	EncryptedCookie(name string) (string, error)
+	SetEncryptedCookie(cookie *http.Cookie) error
 
 	// 上下文数据
 	Set(key string, value interface{})
 	Get(key string) (interface{}, bool)
 	MustGet(key string) interface{}
 
+	// 服务端会话，需要使用会话中间件，未使用时读取返回空值，修改返回ErrSessionDisabled
+	Session() Session
+
+	// 请求范围的context.Context，携带请求ID等值，可以传给数据库和缓存调用
+	Context() context.Context
+	SetContext(ctx context.Context)
+
 	// 框架原生上下文
 	GinContext() interface{}
 	FiberContext() interface{}
@@ -65,3 +88,11 @@ type Context interface {
 	AbortWithStatus(code int)
 	AbortWithJSON(code int, obj interface{}) error
 }
+
+// checkRedirectCode 检查重定向状态码
+func checkRedirectCode(code int) error {
+	if (code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect) && code != http.StatusCreated {
+		return fmt.Errorf("无法使用状态码 %d 重定向", code)
+	}
+	return nil
+}
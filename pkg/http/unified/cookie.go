@@ -0,0 +1,355 @@
+package unified
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidCookie Cookie签名校验或解密失败
+	ErrInvalidCookie = errors.New("Cookie无效或已被篡改")
+	// ErrCookieKeyMissing 未配置签名或加密密钥
+	ErrCookieKeyMissing = errors.New("未配置Cookie密钥")
+)
+
+// CookieConfig Cookie配置
+// Path、Domain、SameSite在SetCookie传入的Cookie未设置时使用，Secure、HTTPOnly为true时强制开启
+type CookieConfig struct {
+	Path     string
+	Domain   string
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
+	// SignKey 签名Cookie使用的HMAC-SHA256密钥
+	SignKey string
+	// EncryptKey 加密Cookie使用的AES-GCM密钥，长度为16、24或32字节时直接使用，否则使用其SHA-256摘要
+	EncryptKey string
+}
+
+var (
+	cookieMu     sync.RWMutex
+	cookieConfig = CookieConfig{Path: "/"}
+	cookieAEAD   cipher.AEAD
+)
+
+// SetCookieConfig 设置Cookie配置
+func SetCookieConfig(config CookieConfig) error {
+	var aead cipher.AEAD
+	if config.EncryptKey != "" {
+		key := []byte(config.EncryptKey)
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			sum := sha256.Sum256(key)
+			key = sum[:]
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		if aead, err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+
+	cookieMu.Lock()
+	defer cookieMu.Unlock()
+	cookieConfig = config
+	cookieAEAD = aead
+	return nil
+}
+
+// getCookieConfig 获取Cookie配置
+func getCookieConfig() (CookieConfig, cipher.AEAD) {
+	cookieMu.RLock()
+	defer cookieMu.RUnlock()
+	return cookieConfig, cookieAEAD
+}
+
+// cookieLine 补全默认属性后生成Set-Cookie响应头，Cookie名称无效时返回空字符串
+func cookieLine(cookie *http.Cookie) string {
+	config, _ := getCookieConfig()
+	ck := *cookie
+	if ck.Path == "" {
+		ck.Path = config.Path
+	}
+	if ck.Domain == "" {
+		ck.Domain = config.Domain
+	}
+	if ck.SameSite == 0 {
+		ck.SameSite = config.SameSite
+	}
+	ck.Secure = ck.Secure || config.Secure
+	ck.HttpOnly = ck.HttpOnly || config.HTTPOnly
+	return ck.String()
+}
+
+// clearCookieLine 生成删除Cookie的Set-Cookie响应头，Path和Domain使用配置中的默认值
+func clearCookieLine(name string) string {
+	return cookieLine(&http.Cookie{
+		Name:    name,
+		Value:   "",
+		Expires: time.Unix(0, 0),
+		MaxAge:  -1,
+	})
+}
+
+// parseCookie 从Cookie请求头中读取指定Cookie，与net/http的解析规则一致
+func parseCookie(header, name string) (string, error) {
+	r := http.Request{Header: http.Header{"Cookie": {header}}}
+	ck, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return ck.Value, nil
+}
+
+// signCookie 签名Cookie值，格式为base64(值).base64(签名)，签名包含Cookie名称，防止不同Cookie之间互换
+func signCookie(name, value string) (string, error) {
+	config, _ := getCookieConfig()
+	if config.SignKey == "" {
+		return "", ErrCookieKeyMissing
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(cookieMAC(config.SignKey, name, payload)), nil
+}
+
+// verifyCookie 校验签名并返回原始值
+func verifyCookie(name, signed string) (string, error) {
+	config, _ := getCookieConfig()
+	if config.SignKey == "" {
+		return "", ErrCookieKeyMissing
+	}
+	payload, sig, ok := strings.Cut(signed, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, cookieMAC(config.SignKey, name, payload)) {
+		return "", ErrInvalidCookie
+	}
+	value, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(value), nil
+}
+
+// cookieMAC 计算签名
+func cookieMAC(key, name, payload string) []byte {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(name))
+	h.Write([]byte{'='})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// encryptCookie 加密Cookie值，格式为base64(随机数+密文)，Cookie名称作为附加数据参与认证
+func encryptCookie(name, value string) (string, error) {
+	_, aead := getCookieConfig()
+	if aead == nil {
+		return "", ErrCookieKeyMissing
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// decryptCookie 解密Cookie值
+func decryptCookie(name, encrypted string) (string, error) {
+	_, aead := getCookieConfig()
+	if aead == nil {
+		return "", ErrCookieKeyMissing
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrInvalidCookie
+	}
+	value, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	return string(value), nil
+}
+
+// cookieAccessor 各引擎读写Cookie的基础操作
+type cookieAccessor interface {
+	Cookie(name string) (string, error)
+	SetCookie(cookie *http.Cookie)
+}
+
+// signedCookie 读取并校验签名Cookie
+func signedCookie(c cookieAccessor, name string) (string, error) {
+	value, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return verifyCookie(name, value)
+}
+
+// setSignedCookie 签名后写入Cookie
+func setSignedCookie(c cookieAccessor, cookie *http.Cookie) error {
+	value, err := signCookie(cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+	ck := *cookie
+	ck.Value = value
+	c.SetCookie(&ck)
+	return nil
+}
+
+// encryptedCookie 读取并解密Cookie
+func encryptedCookie(c cookieAccessor, name string) (string, error) {
+	value, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return decryptCookie(name, value)
+}
+
+// setEncryptedCookie 加密后写入Cookie
+func setEncryptedCookie(c cookieAccessor, cookie *http.Cookie) error {
+	value, err := encryptCookie(cookie.Name, cookie.Value)
+	if err != nil {
+		return err
+	}
+	ck := *cookie
+	ck.Value = value
+	c.SetCookie(&ck)
+	return nil
+}
+
+// Cookie 实现Context接口
+func (c *GinContext) Cookie(name string) (string, error) {
+	ck, err := c.ctx.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return ck.Value, nil
+}
+
+// SetCookie 实现Context接口
+func (c *GinContext) SetCookie(cookie *http.Cookie) {
+	if line := cookieLine(cookie); line != "" {
+		c.ctx.Writer.Header().Add("Set-Cookie", line)
+	}
+}
+
+// ClearCookie 实现Context接口
+func (c *GinContext) ClearCookie(name string) {
+	c.ctx.Writer.Header().Add("Set-Cookie", clearCookieLine(name))
+}
+
+// SignedCookie 实现Context接口
+func (c *GinContext) SignedCookie(name string) (string, error) {
+	return signedCookie(c, name)
+}
+
+// SetSignedCookie 实现Context接口
+func (c *GinContext) SetSignedCookie(cookie *http.Cookie) error {
+	return setSignedCookie(c, cookie)
+}
+
+// EncryptedCookie 实现Context接口
+func (c *GinContext) EncryptedCookie(name string) (string, error) {
+	return encryptedCookie(c, name)
+}
+
+// SetEncryptedCookie 实现Context接口
+func (c *GinContext) SetEncryptedCookie(cookie *http.Cookie) error {
+	return setEncryptedCookie(c, cookie)
+}
+
+// Cookie 实现Context接口
+func (c *StdContext) Cookie(name string) (string, error) {
+	ck, err := c.request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return ck.Value, nil
+}
+
+// SetCookie 实现Context接口
+func (c *StdContext) SetCookie(cookie *http.Cookie) {
+	if line := cookieLine(cookie); line != "" {
+		c.writer.Header().Add("Set-Cookie", line)
+	}
+}
+
+// ClearCookie 实现Context接口
+func (c *StdContext) ClearCookie(name string) {
+	c.writer.Header().Add("Set-Cookie", clearCookieLine(name))
+}
+
+// SignedCookie 实现Context接口
+func (c *StdContext) SignedCookie(name string) (string, error) {
+	return signedCookie(c, name)
+}
+
+// SetSignedCookie 实现Context接口
+func (c *StdContext) SetSignedCookie(cookie *http.Cookie) error {
+	return setSignedCookie(c, cookie)
+}
+
+// EncryptedCookie 实现Context接口
+func (c *StdContext) EncryptedCookie(name string) (string, error) {
+	return encryptedCookie(c, name)
+}
+
+// SetEncryptedCookie 实现Context接口
+func (c *StdContext) SetEncryptedCookie(cookie *http.Cookie) error {
+	return setEncryptedCookie(c, cookie)
+}
+
+// Cookie 实现Context接口，使用net/http的规则解析，与其他引擎的结果一致
+func (c *FiberContext) Cookie(name string) (string, error) {
+	return parseCookie(string(c.ctx.Request().Header.Peek("Cookie")), name)
+}
+
+// SetCookie 实现Context接口
+func (c *FiberContext) SetCookie(cookie *http.Cookie) {
+	if line := cookieLine(cookie); line != "" {
+		c.ctx.Response().Header.Add("Set-Cookie", line)
+	}
+}
+
+// ClearCookie 实现Context接口
+func (c *FiberContext) ClearCookie(name string) {
+	c.ctx.Response().Header.Add("Set-Cookie", clearCookieLine(name))
+}
+
+// SignedCookie 实现Context接口
+func (c *FiberContext) SignedCookie(name string) (string, error) {
+	return signedCookie(c, name)
+}
+
+// SetSignedCookie 实现Context接口
+func (c *FiberContext) SetSignedCookie(cookie *http.Cookie) error {
+	return setSignedCookie(c, cookie)
+}
+
+// EncryptedCookie 实现Context接口
+func (c *FiberContext) EncryptedCookie(name string) (string, error) {
+	return encryptedCookie(c, name)
+}
+
+// SetEncryptedCookie 实现Context接口
+func (c *FiberContext) SetEncryptedCookie(cookie *http.Cookie) error {
+	return setEncryptedCookie(c, cookie)
+}
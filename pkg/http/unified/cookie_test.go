@@ -0,0 +1,172 @@
+package unified
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setCookieKeys 设置测试密钥，测试结束后恢复默认配置
+func setCookieKeys(t *testing.T, signKey, encryptKey string) {
+	t.Helper()
+	if err := SetCookieConfig(CookieConfig{SignKey: signKey, EncryptKey: encryptKey}); err != nil {
+		t.Fatalf("设置Cookie配置失败: %v", err)
+	}
+	t.Cleanup(func() { SetCookieConfig(CookieConfig{}) })
+}
+
+// writeCookie 通过StdContext写入Cookie，返回Set-Cookie中的Cookie
+func writeCookie(t *testing.T, set func(c Context) error) *http.Cookie {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := set(NewStdContext(w, httptest.NewRequest(http.MethodGet, "/", nil))); err != nil {
+		t.Fatalf("写入Cookie失败: %v", err)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Set-Cookie数量 %d，期望 1", len(cookies))
+	}
+	return cookies[0]
+}
+
+// readContext 创建带有Cookie请求头的StdContext
+func readContext(cookies ...*http.Cookie) Context {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, ck := range cookies {
+		r.AddCookie(ck)
+	}
+	return NewStdContext(httptest.NewRecorder(), r)
+}
+
+// flipChar 修改base64字符串中间的一个字符，末尾字符可能包含不参与解码的填充位
+func flipChar(s string) string {
+	b := []byte(s)
+	i := len(b) / 2
+	if b[i] == 'A' {
+		b[i] = 'B'
+	} else {
+		b[i] = 'A'
+	}
+	return string(b)
+}
+
+func TestSignedCookieRoundTrip(t *testing.T) {
+	setCookieKeys(t, "sign-key", "")
+
+	ck := writeCookie(t, func(c Context) error {
+		return c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "42; 中文"})
+	})
+	if strings.Contains(ck.Value, "42") {
+		t.Errorf("签名Cookie应编码原始值: %s", ck.Value)
+	}
+	value, err := readContext(ck).SignedCookie("uid")
+	if err != nil || value != "42; 中文" {
+		t.Fatalf("SignedCookie = %q, %v", value, err)
+	}
+}
+
+func TestSignedCookieTampering(t *testing.T) {
+	setCookieKeys(t, "sign-key", "")
+
+	ck := writeCookie(t, func(c Context) error {
+		return c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "42"})
+	})
+	payload, sig, _ := strings.Cut(ck.Value, ".")
+	forged, err := signCookie("uid", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	cases := map[string]*http.Cookie{
+		"替换值":    {Name: "uid", Value: forgedPayload + "." + sig},
+		"缺少签名":   {Name: "uid", Value: payload},
+		"签名被修改":  {Name: "uid", Value: payload + "." + flipChar(sig)},
+		"签名不是编码": {Name: "uid", Value: payload + ".!!"},
+	}
+	for name, tampered := range cases {
+		if _, err := readContext(tampered).SignedCookie("uid"); !errors.Is(err, ErrInvalidCookie) {
+			t.Errorf("%s: 错误 %v，期望 ErrInvalidCookie", name, err)
+		}
+	}
+
+	// 签名包含Cookie名称，不能把一个Cookie的值用作另一个Cookie
+	swapped := &http.Cookie{Name: "admin", Value: ck.Value}
+	if _, err := readContext(swapped).SignedCookie("admin"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("互换Cookie名称: 错误 %v，期望 ErrInvalidCookie", err)
+	}
+
+	// 更换密钥后旧签名失效
+	setCookieKeys(t, "other-key", "")
+	if _, err := readContext(ck).SignedCookie("uid"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("更换密钥: 错误 %v，期望 ErrInvalidCookie", err)
+	}
+}
+
+func TestEncryptedCookieRoundTrip(t *testing.T) {
+	for _, key := range []string{"0123456789abcdef", "任意长度的密钥"} {
+		setCookieKeys(t, "", key)
+
+		ck := writeCookie(t, func(c Context) error {
+			return c.SetEncryptedCookie(&http.Cookie{Name: "profile", Value: "机密"})
+		})
+		value, err := readContext(ck).EncryptedCookie("profile")
+		if err != nil || value != "机密" {
+			t.Fatalf("密钥 %q: EncryptedCookie = %q, %v", key, value, err)
+		}
+	}
+
+	// 每次加密使用随机数，相同的值生成不同的密文
+	setCookieKeys(t, "", "0123456789abcdef")
+	a, _ := encryptCookie("profile", "机密")
+	b, _ := encryptCookie("profile", "机密")
+	if a == b {
+		t.Error("相同的值加密后密文相同")
+	}
+}
+
+func TestEncryptedCookieTampering(t *testing.T) {
+	setCookieKeys(t, "", "0123456789abcdef")
+
+	ck := writeCookie(t, func(c Context) error {
+		return c.SetEncryptedCookie(&http.Cookie{Name: "profile", Value: "机密"})
+	})
+	cases := map[string]*http.Cookie{
+		"密文被修改": {Name: "profile", Value: flipChar(ck.Value)},
+		"密文过短":  {Name: "profile", Value: "AAAA"},
+		"不是编码":  {Name: "profile", Value: "!!"},
+	}
+	for name, tampered := range cases {
+		if _, err := readContext(tampered).EncryptedCookie("profile"); !errors.Is(err, ErrInvalidCookie) {
+			t.Errorf("%s: 错误 %v，期望 ErrInvalidCookie", name, err)
+		}
+	}
+
+	// Cookie名称作为附加数据参与认证
+	swapped := &http.Cookie{Name: "other", Value: ck.Value}
+	if _, err := readContext(swapped).EncryptedCookie("other"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("互换Cookie名称: 错误 %v，期望 ErrInvalidCookie", err)
+	}
+
+	setCookieKeys(t, "", "fedcba9876543210")
+	if _, err := readContext(ck).EncryptedCookie("profile"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("更换密钥: 错误 %v，期望 ErrInvalidCookie", err)
+	}
+}
+
+func TestCookieKeyMissing(t *testing.T) {
+	setCookieKeys(t, "", "")
+
+	c := readContext(&http.Cookie{Name: "uid", Value: "a.b"})
+	if err := c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "1"}); !errors.Is(err, ErrCookieKeyMissing) {
+		t.Errorf("SetSignedCookie: 错误 %v，期望 ErrCookieKeyMissing", err)
+	}
+	if _, err := c.SignedCookie("uid"); !errors.Is(err, ErrCookieKeyMissing) {
+		t.Errorf("SignedCookie: 错误 %v，期望 ErrCookieKeyMissing", err)
+	}
+	if err := c.SetEncryptedCookie(&http.Cookie{Name: "uid", Value: "1"}); !errors.Is(err, ErrCookieKeyMissing) {
+		t.Errorf("SetEncryptedCookie: 错误 %v，期望 ErrCookieKeyMissing", err)
+	}
+}
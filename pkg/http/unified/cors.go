@@ -0,0 +1,283 @@
+package unified
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig 跨域中间件配置
+type CORSConfig struct {
+	// AllowOrigins 允许的来源，"*"允许所有来源，"https://*.example.com"允许example.com的所有子域名
+	AllowOrigins []string
+	// AllowMethods 允许的请求方法，默认GET、POST、PUT、PATCH、DELETE、HEAD
+	AllowMethods []string
+	// AllowHeaders 允许的请求头，为空时允许预检请求中声明的所有请求头
+	AllowHeaders []string
+	// ExposeHeaders 允许浏览器读取的响应头
+	ExposeHeaders []string
+	// AllowCredentials 是否允许携带Cookie等凭证，允许时返回请求的来源，AllowOrigins必须列出具体的来源，不能包含"*"
+	AllowCredentials bool
+	// MaxAge 预检结果的缓存时间，为0时不返回Access-Control-Max-Age
+	MaxAge time.Duration
+}
+
+// defaultCORSMethods 默认允许的请求方法
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+}
+
+// ErrCORSCredentialsWildcard 允许所有来源的同时允许携带凭证，任何网站都能以用户身份读取响应
+var ErrCORSCredentialsWildcard = errors.New("跨域配置允许携带凭证时，allow_origins不能包含\"*\"，需要列出具体的来源")
+
+// Validate 检查跨域配置，允许携带凭证时不能允许所有来源
+func (config CORSConfig) Validate() error {
+	if !config.AllowCredentials {
+		return nil
+	}
+	for _, origin := range config.AllowOrigins {
+		origin = strings.TrimSpace(origin)
+		// "https://*"这类通配符同样匹配所有来源
+		if origin == "*" || strings.HasSuffix(origin, "*") {
+			return ErrCORSCredentialsWildcard
+		}
+	}
+	return nil
+}
+
+// CORS 跨域中间件，可以作为全局中间件，也可以在路由组或路由上覆盖全局配置，例如:
+//
+//	router.Group("/open", unified.CORS(unified.CORSConfig{AllowOrigins: []string{"*"}}))
+//
+// 路由上有多个跨域中间件时只使用最后注册的一个（路由组和路由上的配置覆盖全局配置），
+// 跨域中间件总是最先执行，认证等中间件返回的错误响应也带有跨域响应头；
+// 使用了跨域中间件的路由会自动注册OPTIONS路由，预检请求由中间件直接返回204，不会进入处理函数；
+// 配置没有通过Validate检查时panic
+func CORS(config CORSConfig) MiddlewareFunc {
+	if err := config.Validate(); err != nil {
+		panic(err)
+	}
+	return newCORSPolicy(config).middleware
+}
+
+// corsMiddlewarePC corsPolicy.middleware方法值的函数地址，路由器据此识别跨域中间件
+var corsMiddlewarePC = funcPC((*corsPolicy)(nil).middleware)
+
+// splitCORS 从中间件列表中取出最后一个跨域中间件，返回跨域中间件和其余中间件
+func splitCORS(middlewares []MiddlewareFunc) (MiddlewareFunc, []MiddlewareFunc) {
+	var cors MiddlewareFunc
+	rest := make([]MiddlewareFunc, 0, len(middlewares))
+	for _, m := range middlewares {
+		if funcPC(m) == corsMiddlewarePC {
+			cors = m
+			continue
+		}
+		rest = append(rest, m)
+	}
+	return cors, rest
+}
+
+// corsPolicy 预处理后的跨域配置
+type corsPolicy struct {
+	allowAll         bool
+	origins          map[string]bool
+	wildcards        [][2]string
+	methods          map[string]bool
+	allowMethods     string
+	allowAllHeaders  bool
+	headers          map[string]bool
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// newCORSPolicy 创建跨域策略
+func newCORSPolicy(config CORSConfig) *corsPolicy {
+	p := &corsPolicy{
+		origins:          map[string]bool{},
+		methods:          map[string]bool{},
+		headers:          map[string]bool{},
+		allowCredentials: config.AllowCredentials,
+	}
+
+	for _, origin := range config.AllowOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "":
+		case origin == "*":
+			p.allowAll = true
+		case strings.Contains(origin, "*"):
+			i := strings.Index(origin, "*")
+			p.wildcards = append(p.wildcards, [2]string{origin[:i], origin[i+1:]})
+		default:
+			p.origins[origin] = true
+		}
+	}
+
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	normalized := make([]string, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method != "" && !p.methods[method] {
+			p.methods[method] = true
+			normalized = append(normalized, method)
+		}
+	}
+	p.allowMethods = strings.Join(normalized, ", ")
+
+	p.allowAllHeaders = len(config.AllowHeaders) == 0
+	normalized = make([]string, 0, len(config.AllowHeaders))
+	for _, header := range config.AllowHeaders {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header == "*" {
+			p.allowAllHeaders = true
+			continue
+		}
+		if header != "" && !p.headers[header] {
+			p.headers[header] = true
+			normalized = append(normalized, header)
+		}
+	}
+	p.allowHeaders = strings.Join(normalized, ", ")
+
+	expose := make([]string, 0, len(config.ExposeHeaders))
+	for _, header := range config.ExposeHeaders {
+		if header = strings.TrimSpace(header); header != "" {
+			expose = append(expose, http.CanonicalHeaderKey(header))
+		}
+	}
+	p.exposeHeaders = strings.Join(expose, ", ")
+
+	if config.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(config.MaxAge / time.Second))
+	}
+	return p
+}
+
+// allowOrigin 检查来源是否允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		// 通配符至少匹配一个字符，"https://*.example.com"不匹配"https://.example.com"
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowRequestHeaders 检查预检请求声明的请求头是否都允许
+func (p *corsPolicy) allowRequestHeaders(requested string) bool {
+	if p.allowAllHeaders {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header != "" && !p.headers[header] {
+			return false
+		}
+	}
+	return true
+}
+
+// middleware 跨域中间件
+func (p *corsPolicy) middleware(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		return p.handle(c, next)
+	}
+}
+
+// handle 处理跨域请求
+func (p *corsPolicy) handle(c Context, next HandlerFunc) error {
+	origin := c.GetHeader("Origin")
+	requestMethod := c.GetHeader("Access-Control-Request-Method")
+	preflight := c.Method() == http.MethodOptions && requestMethod != ""
+
+	// 响应内容随请求来源变化，缓存需要区分来源
+	addVary(c, "Origin")
+	if preflight {
+		addVary(c, "Access-Control-Request-Method", "Access-Control-Request-Headers")
+	}
+
+	// 非跨域请求
+	if origin == "" {
+		return next(c)
+	}
+
+	if preflight {
+		// 预检请求不进入处理函数，不允许的来源、方法或请求头不返回跨域响应头，由浏览器拒绝实际请求
+		requestHeaders := c.GetHeader("Access-Control-Request-Headers")
+		if p.allowOrigin(origin) && p.methods[strings.ToUpper(requestMethod)] && p.allowRequestHeaders(requestHeaders) {
+			p.setOrigin(c, origin)
+			c.SetHeader("Access-Control-Allow-Methods", p.allowMethods)
+			allowHeaders := p.allowHeaders
+			if p.allowAllHeaders {
+				allowHeaders = requestHeaders
+			}
+			if allowHeaders != "" {
+				c.SetHeader("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if p.maxAge != "" {
+				c.SetHeader("Access-Control-Max-Age", p.maxAge)
+			}
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+		return nil
+	}
+
+	if p.allowOrigin(origin) {
+		p.setOrigin(c, origin)
+		if p.exposeHeaders != "" {
+			c.SetHeader("Access-Control-Expose-Headers", p.exposeHeaders)
+		}
+	}
+	return next(c)
+}
+
+// setOrigin 设置允许的来源和凭证响应头
+func (p *corsPolicy) setOrigin(c Context, origin string) {
+	if p.allowAll {
+		c.SetHeader("Access-Control-Allow-Origin", "*")
+		return
+	}
+	c.SetHeader("Access-Control-Allow-Origin", origin)
+	if p.allowCredentials {
+		c.SetHeader("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// addVary 向Vary响应头追加字段，已存在的字段不重复添加
+func addVary(c Context, fields ...string) {
+	header := c.GetResponse().Header()
+	existing := header.Values("Vary")
+	for _, field := range fields {
+		if !headerContains(existing, field) {
+			header.Add("Vary", field)
+			existing = append(existing, field)
+		}
+	}
+}
+
+// headerContains 检查逗号分隔的响应头值中是否包含指定字段
+func headerContains(values []string, field string) bool {
+	for _, value := range values {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), field) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package unified
+
+import (
+	"net/http"
+	"sync"
+)
+
+// ErrorHandler 处理函数（包括中间件）返回错误时的处理方式
+// 所有引擎的路由适配器都通过HandleError调用同一个错误处理器
+type ErrorHandler func(c Context, err error)
+
+var (
+	errorMu      sync.RWMutex
+	errorHandler ErrorHandler = defaultErrorHandler
+)
+
+// SetErrorHandler 设置错误处理器，传入nil恢复默认处理方式
+func SetErrorHandler(handler ErrorHandler) {
+	errorMu.Lock()
+	defer errorMu.Unlock()
+	if handler == nil {
+		handler = defaultErrorHandler
+	}
+	errorHandler = handler
+}
+
+// HandleError 将错误交给错误处理器
+func HandleError(c Context, err error) {
+	if err == nil {
+		return
+	}
+	errorMu.RLock()
+	handler := errorHandler
+	errorMu.RUnlock()
+	handler(c, err)
+}
+
+// defaultErrorHandler 默认错误处理方式，记录错误并在尚未输出响应时返回500
+func defaultErrorHandler(c Context, err error) {
+	c.Error(err)
+	if !ResponseWritten(c) {
+		c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// ResponseWritten 判断是否已经输出了响应，已输出时错误处理器不应再写入响应
+func ResponseWritten(c Context) bool {
+	switch v := c.(type) {
+	case *GinContext:
+		return v.ctx.Writer.Written()
+	case *StdContext:
+		return v.writer.Written()
+	case *FiberContext:
+		resp := v.ctx.Response()
+		return len(resp.Body()) > 0 || resp.IsBodyStream()
+	}
+	return false
+}
+
+// ResponseStatus 获取响应状态码，尚未设置时返回200，供在处理结束后记录状态码的中间件使用
+func ResponseStatus(c Context) int {
+	switch v := c.(type) {
+	case *GinContext:
+		return v.ctx.Writer.Status()
+	case *StdContext:
+		return v.writer.Status()
+	case *FiberContext:
+		return v.ctx.Response().StatusCode()
+	}
+	return http.StatusOK
+}
@@ -1,19 +1,24 @@
 package unified
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 )
 
 // FiberContext 是Fiber上下文的适配器
 type FiberContext struct {
 	ctx *fiber.Ctx
+	// routed 由统一路由器创建，中间件已通过包装HandlerFunc串联
+	routed bool
 }
 
 // NewFiberContext 创建一个Fiber上下文适配器
@@ -46,7 +51,7 @@ func (c *FiberContext) URL() *url.URL {
 
 // ClientIP 实现Context接口
 func (c *FiberContext) ClientIP() string {
-	return c.ctx.IP()
+	return clientIP(c.ctx.Get("X-Forwarded-For"), c.ctx.Get("X-Real-IP"), c.ctx.Context().RemoteAddr().String())
 }
 
 // GetHeader 实现Context接口
@@ -66,29 +71,41 @@ func (c *FiberContext) Query(key string) string {
 
 // QueryDefault 实现Context接口
 func (c *FiberContext) QueryDefault(key, defaultValue string) string {
-	return c.ctx.Query(key, defaultValue)
+	// 参数存在但值为空时返回空字符串，与其他引擎一致
+	if !c.ctx.Context().QueryArgs().Has(key) {
+		return defaultValue
+	}
+	return c.ctx.Query(key)
 }
 
-// QueryMap 实现Context接口
+// QueryMap 实现Context接口，同名参数取第一个值
 func (c *FiberContext) QueryMap() map[string]string {
 	result := make(map[string]string)
-	c.ctx.QueryParser(&result)
+	c.ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		if _, ok := result[string(key)]; !ok {
+			result[string(key)] = string(value)
+		}
+	})
 	return result
 }
 
-// Param 实现Context接口
+// Param 实现Context接口，返回解码后的路径参数，与其他引擎一致
 func (c *FiberContext) Param(key string) string {
-	return c.ctx.Params(key)
+	value := c.ctx.Params(key)
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+	return value
 }
 
 // ParamInt 实现Context接口
 func (c *FiberContext) ParamInt(key string) (int, error) {
-	return c.ctx.ParamsInt(key)
+	return strconv.Atoi(c.Param(key))
 }
 
 // ParamUint 实现Context接口
 func (c *FiberContext) ParamUint(key string) (uint, error) {
-	val, err := strconv.ParseUint(c.ctx.Params(key), 10, 64)
+	val, err := strconv.ParseUint(c.Param(key), 10, 64)
 	return uint(val), err
 }
 
@@ -107,6 +124,14 @@ func (c *FiberContext) BindForm(obj interface{}) error {
 	return c.ctx.BodyParser(obj)
 }
 
+// Bind 实现Context接口
+func (c *FiberContext) Bind(obj interface{}) error {
+	return bind(c, obj, func() ([]byte, error) {
+		// fasthttp的请求体在请求结束后会被复用
+		return append([]byte(nil), c.ctx.Body()...), nil
+	})
+}
+
 // FormFile 实现Context接口
 func (c *FiberContext) FormFile(name string) (*multipart.FileHeader, error) {
 	return c.ctx.FormFile(name)
@@ -125,12 +150,16 @@ func (c *FiberContext) Status(code int) Context {
 
 // JSON 实现Context接口
 func (c *FiberContext) JSON(code int, obj interface{}) error {
-	return c.ctx.Status(code).JSON(obj)
+	return c.ctx.Status(code).JSON(obj, jsonContentType)
 }
 
 // String 实现Context接口
 func (c *FiberContext) String(code int, format string, values ...interface{}) error {
-	return c.ctx.Status(code).SendString(fmt.Sprintf(format, values...))
+	text := format
+	if len(values) > 0 {
+		text = fmt.Sprintf(format, values...)
+	}
+	return c.ctx.Status(code).SendString(text)
 }
 
 // HTML 实现Context接口
@@ -139,9 +168,34 @@ func (c *FiberContext) HTML(code int, html string) error {
 	return c.ctx.Status(code).SendString(html)
 }
 
+// XML 实现Context接口
+func (c *FiberContext) XML(code int, obj interface{}) error {
+	return render(c, code, xmlCodec{}, obj)
+}
+
+// Data 实现Context接口
+func (c *FiberContext) Data(code int, contentType string, data []byte) error {
+	c.ctx.Set("Content-Type", contentType)
+	return c.ctx.Status(code).Send(data)
+}
+
+// Negotiate 实现Context接口
+func (c *FiberContext) Negotiate(code int, data interface{}) error {
+	return negotiate(c, code, data)
+}
+
 // Redirect 实现Context接口
+// 使用http.Redirect生成响应，Location和响应体与其他引擎一致
 func (c *FiberContext) Redirect(code int, url string) error {
-	return c.ctx.Status(code).Redirect(url)
+	if err := checkRedirectCode(code); err != nil {
+		return err
+	}
+	r := c.GetRequest()
+	if r == nil {
+		return c.ctx.Redirect(url, code)
+	}
+	http.Redirect(c.GetResponse(), r, url, code)
+	return nil
 }
 
 // File 实现Context接口
@@ -152,6 +206,7 @@ func (c *FiberContext) File(filepath string) error {
 // Stream 实现Context接口
 func (c *FiberContext) Stream(contentType string, r io.Reader) error {
 	c.ctx.Set("Content-Type", contentType)
+	c.ctx.Locals(streamedKey, true)
 	data, err := io.ReadAll(r)
 	if err != nil {
 		return err
@@ -159,8 +214,14 @@ func (c *FiberContext) Stream(contentType string, r io.Reader) error {
 	return c.ctx.Send(data)
 }
 
+// nilLocal 通过Set保存nil时实际保存在Locals中的值，Locals无法区分值为nil和键不存在，Get读到它时返回nil和true
+type nilLocal struct{}
+
 // Set 实现Context接口
 func (c *FiberContext) Set(key string, value interface{}) {
+	if value == nil {
+		value = nilLocal{}
+	}
 	c.ctx.Locals(key, value)
 }
 
@@ -170,13 +231,16 @@ func (c *FiberContext) Get(key string) (interface{}, bool) {
 	if val == nil {
 		return nil, false
 	}
+	if _, ok := val.(nilLocal); ok {
+		return nil, true
+	}
 	return val, true
 }
 
 // MustGet 实现Context接口
 func (c *FiberContext) MustGet(key string) interface{} {
-	val := c.ctx.Locals(key)
-	if val == nil {
+	val, ok := c.Get(key)
+	if !ok {
 		panic("key " + key + " not found")
 	}
 	return val
@@ -192,18 +256,143 @@ func (c *FiberContext) FiberContext() interface{} {
 	return c.ctx
 }
 
+// Context 实现Context接口
+func (c *FiberContext) Context() context.Context {
+	return c.ctx.UserContext()
+}
+
+// SetContext 实现Context接口，同时更新GetRequest返回的请求
+func (c *FiberContext) SetContext(ctx context.Context) {
+	c.ctx.SetUserContext(ctx)
+	if r, ok := c.ctx.Locals(requestKey).(*http.Request); ok {
+		c.ctx.Locals(requestKey, r.WithContext(ctx))
+	}
+}
+
+// bindContext 为处理函数设置可取消的上下文，返回的函数取消上下文并恢复原来的上下文
+// fasthttp无法感知客户端断开连接，上下文在处理函数返回时取消；
+// fasthttp.RequestCtx.Done()与服务器关闭存在数据竞争，不能作为父上下文，服务器关闭时会等待处理函数返回
+func (c *FiberContext) bindContext() func() {
+	parent := c.ctx.UserContext()
+	ctx, cancel := context.WithCancel(parent)
+	c.SetContext(ctx)
+	return func() {
+		cancel()
+		c.SetContext(parent)
+	}
+}
+
 // GetRequest 实现Context接口
+// 由fasthttp请求转换而来，只在处理函数执行期间有效，请求URI无效时返回nil
 func (c *FiberContext) GetRequest() *http.Request {
-	// Fiber不直接暴露http.Request
-	// 这里返回nil，如果需要原始请求可以使用FiberContext()获取原始ctx
-	return nil
+	if r, ok := c.ctx.Locals(requestKey).(*http.Request); ok {
+		return r
+	}
+	r := new(http.Request)
+	if err := fasthttpadaptor.ConvertRequest(c.ctx.Context(), r, true); err != nil {
+		return nil
+	}
+	r = r.WithContext(c.ctx.UserContext())
+	c.ctx.Locals(requestKey, r)
+	return r
 }
 
 // GetResponse 实现Context接口
+// 写入fasthttp响应，通过Header()修改的响应头在写入状态码、响应体或处理函数返回时生效
 func (c *FiberContext) GetResponse() http.ResponseWriter {
-	// Fiber不直接暴露http.ResponseWriter
-	// 这里返回nil，如果需要原始响应可以使用FiberContext()获取原始ctx
-	return nil
+	return c.responseWriter()
+}
+
+// responseWriter 获取当前请求的fiberResponseWriter
+func (c *FiberContext) responseWriter() *fiberResponseWriter {
+	if w, ok := c.ctx.Locals(responseWriterKey).(*fiberResponseWriter); ok {
+		return w
+	}
+	w := newFiberResponseWriter(c.ctx)
+	c.ctx.Locals(responseWriterKey, w)
+	return w
+}
+
+// finish 处理函数返回后调用，使通过GetResponse修改但尚未写入的响应头生效
+func (c *FiberContext) finish() {
+	if w, ok := c.ctx.Locals(responseWriterKey).(*fiberResponseWriter); ok {
+		w.syncHeader()
+	}
+}
+
+// 用于存储转换后的请求和响应的本地变量名
+const (
+	requestKey        = "_http_request"
+	responseWriterKey = "_http_response_writer"
+)
+
+// fiberResponseWriter 基于fasthttp响应实现http.ResponseWriter
+type fiberResponseWriter struct {
+	ctx         *fiber.Ctx
+	header      http.Header
+	snapshot    http.Header
+	wroteHeader bool
+}
+
+// newFiberResponseWriter 创建响应适配器，响应头从当前fasthttp响应复制
+func newFiberResponseWriter(ctx *fiber.Ctx) *fiberResponseWriter {
+	header := http.Header{}
+	resp := &ctx.Response().Header
+	resp.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	// fasthttp在未设置Content-Type时返回默认值，这里只保留显式设置的值
+	resp.SetNoDefaultContentType(true)
+	if len(resp.ContentType()) == 0 {
+		header.Del("Content-Type")
+	}
+	resp.SetNoDefaultContentType(false)
+	return &fiberResponseWriter{ctx: ctx, header: header, snapshot: header.Clone()}
+}
+
+// Header 实现http.ResponseWriter接口
+func (w *fiberResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader 实现http.ResponseWriter接口
+func (w *fiberResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.syncHeader()
+	w.ctx.Status(code)
+}
+
+// Write 实现http.ResponseWriter接口，未写入状态码时使用当前的状态码
+func (w *fiberResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(w.ctx.Response().StatusCode())
+	}
+	w.ctx.Response().AppendBody(p)
+	return len(p), nil
+}
+
+// syncHeader 将通过Header()修改的响应头写入fasthttp响应
+// 只同步与上次同步时不同的响应头，避免覆盖期间通过Context方法设置的响应头
+func (w *fiberResponseWriter) syncHeader() {
+	resp := &w.ctx.Response().Header
+	for key, values := range w.header {
+		if slices.Equal(values, w.snapshot[key]) {
+			continue
+		}
+		resp.Del(key)
+		for _, v := range values {
+			resp.Add(key, v)
+		}
+	}
+	for key := range w.snapshot {
+		if _, ok := w.header[key]; !ok {
+			resp.Del(key)
+		}
+	}
+	w.snapshot = w.header.Clone()
 }
 
 // 用于存储错误的本地变量名
@@ -238,7 +427,11 @@ func (c *FiberContext) Errors() []error {
 }
 
 // Next 实现Context接口
+// 统一路由器中的处理函数位于Fiber处理链的末尾，与Gin一致不做任何操作，否则会进入下一个匹配的路由
 func (c *FiberContext) Next() {
+	if c.routed {
+		return
+	}
 	if err := c.ctx.Next(); err != nil {
 		c.Error(err)
 	}
@@ -266,5 +459,5 @@ func (c *FiberContext) AbortWithStatus(code int) {
 // AbortWithJSON 实现Context接口
 func (c *FiberContext) AbortWithJSON(code int, obj interface{}) error {
 	c.Abort()
-	return c.ctx.Status(code).JSON(obj)
+	return c.JSON(code, obj)
 }
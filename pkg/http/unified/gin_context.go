@@ -1,6 +1,7 @@
 package unified
 
 import (
+	"context"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -44,7 +45,8 @@ func (c *GinContext) URL() *url.URL {
 
 // ClientIP 实现Context接口
 func (c *GinContext) ClientIP() string {
-	return c.ctx.ClientIP()
+	r := c.ctx.Request
+	return clientIP(r.Header.Get("X-Forwarded-For"), r.Header.Get("X-Real-IP"), r.RemoteAddr)
 }
 
 // GetHeader 实现Context接口
@@ -109,6 +111,11 @@ func (c *GinContext) BindForm(obj interface{}) error {
 	return c.ctx.ShouldBind(obj)
 }
 
+// Bind 实现Context接口
+func (c *GinContext) Bind(obj interface{}) error {
+	return bind(c, obj, c.ctx.GetRawData)
+}
+
 // FormFile 实现Context接口
 func (c *GinContext) FormFile(name string) (*multipart.FileHeader, error) {
 	return c.ctx.FormFile(name)
@@ -143,8 +150,28 @@ func (c *GinContext) HTML(code int, html string) error {
 	return nil
 }
 
+// XML 实现Context接口
+func (c *GinContext) XML(code int, obj interface{}) error {
+	return render(c, code, xmlCodec{}, obj)
+}
+
+// Data 实现Context接口
+func (c *GinContext) Data(code int, contentType string, data []byte) error {
+	c.ctx.Data(code, contentType, data)
+	return nil
+}
+
+// Negotiate 实现Context接口
+func (c *GinContext) Negotiate(code int, data interface{}) error {
+	return negotiate(c, code, data)
+}
+
 // Redirect 实现Context接口
 func (c *GinContext) Redirect(code int, url string) error {
+	// Gin对无效的状态码直接panic，这里与其他引擎一致返回错误
+	if err := checkRedirectCode(code); err != nil {
+		return err
+	}
 	c.ctx.Redirect(code, url)
 	return nil
 }
@@ -157,12 +184,12 @@ func (c *GinContext) File(filepath string) error {
 
 // Stream 实现Context接口
 func (c *GinContext) Stream(contentType string, r io.Reader) error {
-	data, err := io.ReadAll(r)
-	if err != nil {
-		return err
-	}
-	c.ctx.Data(http.StatusOK, contentType, data)
-	return nil
+	// 使用之前通过Status设置的状态码
+	c.ctx.Header("Content-Type", contentType)
+	streamResponse(c.ctx.Writer)
+	c.ctx.Writer.WriteHeaderNow()
+	_, err := io.Copy(c.ctx.Writer, r)
+	return err
 }
 
 // Set 实现Context接口
@@ -190,6 +217,16 @@ func (c *GinContext) FiberContext() interface{} {
 	return nil
 }
 
+// Context 实现Context接口
+func (c *GinContext) Context() context.Context {
+	return c.ctx.Request.Context()
+}
+
+// SetContext 实现Context接口
+func (c *GinContext) SetContext(ctx context.Context) {
+	c.ctx.Request = c.ctx.Request.WithContext(ctx)
+}
+
 // GetRequest 实现Context接口
 func (c *GinContext) GetRequest() *http.Request {
 	return c.ctx.Request
@@ -202,6 +239,10 @@ func (c *GinContext) GetResponse() http.ResponseWriter {
 
 // Error 实现Context接口
 func (c *GinContext) Error(err error) error {
+	// Gin的Error不接受nil
+	if err == nil {
+		return nil
+	}
 	c.ctx.Error(err)
 	return err
 }
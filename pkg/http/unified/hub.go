@@ -0,0 +1,204 @@
+package unified
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Hub WebSocket连接管理器，支持房间和广播
+// 连接通过Register加入、Unregister退出，写消息失败的连接会被自动移除并关闭
+type Hub struct {
+	mu    sync.RWMutex
+	conns map[string]*hubConn
+	rooms map[string]map[string]*hubConn
+}
+
+// hubConn 已注册的连接及其所在的房间
+type hubConn struct {
+	conn  WSConn
+	rooms map[string]struct{}
+}
+
+// NewHub 创建连接管理器
+func NewHub() *Hub {
+	return &Hub{
+		conns: make(map[string]*hubConn),
+		rooms: make(map[string]map[string]*hubConn),
+	}
+}
+
+// Register 注册连接
+func (h *Hub) Register(conn WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn.ID()]; !ok {
+		h.conns[conn.ID()] = &hubConn{conn: conn, rooms: make(map[string]struct{})}
+	}
+}
+
+// Unregister 注销连接，并退出所有房间
+func (h *Hub) Unregister(conn WSConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(conn.ID())
+}
+
+// remove 移除连接，调用方需要持有写锁
+func (h *Hub) remove(id string) {
+	hc, ok := h.conns[id]
+	if !ok {
+		return
+	}
+	for room := range hc.rooms {
+		h.leave(hc, room)
+	}
+	delete(h.conns, id)
+}
+
+// Join 加入房间，未注册的连接会先注册
+func (h *Hub) Join(conn WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hc, ok := h.conns[conn.ID()]
+	if !ok {
+		hc = &hubConn{conn: conn, rooms: make(map[string]struct{})}
+		h.conns[conn.ID()] = hc
+	}
+	members, ok := h.rooms[room]
+	if !ok {
+		members = make(map[string]*hubConn)
+		h.rooms[room] = members
+	}
+	members[conn.ID()] = hc
+	hc.rooms[room] = struct{}{}
+}
+
+// Leave 退出房间
+func (h *Hub) Leave(conn WSConn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hc, ok := h.conns[conn.ID()]; ok {
+		h.leave(hc, room)
+	}
+}
+
+// leave 退出房间，房间为空时删除，调用方需要持有写锁
+func (h *Hub) leave(hc *hubConn, room string) {
+	delete(hc.rooms, room)
+	if members, ok := h.rooms[room]; ok {
+		delete(members, hc.conn.ID())
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+}
+
+// Broadcast 向所有连接发送消息
+func (h *Hub) Broadcast(messageType int, data []byte) {
+	h.mu.RLock()
+	targets := make([]WSConn, 0, len(h.conns))
+	for _, hc := range h.conns {
+		targets = append(targets, hc.conn)
+	}
+	h.mu.RUnlock()
+	h.send(targets, messageType, data)
+}
+
+// BroadcastJSON 向所有连接发送JSON消息
+func (h *Hub) BroadcastJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.Broadcast(TextMessage, data)
+	return nil
+}
+
+// BroadcastToRoom 向房间内的所有连接发送消息，except中的连接不会收到消息
+func (h *Hub) BroadcastToRoom(room string, messageType int, data []byte, except ...WSConn) {
+	h.mu.RLock()
+	members := h.rooms[room]
+	targets := make([]WSConn, 0, len(members))
+	for id, hc := range members {
+		if !containsConn(except, id) {
+			targets = append(targets, hc.conn)
+		}
+	}
+	h.mu.RUnlock()
+	h.send(targets, messageType, data)
+}
+
+// BroadcastToRoomJSON 向房间内的所有连接发送JSON消息，except中的连接不会收到消息
+func (h *Hub) BroadcastToRoomJSON(room string, v interface{}, except ...WSConn) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.BroadcastToRoom(room, TextMessage, data, except...)
+	return nil
+}
+
+// send 发送消息，发送失败的连接会被移除并关闭
+func (h *Hub) send(targets []WSConn, messageType int, data []byte) {
+	for _, conn := range targets {
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			h.mu.Lock()
+			h.remove(conn.ID())
+			h.mu.Unlock()
+			conn.Close()
+		}
+	}
+}
+
+// Count 获取连接数
+func (h *Hub) Count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// RoomCount 获取房间内的连接数
+func (h *Hub) RoomCount(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Rooms 获取所有房间名，按名称排序
+func (h *Hub) Rooms() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	rooms := make([]string, 0, len(h.rooms))
+	for room := range h.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// RoomsOf 获取连接所在的房间，按名称排序
+func (h *Hub) RoomsOf(conn WSConn) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hc, ok := h.conns[conn.ID()]
+	if !ok {
+		return nil
+	}
+	rooms := make([]string, 0, len(hc.rooms))
+	for room := range hc.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// containsConn 判断连接是否在列表中
+func containsConn(conns []WSConn, id string) bool {
+	for _, c := range conns {
+		if c != nil && c.ID() == id {
+			return true
+		}
+	}
+	return false
+}
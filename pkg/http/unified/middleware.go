@@ -1,6 +1,8 @@
 package unified
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gofiber/fiber/v2"
 )
@@ -107,9 +109,7 @@ func Adapt(handler HandlerFunc) MiddlewareFunc {
 // ToGinHandler 将HandlerFunc转换为Gin的处理函数
 func ToGinHandler(handler HandlerFunc) func(*GinContext) {
 	return func(c *GinContext) {
-		if err := handler(c); err != nil {
-			// 已经在handler中处理了错误
-		}
+		HandleError(c, handler(c))
 	}
 }
 
@@ -120,6 +120,67 @@ func ToFiberHandler(handler HandlerFunc) func(*FiberContext) error {
 	}
 }
 
+// ToStdHandler 将HandlerFunc转换为标准库的处理函数
+func ToStdHandler(handler HandlerFunc) http.HandlerFunc {
+	return stdHandlerFunc("", handler)
+}
+
+// stdHandlerFunc 创建标准库处理函数，fullPath为注册时的路由模板
+func stdHandlerFunc(fullPath string, handler HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := NewStdContext(w, r)
+		ctx.fullPath = fullPath
+		HandleError(ctx, handler(ctx))
+		// 处理结束后确保状态码已写入
+		ctx.writer.WriteHeaderNow()
+	}
+}
+
+// ToGinMiddleware 将统一中间件转换为Gin引擎的全局中间件，next继续执行Gin处理链，未匹配路由的请求也会经过
+func ToGinMiddleware(middleware MiddlewareFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := NewGinContext(c)
+		called := false
+		err := middleware(func(Context) error {
+			called = true
+			c.Next()
+			return nil
+		})(ctx)
+		HandleError(ctx, err)
+		// 中间件没有调用next时不再执行后续处理函数
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// ToFiberMiddleware 将统一中间件转换为Fiber应用的全局中间件，next继续执行Fiber处理链，未匹配路由的请求也会经过
+func ToFiberMiddleware(middleware MiddlewareFunc) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := NewFiberContext(c)
+		HandleError(ctx, middleware(func(Context) error {
+			return c.Next()
+		})(ctx))
+		ctx.finish()
+		return nil
+	}
+}
+
+// ToStdMiddleware 将统一中间件转换为标准库的处理器包装函数，next继续执行被包装的处理器
+func ToStdMiddleware(middleware MiddlewareFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := NewStdContext(w, r)
+			HandleError(ctx, middleware(func(Context) error {
+				// 使用中间件通过SetContext更新后的请求
+				next.ServeHTTP(ctx.writer, ctx.request)
+				return nil
+			})(ctx))
+			ctx.writer.WriteHeaderNow()
+		})
+	}
+}
+
 // GinMiddlewareAdapter 将Gin中间件适配为统一的Middleware
 func GinMiddlewareAdapter(ginMiddleware func(c *GinContext)) MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
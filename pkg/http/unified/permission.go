@@ -0,0 +1,79 @@
+package unified
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// UserIDKey 认证中间件保存用户ID的上下文数据键，jwt和session中间件写入，授权、限流和幂等中间件读取
+const UserIDKey = "user_id"
+
+// routePermissions 路由通过Router.Permission声明的权限，键为"方法 路由模板"
+var routePermissions sync.Map
+
+// RoutePermissions 获取当前请求的路由声明的权限，没有声明时返回nil，供授权中间件使用
+func RoutePermissions(c Context) []string {
+	method := c.Method()
+	v, ok := routePermissions.Load(method + " " + c.Path())
+	if !ok && method == http.MethodHead {
+		// 标准库引擎的GET路由同时处理HEAD请求，使用GET路由的权限
+		v, ok = routePermissions.Load(http.MethodGet + " " + c.Path())
+	}
+	if !ok {
+		return nil
+	}
+	return v.([]string)
+}
+
+// Authorize 创建授权中间件，路由通过Router.Permission声明了权限时调用check检查，check返回错误时不执行处理函数，
+// 没有声明权限的路由直接放行；声明了权限的路由必须使用授权中间件，否则CheckAuthorization返回错误
+func Authorize(check func(c Context, permissions []string) error) MiddlewareFunc {
+	return (&authorizer{check: check}).middleware
+}
+
+// authorizer 授权中间件
+type authorizer struct {
+	check func(c Context, permissions []string) error
+}
+
+// authorizerPC 授权中间件的函数地址，用于判断路由是否使用了授权中间件
+var authorizerPC = funcPC((*authorizer)(nil).middleware)
+
+// middleware 检查路由声明的权限
+func (a *authorizer) middleware(next HandlerFunc) HandlerFunc {
+	return func(c Context) error {
+		if required := RoutePermissions(c); len(required) > 0 {
+			if err := a.check(c, required); err != nil {
+				return err
+			}
+		}
+		return next(c)
+	}
+}
+
+// hasAuthorizer 判断中间件中是否包含授权中间件
+func hasAuthorizer(middlewares []MiddlewareFunc) bool {
+	for _, m := range middlewares {
+		if funcPC(m) == authorizerPC {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAuthorization 检查声明了权限的路由是否都使用了授权中间件，
+// 没有授权中间件时权限声明不会生效，服务器启动前调用，存在这样的路由时返回错误
+func CheckAuthorization(routes []RouteInfo) error {
+	var unprotected []string
+	for _, route := range routes {
+		if len(route.Permissions) > 0 && !route.authorized {
+			unprotected = append(unprotected, route.Method+" "+route.Path)
+		}
+	}
+	if len(unprotected) > 0 {
+		return fmt.Errorf("路由声明了权限但没有使用授权中间件: %s", strings.Join(unprotected, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package unified
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/log"
+)
+
+// RequestIDHeader 默认的请求ID请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey 请求ID在上下文数据中的键
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength 接受的客户端请求ID的最大长度
+const maxRequestIDLength = 128
+
+// RequestIDConfig 请求ID中间件配置
+type RequestIDConfig struct {
+	// Header 读取和返回请求ID的头，默认X-Request-ID
+	Header string
+	// Generator 生成请求ID，默认生成32位十六进制随机字符串
+	Generator func() string
+}
+
+// RequestID 请求ID中间件
+// 优先使用请求头中的请求ID，没有或格式不合法时生成新的请求ID，
+// 请求ID保存到上下文数据和Context()中并通过响应头返回，通过Logger.WithContext(c.Context())记录的日志都会带上请求ID
+func RequestID(config ...RequestIDConfig) MiddlewareFunc {
+	cfg := RequestIDConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Header == "" {
+		cfg.Header = RequestIDHeader
+	}
+	if cfg.Generator == nil {
+		cfg.Generator = newRequestID
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			// 外层已经设置过请求ID，例如服务器全局启用时再在路由上使用
+			requestID := GetRequestID(c)
+			if requestID == "" {
+				// Fiber返回的请求头引用请求缓冲区，复制后才能在请求结束后继续使用
+				requestID = strings.Clone(c.GetHeader(cfg.Header))
+				if !validRequestID(requestID) {
+					requestID = cfg.Generator()
+				}
+				c.SetContext(log.WithRequestID(c.Context(), requestID))
+			}
+			c.Set(RequestIDKey, requestID)
+			c.SetHeader(cfg.Header, requestID)
+			return next(c)
+		}
+	}
+}
+
+// GetRequestID 获取当前请求的请求ID，未使用RequestID中间件时返回空字符串
+func GetRequestID(c Context) string {
+	if requestID := log.RequestIDFromContext(c.Context()); requestID != "" {
+		return requestID
+	}
+	if v, ok := c.Get(RequestIDKey); ok {
+		if requestID, ok := v.(string); ok {
+			return requestID
+		}
+	}
+	return ""
+}
+
+// newRequestID 生成随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 检查客户端传入的请求ID，只接受长度有限的可见ASCII字符，避免日志注入
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,10 @@
 package unified
 
 import (
+	"net/http"
+	"slices"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gofiber/fiber/v2"
 )
@@ -29,6 +33,9 @@ type Router interface {
 	OPTIONS(path string, handler HandlerFunc, middlewares ...MiddlewareFunc) Router
 	HEAD(path string, handler HandlerFunc, middlewares ...MiddlewareFunc) Router
 
+	// WebSocket路由，升级成功后在连接上调用handler，handler返回时关闭连接
+	WebSocket(path string, handler WSHandler, middlewares ...MiddlewareFunc) Router
+
 	// 静态文件
 	Static(prefix, root string) Router
 
@@ -40,6 +47,17 @@ type Router interface {
 
 	// 处理请求
 	Handle(method HTTPMethod, path string, handler HandlerFunc, middlewares ...MiddlewareFunc) Router
+
+	// 获取已注册的路由，分组与根路由器共享同一张路由表
+	Routes() []RouteInfo
+
+	// 为最近注册的路由添加文档信息，例如:
+	// router.GET("/:id", handler).Doc(RouteDoc{Summary: "获取详情", Response: model.Demo{}})
+	Doc(doc RouteDoc) Router
+
+	// 为最近注册的路由声明需要的权限，由Authorize创建的授权中间件检查，路由没有使用授权中间件时服务器拒绝启动，例如:
+	// router.DELETE("/:id", handler).Permission("demo:delete")
+	Permission(permissions ...string) Router
 }
 
 // RouterAdapter 路由适配器接口
@@ -63,15 +81,31 @@ type EngineType int
 const (
 	GinEngine EngineType = iota
 	FiberEngine
+	StdEngine
 )
 
+// String 获取引擎名称，与配置项http.engine的取值一致
+func (e EngineType) String() string {
+	switch e {
+	case GinEngine:
+		return "gin"
+	case FiberEngine:
+		return "fiber"
+	case StdEngine:
+		return "std"
+	}
+	return "unknown"
+}
+
 // RouterImpl 路由器实现
 type RouterImpl struct {
 	engineType EngineType
 	ginEngine  *gin.Engine
 	fiberApp   *fiber.App
+	stdMux     *http.ServeMux
 	prefix     string
 	middleware []MiddlewareFunc
+	routes     *routeTable
 }
 
 // NewRouter 创建新的路由器
@@ -82,43 +116,135 @@ func NewRouter(engineType EngineType, ginEngine *gin.Engine, fiberApp *fiber.App
 		fiberApp:   fiberApp,
 		prefix:     "",
 		middleware: []MiddlewareFunc{},
+		routes:     &routeTable{},
+	}
+}
+
+// NewStdRouter 创建基于标准库http.ServeMux的路由器
+func NewStdRouter(mux *http.ServeMux) Router {
+	return &RouterImpl{
+		engineType: StdEngine,
+		stdMux:     mux,
+		prefix:     "",
+		middleware: []MiddlewareFunc{},
+		routes:     &routeTable{},
 	}
 }
 
 // Handle 实现Router接口
 func (r *RouterImpl) Handle(method HTTPMethod, path string, handler HandlerFunc, middlewares ...MiddlewareFunc) Router {
+	return r.handle(method, path, handlerName(handler), handler, middlewares)
+}
+
+// handle 注册路由，name为路由列表中显示的处理函数名称
+func (r *RouterImpl) handle(method HTTPMethod, path, name string, handler HandlerFunc, middlewares []MiddlewareFunc) Router {
 	fullPath := r.prefix + path
 
 	// 合并中间件
-	allMiddlewares := append(r.middleware, middlewares...)
+	allMiddlewares := slices.Concat(r.middleware, middlewares)
+
+	// 记录路由信息
+	r.routes.add(RouteInfo{
+		Method:      string(method),
+		Path:        fullPath,
+		Handler:     name,
+		Middlewares: middlewareNames(allMiddlewares),
+		Group:       r.prefix,
+		authorized:  hasAuthorizer(allMiddlewares),
+	})
+
+	// 跨域和压缩中间件只使用最后注册的一个，并且依次最先执行
+	cors, allMiddlewares := splitCORS(allMiddlewares)
+	compress, allMiddlewares := splitCompress(allMiddlewares)
+
+	// 应用中间件，先注册的中间件先执行，路由组的中间件在路由的中间件之前执行
+	for i := len(allMiddlewares) - 1; i >= 0; i-- {
+		handler = allMiddlewares[i](handler)
+	}
+	if compress != nil {
+		handler = compress(handler)
+	}
+	if cors != nil {
+		handler = cors(handler)
+	}
+
+	// 已自动注册OPTIONS路由的路径只替换处理函数
+	if method == OPTIONS && r.routes.claimOptions(fullPath, handler) {
+		return r
+	}
+	r.register(method, fullPath, handler)
 
-	// 应用中间件
-	if len(allMiddlewares) > 0 {
-		for _, m := range allMiddlewares {
-			handler = m(handler)
+	// 自动注册OPTIONS路由处理预检请求，不记录到路由列表
+	if cors != nil && method != OPTIONS {
+		if preflight := r.routes.addPreflight(fullPath, cors(noContent)); preflight != nil {
+			r.register(OPTIONS, fullPath, preflight.serve)
 		}
 	}
 
+	return r
+}
+
+// register 向底层引擎注册路由
+func (r *RouterImpl) register(method HTTPMethod, fullPath string, handler HandlerFunc) {
 	switch r.engineType {
 	case GinEngine:
 		if r.ginEngine != nil {
 			r.ginEngine.Handle(string(method), fullPath, func(c *gin.Context) {
 				ctx := NewGinContext(c)
-				if err := handler(ctx); err != nil {
-					c.Error(err)
-				}
+				HandleError(ctx, handler(ctx))
 			})
 		}
 	case FiberEngine:
 		if r.fiberApp != nil {
 			r.fiberApp.Add(string(method), fullPath, func(c *fiber.Ctx) error {
 				ctx := NewFiberContext(c)
-				return handler(ctx)
+				ctx.routed = true
+				release := ctx.bindContext()
+				HandleError(ctx, handler(ctx))
+				ctx.finish()
+				release()
+				return nil
 			})
 		}
+	case StdEngine:
+		if r.stdMux != nil {
+			r.stdMux.HandleFunc(StdPattern(string(method), fullPath), stdHandlerFunc(fullPath, handler))
+		}
 	}
+}
 
-	return r
+// noContent 自动注册的OPTIONS路由的处理函数，非预检的OPTIONS请求返回204
+func noContent(c Context) error {
+	c.AbortWithStatus(http.StatusNoContent)
+	return nil
+}
+
+// StdPattern 将统一路由路径转换为Go 1.22 ServeMux的匹配模式
+// 例如 "/users/:id/*filepath" 转换为 "GET /users/{id}/{filepath...}"；
+// ServeMux中以"/"结尾的模式匹配所有以它开头的路径，因此"/"和"/users/"转换为"/{$}"和"/users/{$}"，与gin和fiber一样只匹配该路径
+func StdPattern(method, path string) string {
+	if path == "" {
+		path = "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":") && len(seg) > 1:
+			segments[i] = "{" + seg[1:] + "}"
+		case strings.HasPrefix(seg, "*") && len(seg) > 1:
+			segments[i] = "{" + seg[1:] + "...}"
+		case seg == "*":
+			segments[i] = "{path...}"
+		}
+	}
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+	if method == "" {
+		return pattern
+	}
+	return method + " " + pattern
 }
 
 // GET 实现Router接口
@@ -156,10 +282,24 @@ func (r *RouterImpl) OPTIONS(path string, handler HandlerFunc, middlewares ...Mi
 	return r.Handle(OPTIONS, path, handler, middlewares...)
 }
 
+// WebSocket 实现Router接口
+func (r *RouterImpl) WebSocket(path string, handler WSHandler, middlewares ...MiddlewareFunc) Router {
+	return r.handle(GET, path, FuncName(handler), webSocketHandler(handler), middlewares)
+}
+
 // Static 实现Router接口
 func (r *RouterImpl) Static(prefix, root string) Router {
 	fullPrefix := r.prefix + prefix
 
+	// 记录静态文件路由
+	r.routes.add(RouteInfo{
+		Method:      string(GET),
+		Path:        strings.TrimSuffix(fullPrefix, "/") + "/*filepath",
+		Handler:     "static:" + root,
+		Middlewares: []string{},
+		Group:       r.prefix,
+	})
+
 	switch r.engineType {
 	case GinEngine:
 		if r.ginEngine != nil {
@@ -169,6 +309,12 @@ func (r *RouterImpl) Static(prefix, root string) Router {
 		if r.fiberApp != nil {
 			r.fiberApp.Static(fullPrefix, root)
 		}
+	case StdEngine:
+		if r.stdMux != nil {
+			prefix := strings.TrimSuffix(fullPrefix, "/")
+			fileServer := http.StripPrefix(prefix, http.FileServer(http.Dir(root)))
+			r.stdMux.Handle(StdPattern(string(GET), prefix+"/*filepath"), fileServer)
+		}
 	}
 	return r
 }
@@ -185,8 +331,29 @@ func (r *RouterImpl) Group(prefix string, middlewares ...MiddlewareFunc) Router
 		engineType: r.engineType,
 		ginEngine:  r.ginEngine,
 		fiberApp:   r.fiberApp,
+		stdMux:     r.stdMux,
 		prefix:     r.prefix + prefix,
-		middleware: append(r.middleware, middlewares...),
+		middleware: slices.Concat(r.middleware, middlewares),
+		routes:     r.routes,
 	}
 	return group
 }
+
+// Routes 实现Router接口
+func (r *RouterImpl) Routes() []RouteInfo {
+	return r.routes.list()
+}
+
+// Doc 实现Router接口
+func (r *RouterImpl) Doc(doc RouteDoc) Router {
+	r.routes.describeLast(doc)
+	return r
+}
+
+// Permission 实现Router接口
+func (r *RouterImpl) Permission(permissions ...string) Router {
+	if route, ok := r.routes.permitLast(permissions); ok {
+		routePermissions.Store(route.Method+" "+route.Path, route.Permissions)
+	}
+	return r
+}
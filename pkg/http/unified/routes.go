@@ -0,0 +1,178 @@
+package unified
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RouteInfo 已注册路由的描述信息
+type RouteInfo struct {
+	// Method HTTP方法
+	Method string `json:"method"`
+	// Path 完整路径（包含分组前缀）
+	Path string `json:"path"`
+	// Handler 处理函数名称
+	Handler string `json:"handler"`
+	// Middlewares 路由上生效的中间件名称，按注册顺序排列
+	Middlewares []string `json:"middlewares"`
+	// Group 路由所属的分组前缀
+	Group string `json:"group"`
+	// Doc 路由文档信息，通过Router.Doc设置
+	Doc *RouteDoc `json:"doc,omitempty"`
+	// Permissions 访问路由需要的权限，通过Router.Permission声明
+	Permissions []string `json:"permissions,omitempty"`
+
+	// authorized 路由的中间件中是否包含Authorize创建的授权中间件
+	authorized bool
+}
+
+// RouteDoc 路由文档信息，用于生成OpenAPI文档
+type RouteDoc struct {
+	// Summary 接口摘要
+	Summary string `json:"summary,omitempty"`
+	// Description 接口详细描述
+	Description string `json:"description,omitempty"`
+	// Tags 接口标签，为空时使用分组前缀的最后一段
+	Tags []string `json:"tags,omitempty"`
+	// OperationID 接口唯一标识，为空时根据处理函数名生成
+	OperationID string `json:"operationId,omitempty"`
+	// Request 请求参数结构体（值或指针），根据json/form/uri/header标签生成参数
+	Request interface{} `json:"-"`
+	// Response 成功响应中data字段的结构体（值或指针）
+	Response interface{} `json:"-"`
+	// Deprecated 是否已废弃
+	Deprecated bool `json:"deprecated,omitempty"`
+	// Hidden 不在文档中显示
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// routeTable 路由表，同一个根路由器派生出的分组共享同一张表
+type routeTable struct {
+	mu         sync.RWMutex
+	routes     []RouteInfo
+	preflights map[string]*preflightRoute
+}
+
+// preflightRoute 路径上的OPTIONS路由，使用跨域中间件的路由会自动注册，
+// 之后显式注册同一路径的OPTIONS路由时替换处理函数，不再向引擎重复注册
+type preflightRoute struct {
+	mu       sync.RWMutex
+	handler  HandlerFunc
+	explicit bool
+}
+
+// serve 调用当前的处理函数
+func (p *preflightRoute) serve(c Context) error {
+	p.mu.RLock()
+	handler := p.handler
+	p.mu.RUnlock()
+	return handler(c)
+}
+
+// addPreflight 为路径自动注册OPTIONS路由，路径已有OPTIONS路由时返回nil
+func (t *routeTable) addPreflight(path string, handler HandlerFunc) *preflightRoute {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.preflights[path]; ok {
+		return nil
+	}
+	if t.preflights == nil {
+		t.preflights = map[string]*preflightRoute{}
+	}
+	route := &preflightRoute{handler: handler}
+	t.preflights[path] = route
+	return route
+}
+
+// claimOptions 显式注册OPTIONS路由，路径已自动注册OPTIONS路由时替换其处理函数并返回true
+func (t *routeTable) claimOptions(path string, handler HandlerFunc) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if route, ok := t.preflights[path]; ok {
+		route.mu.Lock()
+		defer route.mu.Unlock()
+		if !route.explicit {
+			route.handler = handler
+			route.explicit = true
+			return true
+		}
+		return false
+	}
+	if t.preflights == nil {
+		t.preflights = map[string]*preflightRoute{}
+	}
+	t.preflights[path] = &preflightRoute{handler: handler, explicit: true}
+	return false
+}
+
+// add 添加路由记录
+func (t *routeTable) add(info RouteInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = append(t.routes, info)
+}
+
+// describeLast 为最近注册的路由设置文档信息
+func (t *routeTable) describeLast(doc RouteDoc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.routes) == 0 {
+		return
+	}
+	t.routes[len(t.routes)-1].Doc = &doc
+}
+
+// permitLast 为最近注册的路由添加需要的权限，返回该路由
+func (t *routeTable) permitLast(permissions []string) (RouteInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.routes) == 0 {
+		return RouteInfo{}, false
+	}
+	route := &t.routes[len(t.routes)-1]
+	route.Permissions = slices.Concat(route.Permissions, permissions)
+	return *route, true
+}
+
+// list 获取路由记录的副本
+func (t *routeTable) list() []RouteInfo {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	result := make([]RouteInfo, len(t.routes))
+	copy(result, t.routes)
+	return result
+}
+
+// FuncName 获取函数的完整名称，方法值会去掉编译器追加的"-fm"后缀
+func FuncName(fn interface{}) string {
+	if fn == nil {
+		return ""
+	}
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	return strings.TrimSuffix(f.Name(), "-fm")
+}
+
+// funcPC 获取函数值的代码地址，方法值返回编译器为该方法生成的包装函数地址，
+// 同一方法的所有方法值地址相同，用于识别类型化处理函数、跨域和压缩中间件
+func funcPC(fn interface{}) uintptr {
+	return reflect.ValueOf(fn).Pointer()
+}
+
+// middlewareNames 获取中间件名称列表
+func middlewareNames(middlewares []MiddlewareFunc) []string {
+	names := make([]string, 0, len(middlewares))
+	for _, m := range middlewares {
+		names = append(names, FuncName(m))
+	}
+	return names
+}
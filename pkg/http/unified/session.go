@@ -0,0 +1,69 @@
+package unified
+
+import "errors"
+
+// SessionKey 会话在上下文数据中的键
+const SessionKey = "session"
+
+// ErrSessionDisabled 未使用会话中间件时修改会话返回的错误
+var ErrSessionDisabled = errors.New("未启用会话中间件")
+
+// Session 服务端会话，由会话中间件（pkg/http/session）创建并保存到上下文数据，通过Context.Session()获取
+type Session interface {
+	// ID 会话ID，新会话在第一次写入前为空字符串
+	ID() string
+	// Get 获取会话值
+	Get(key string) (string, bool)
+	// Set 设置会话值，新会话第一次写入时生成会话ID并写入Cookie或响应头
+	Set(key string, value interface{}) error
+	// Delete 删除会话值
+	Delete(keys ...string) error
+	// Values 获取所有会话值，不包括闪存消息
+	Values() map[string]string
+	// Regenerate 生成新的会话ID并保留会话值，登录成功后调用，防止会话固定攻击
+	Regenerate() error
+	// Destroy 删除会话及其所有值，退出登录时调用
+	Destroy() error
+	// AddFlash 添加闪存消息，消息保留到下一次通过Flashes读取
+	AddFlash(key, message string) error
+	// Flashes 读取并删除闪存消息
+	Flashes(key string) ([]string, error)
+}
+
+// sessionOf 获取上下文中的会话，未使用会话中间件时返回disabledSession
+func sessionOf(c Context) Session {
+	if v, ok := c.Get(SessionKey); ok {
+		if s, ok := v.(Session); ok {
+			return s
+		}
+	}
+	return disabledSession{}
+}
+
+// disabledSession 未使用会话中间件时的会话，读取返回空值，修改返回ErrSessionDisabled
+type disabledSession struct{}
+
+func (disabledSession) ID() string                       { return "" }
+func (disabledSession) Get(string) (string, bool)        { return "", false }
+func (disabledSession) Set(string, interface{}) error    { return ErrSessionDisabled }
+func (disabledSession) Delete(...string) error           { return ErrSessionDisabled }
+func (disabledSession) Values() map[string]string        { return map[string]string{} }
+func (disabledSession) Regenerate() error                { return ErrSessionDisabled }
+func (disabledSession) Destroy() error                   { return ErrSessionDisabled }
+func (disabledSession) AddFlash(string, string) error    { return ErrSessionDisabled }
+func (disabledSession) Flashes(string) ([]string, error) { return nil, ErrSessionDisabled }
+
+// Session 实现Context接口
+func (c *GinContext) Session() Session {
+	return sessionOf(c)
+}
+
+// Session 实现Context接口
+func (c *StdContext) Session() Session {
+	return sessionOf(c)
+}
+
+// Session 实现Context接口
+func (c *FiberContext) Session() Session {
+	return sessionOf(c)
+}
@@ -0,0 +1,369 @@
+package unified
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamClosed 客户端已断开连接或事件流已结束，此后写入的事件都会返回此错误
+var ErrStreamClosed = errors.New("事件流已关闭")
+
+// SSEHandler 事件流处理函数，返回后事件流结束
+type SSEHandler func(w EventWriter) error
+
+// Event 服务器推送事件
+type Event struct {
+	// ID 事件ID，客户端重连时通过Last-Event-ID请求头带回
+	ID string
+	// Event 事件名称，为空时客户端按message事件处理
+	Event string
+	// Data 事件数据，string和[]byte原样输出，其他类型序列化为JSON
+	Data interface{}
+	// Retry 客户端断线重连间隔
+	Retry time.Duration
+}
+
+// EventWriter 事件写入器，写方法可以被多个goroutine并发调用
+type EventWriter interface {
+	// Send 发送事件
+	Send(event Event) error
+	// Event 发送指定名称的事件
+	Event(name string, data interface{}) error
+	// Data 发送message事件
+	Data(data interface{}) error
+	// Comment 发送注释，客户端会忽略注释
+	Comment(text string) error
+	// Retry 设置客户端断线重连间隔
+	Retry(d time.Duration) error
+	// LastEventID 客户端重连时携带的最后一个事件ID
+	LastEventID() string
+	// Done 客户端断开连接时关闭
+	Done() <-chan struct{}
+}
+
+// SSEConfig 服务器推送事件配置
+type SSEConfig struct {
+	// KeepAliveInterval 发送保活注释的间隔，0表示不发送
+	// 保活注释可以防止代理断开空闲连接，同时用于及时发现客户端断开
+	KeepAliveInterval time.Duration
+	// WriteTimeout 单次写入超时时间，同时取代服务器的写超时，避免长连接被中断
+	WriteTimeout time.Duration
+	// Retry 事件流开始时发送的重连间隔，0表示不发送
+	Retry time.Duration
+}
+
+var (
+	sseMu     sync.RWMutex
+	sseConfig = SSEConfig{
+		KeepAliveInterval: 15 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	sseErrorHandler func(path string, err error)
+)
+
+// SetSSEConfig 设置服务器推送事件配置
+func SetSSEConfig(config SSEConfig) {
+	sseMu.Lock()
+	defer sseMu.Unlock()
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	sseConfig = config
+}
+
+// SetSSEErrorHandler 设置事件流处理函数返回错误时的处理方式
+// 事件流开始后响应头已经发出，错误无法再输出给客户端，只能记录下来
+func SetSSEErrorHandler(handler func(path string, err error)) {
+	sseMu.Lock()
+	defer sseMu.Unlock()
+	sseErrorHandler = handler
+}
+
+// getSSEConfig 获取服务器推送事件配置
+func getSSEConfig() (SSEConfig, func(path string, err error)) {
+	sseMu.RLock()
+	defer sseMu.RUnlock()
+	return sseConfig, sseErrorHandler
+}
+
+// sseWriter EventWriter的实现
+type sseWriter struct {
+	mu          sync.Mutex
+	write       func(p []byte) error
+	config      SSEConfig
+	lastEventID string
+	finished    bool
+	done        chan struct{}
+	doneOnce    sync.Once
+}
+
+// newSSEWriter 创建事件写入器，write负责写入并刷新数据
+func newSSEWriter(write func(p []byte) error, lastEventID string, config SSEConfig) *sseWriter {
+	return &sseWriter{
+		write:       write,
+		config:      config,
+		lastEventID: lastEventID,
+		done:        make(chan struct{}),
+	}
+}
+
+// Send 实现EventWriter接口
+func (s *sseWriter) Send(event Event) error {
+	var buf bytes.Buffer
+	if event.ID != "" {
+		writeSSEField(&buf, "id", event.ID)
+	}
+	if event.Event != "" {
+		writeSSEField(&buf, "event", event.Event)
+	}
+	if event.Retry > 0 {
+		writeSSEField(&buf, "retry", strconv.FormatInt(event.Retry.Milliseconds(), 10))
+	}
+	if event.Data != nil || buf.Len() == 0 || event.Event != "" {
+		data, err := encodeSSEData(event.Data)
+		if err != nil {
+			return err
+		}
+		// 多行数据拆分为多个data字段
+		data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+		for _, line := range strings.Split(data, "\n") {
+			writeSSEField(&buf, "data", line)
+		}
+	}
+	buf.WriteByte('\n')
+	return s.writeRaw(buf.Bytes())
+}
+
+// Event 实现EventWriter接口
+func (s *sseWriter) Event(name string, data interface{}) error {
+	return s.Send(Event{Event: name, Data: data})
+}
+
+// Data 实现EventWriter接口
+func (s *sseWriter) Data(data interface{}) error {
+	return s.Send(Event{Data: data})
+}
+
+// Comment 实现EventWriter接口
+func (s *sseWriter) Comment(text string) error {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		buf.WriteString(": ")
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	return s.writeRaw(buf.Bytes())
+}
+
+// Retry 实现EventWriter接口
+func (s *sseWriter) Retry(d time.Duration) error {
+	return s.Send(Event{Retry: d})
+}
+
+// LastEventID 实现EventWriter接口
+func (s *sseWriter) LastEventID() string {
+	return s.lastEventID
+}
+
+// Done 实现EventWriter接口
+func (s *sseWriter) Done() <-chan struct{} {
+	return s.done
+}
+
+// writeRaw 写入并刷新数据，写入失败视为客户端断开
+func (s *sseWriter) writeRaw(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return ErrStreamClosed
+	}
+	select {
+	case <-s.done:
+		return ErrStreamClosed
+	default:
+	}
+	if err := s.write(p); err != nil {
+		s.disconnect()
+		return ErrStreamClosed
+	}
+	return nil
+}
+
+// disconnect 标记客户端已断开
+func (s *sseWriter) disconnect() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+// run 运行处理函数，返回处理函数的错误，客户端断开导致的错误返回nil
+func (s *sseWriter) run(handler SSEHandler) error {
+	stop := make(chan struct{})
+	defer close(stop)
+	if s.config.KeepAliveInterval > 0 {
+		go s.keepAlive(stop)
+	}
+
+	err := func() error {
+		if s.config.Retry > 0 {
+			if err := s.Retry(s.config.Retry); err != nil {
+				return err
+			}
+		}
+		return handler(s)
+	}()
+
+	// 处理函数返回后不再允许写入，保活协程中正在进行的写入会在获取锁之前完成
+	s.mu.Lock()
+	s.finished = true
+	s.mu.Unlock()
+
+	if errors.Is(err, ErrStreamClosed) {
+		return nil
+	}
+	return err
+}
+
+// keepAlive 定时发送保活注释
+func (s *sseWriter) keepAlive(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.config.KeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.writeRaw([]byte(": keep-alive\n\n")); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSSEField 写入事件字段，字段值中的换行会被移除
+func writeSSEField(buf *bytes.Buffer, name, value string) {
+	if name != "data" {
+		value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	}
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// encodeSSEData 将事件数据转换为字符串
+func encodeSSEData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// setSSEHeaders 设置事件流响应头
+func setSSEHeaders(set func(key, value string)) {
+	set("Content-Type", "text/event-stream; charset=utf-8")
+	set("Cache-Control", "no-cache")
+	set("Connection", "keep-alive")
+	// 禁用Nginx等反向代理的响应缓冲
+	set("X-Accel-Buffering", "no")
+}
+
+// serveHTTPSSE 基于net/http输出事件流（gin和标准库引擎），处理函数在当前goroutine中运行
+func serveHTTPSSE(w http.ResponseWriter, r *http.Request, path string, handler SSEHandler) error {
+	config, onError := getSSEConfig()
+	setSSEHeaders(w.Header().Set)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	write := func(p []byte) error {
+		if err := rc.SetWriteDeadline(time.Now().Add(config.WriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		if _, err := w.Write(p); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	sw := newSSEWriter(write, r.Header.Get("Last-Event-ID"), config)
+	// 先发出响应头，使客户端尽快进入open状态
+	if err := write(nil); err != nil {
+		return nil
+	}
+
+	// 请求上下文在客户端断开时取消
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-r.Context().Done():
+			sw.disconnect()
+		case <-stop:
+		}
+	}()
+
+	if err := sw.run(handler); err != nil && onError != nil {
+		onError(path, err)
+	}
+	return nil
+}
+
+// SSE 实现Context接口
+func (c *GinContext) SSE(handler SSEHandler) error {
+	return serveHTTPSSE(c.ctx.Writer, c.ctx.Request, c.Path(), handler)
+}
+
+// SSE 实现Context接口
+func (c *StdContext) SSE(handler SSEHandler) error {
+	return serveHTTPSSE(c.writer, c.request, c.Path(), handler)
+}
+
+// SSE 实现Context接口
+// fasthttp在处理函数链返回后才通过SetBodyStreamWriter写入响应体，
+// 此时fiber上下文已被回收，因此需要预先复制请求信息，事件流在之后独立运行
+func (c *FiberContext) SSE(handler SSEHandler) error {
+	config, onError := getSSEConfig()
+	path := strings.Clone(c.Path())
+	lastEventID := strings.Clone(c.ctx.Get("Last-Event-ID"))
+	conn := c.ctx.Context().Conn()
+
+	c.ctx.Status(http.StatusOK)
+	setSSEHeaders(c.ctx.Set)
+	c.ctx.Context().SetBodyStreamWriter(func(bw *bufio.Writer) {
+		write := func(p []byte) error {
+			// fasthttp只在开始写响应时设置一次写超时，这里逐次延长，避免长连接被中断
+			if err := conn.SetWriteDeadline(time.Now().Add(config.WriteTimeout)); err != nil {
+				return err
+			}
+			if _, err := bw.Write(p); err != nil {
+				return err
+			}
+			return bw.Flush()
+		}
+
+		sw := newSSEWriter(write, lastEventID, config)
+		if err := sw.run(handler); err != nil && onError != nil {
+			onError(path, err)
+		}
+	})
+	return nil
+}
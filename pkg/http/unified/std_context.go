@@ -0,0 +1,473 @@
+package unified
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 表单解析的默认内存上限
+const defaultMultipartMemory = 32 << 20 // 32 MB
+
+// StdResponseWriter 包装http.ResponseWriter，延迟写入状态码并记录响应信息
+type StdResponseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+// newStdResponseWriter 创建响应包装器
+func newStdResponseWriter(w http.ResponseWriter) *StdResponseWriter {
+	return &StdResponseWriter{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+		size:           -1,
+	}
+}
+
+// WriteHeader 记录状态码，真正写入发生在第一次写响应体或请求结束时
+func (w *StdResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.Written() {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow 立即写入状态码
+func (w *StdResponseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// Write 实现io.Writer接口
+func (w *StdResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+// WriteString 实现io.StringWriter接口
+func (w *StdResponseWriter) WriteString(s string) (int, error) {
+	w.WriteHeaderNow()
+	n, err := io.WriteString(w.ResponseWriter, s)
+	w.size += n
+	return n, err
+}
+
+// Status 获取响应状态码
+func (w *StdResponseWriter) Status() int {
+	return w.status
+}
+
+// Size 获取已写入的响应体大小
+func (w *StdResponseWriter) Size() int {
+	return w.size
+}
+
+// Written 是否已经写入状态码
+func (w *StdResponseWriter) Written() bool {
+	return w.size != -1
+}
+
+// Flush 实现http.Flusher接口
+func (w *StdResponseWriter) Flush() {
+	w.WriteHeaderNow()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现http.Hijacker接口
+func (w *StdResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	// 通过ResponseController逐层Unwrap，兼容日志等中间件包装后的ResponseWriter
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, errors.New("响应不支持Hijack")
+	}
+	if w.size < 0 {
+		w.size = 0
+	}
+	return conn, rw, nil
+}
+
+// Unwrap 返回原始的ResponseWriter，供http.ResponseController使用
+func (w *StdResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// StdContext 是标准库net/http的上下文适配器
+type StdContext struct {
+	writer   *StdResponseWriter
+	request  *http.Request
+	fullPath string
+	keys     map[string]interface{}
+	errors   []error
+	aborted  bool
+}
+
+// NewStdContext 创建一个标准库上下文适配器
+func NewStdContext(w http.ResponseWriter, r *http.Request) *StdContext {
+	writer, ok := w.(*StdResponseWriter)
+	if !ok {
+		writer = newStdResponseWriter(w)
+	}
+	return &StdContext{
+		writer:  writer,
+		request: r,
+	}
+}
+
+// Method 实现Context接口
+func (c *StdContext) Method() string {
+	return c.request.Method
+}
+
+// Path 实现Context接口
+func (c *StdContext) Path() string {
+	return c.fullPath
+}
+
+// Host 实现Context接口
+func (c *StdContext) Host() string {
+	return c.request.Host
+}
+
+// URL 实现Context接口
+func (c *StdContext) URL() *url.URL {
+	return c.request.URL
+}
+
+// ClientIP 实现Context接口
+func (c *StdContext) ClientIP() string {
+	return clientIP(c.request.Header.Get("X-Forwarded-For"), c.request.Header.Get("X-Real-IP"), c.request.RemoteAddr)
+}
+
+// GetHeader 实现Context接口
+func (c *StdContext) GetHeader(key string) string {
+	return c.request.Header.Get(key)
+}
+
+// SetHeader 实现Context接口
+func (c *StdContext) SetHeader(key, value string) {
+	if value == "" {
+		c.writer.Header().Del(key)
+		return
+	}
+	c.writer.Header().Set(key, value)
+}
+
+// Query 实现Context接口
+func (c *StdContext) Query(key string) string {
+	return c.request.URL.Query().Get(key)
+}
+
+// QueryDefault 实现Context接口
+func (c *StdContext) QueryDefault(key, defaultValue string) string {
+	if values, ok := c.request.URL.Query()[key]; ok && len(values) > 0 {
+		return values[0]
+	}
+	return defaultValue
+}
+
+// QueryMap 实现Context接口
+func (c *StdContext) QueryMap() map[string]string {
+	result := make(map[string]string)
+	for k, v := range c.request.URL.Query() {
+		if len(v) > 0 {
+			result[k] = v[0]
+		}
+	}
+	return result
+}
+
+// Param 实现Context接口
+func (c *StdContext) Param(key string) string {
+	return c.request.PathValue(key)
+}
+
+// ParamInt 实现Context接口
+func (c *StdContext) ParamInt(key string) (int, error) {
+	return strconv.Atoi(c.Param(key))
+}
+
+// ParamUint 实现Context接口
+func (c *StdContext) ParamUint(key string) (uint, error) {
+	val, err := strconv.ParseUint(c.Param(key), 10, 64)
+	return uint(val), err
+}
+
+// BindJSON 实现Context接口
+func (c *StdContext) BindJSON(obj interface{}) error {
+	if c.request.Body == nil {
+		return errors.New("请求体为空")
+	}
+	return json.NewDecoder(c.request.Body).Decode(obj)
+}
+
+// BindQuery 实现Context接口
+func (c *StdContext) BindQuery(obj interface{}) error {
+	return bindValues(obj, "form", c.request.URL.Query(), nil)
+}
+
+// BindForm 实现Context接口
+func (c *StdContext) BindForm(obj interface{}) error {
+	if strings.HasPrefix(c.request.Header.Get("Content-Type"), "application/json") {
+		return c.BindJSON(obj)
+	}
+	if err := c.parseForm(); err != nil {
+		return err
+	}
+	var files map[string][]*multipart.FileHeader
+	if c.request.MultipartForm != nil {
+		files = c.request.MultipartForm.File
+	}
+	return bindValues(obj, "form", c.request.Form, files)
+}
+
+// Bind 实现Context接口
+func (c *StdContext) Bind(obj interface{}) error {
+	return bind(c, obj, func() ([]byte, error) {
+		if c.request.Body == nil {
+			return nil, errors.New("请求体为空")
+		}
+		return io.ReadAll(c.request.Body)
+	})
+}
+
+// parseForm 解析表单，兼容普通表单和multipart表单
+func (c *StdContext) parseForm() error {
+	if strings.HasPrefix(c.request.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := c.request.ParseMultipartForm(defaultMultipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			return err
+		}
+		return nil
+	}
+	return c.request.ParseForm()
+}
+
+// FormFile 实现Context接口
+func (c *StdContext) FormFile(name string) (*multipart.FileHeader, error) {
+	if c.request.MultipartForm == nil {
+		if err := c.request.ParseMultipartForm(defaultMultipartMemory); err != nil {
+			return nil, err
+		}
+	}
+	f, fh, err := c.request.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	return fh, nil
+}
+
+// FormValue 实现Context接口
+func (c *StdContext) FormValue(name string) string {
+	if err := c.parseForm(); err != nil {
+		return ""
+	}
+	return c.request.PostFormValue(name)
+}
+
+// Status 实现Context接口
+func (c *StdContext) Status(code int) Context {
+	c.writer.WriteHeader(code)
+	return c
+}
+
+// render 写入响应头和响应体
+func (c *StdContext) render(code int, contentType string, data []byte) error {
+	if contentType != "" {
+		c.writer.Header().Set("Content-Type", contentType)
+	}
+	c.writer.WriteHeader(code)
+	c.writer.WriteHeaderNow()
+	if !bodyAllowedForStatus(code) {
+		return nil
+	}
+	_, err := c.writer.Write(data)
+	return err
+}
+
+// JSON 实现Context接口
+func (c *StdContext) JSON(code int, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return c.render(code, jsonContentType, data)
+}
+
+// String 实现Context接口
+func (c *StdContext) String(code int, format string, values ...interface{}) error {
+	text := format
+	if len(values) > 0 {
+		text = fmt.Sprintf(format, values...)
+	}
+	return c.render(code, "text/plain; charset=utf-8", []byte(text))
+}
+
+// HTML 实现Context接口
+func (c *StdContext) HTML(code int, html string) error {
+	return c.render(code, "text/html", []byte(html))
+}
+
+// XML 实现Context接口
+func (c *StdContext) XML(code int, obj interface{}) error {
+	return render(c, code, xmlCodec{}, obj)
+}
+
+// Data 实现Context接口
+func (c *StdContext) Data(code int, contentType string, data []byte) error {
+	return c.render(code, contentType, data)
+}
+
+// Negotiate 实现Context接口
+func (c *StdContext) Negotiate(code int, data interface{}) error {
+	return negotiate(c, code, data)
+}
+
+// Redirect 实现Context接口
+func (c *StdContext) Redirect(code int, url string) error {
+	if err := checkRedirectCode(code); err != nil {
+		return err
+	}
+	http.Redirect(c.writer, c.request, url, code)
+	return nil
+}
+
+// File 实现Context接口
+func (c *StdContext) File(filepath string) error {
+	http.ServeFile(c.writer, c.request, filepath)
+	return nil
+}
+
+// Stream 实现Context接口
+func (c *StdContext) Stream(contentType string, r io.Reader) error {
+	c.writer.Header().Set("Content-Type", contentType)
+	streamResponse(c.writer)
+	c.writer.WriteHeaderNow()
+	_, err := io.Copy(c.writer, r)
+	return err
+}
+
+// Set 实现Context接口
+func (c *StdContext) Set(key string, value interface{}) {
+	if c.keys == nil {
+		c.keys = make(map[string]interface{})
+	}
+	c.keys[key] = value
+}
+
+// Get 实现Context接口
+func (c *StdContext) Get(key string) (interface{}, bool) {
+	val, ok := c.keys[key]
+	return val, ok
+}
+
+// MustGet 实现Context接口
+func (c *StdContext) MustGet(key string) interface{} {
+	if val, ok := c.Get(key); ok {
+		return val
+	}
+	panic("key " + key + " not found")
+}
+
+// GinContext 实现Context接口
+func (c *StdContext) GinContext() interface{} {
+	return nil
+}
+
+// FiberContext 实现Context接口
+func (c *StdContext) FiberContext() interface{} {
+	return nil
+}
+
+// Context 实现Context接口
+func (c *StdContext) Context() context.Context {
+	return c.request.Context()
+}
+
+// SetContext 实现Context接口
+func (c *StdContext) SetContext(ctx context.Context) {
+	c.request = c.request.WithContext(ctx)
+}
+
+// GetRequest 实现Context接口
+func (c *StdContext) GetRequest() *http.Request {
+	return c.request
+}
+
+// GetResponse 实现Context接口
+func (c *StdContext) GetResponse() http.ResponseWriter {
+	return c.writer
+}
+
+// Error 实现Context接口
+func (c *StdContext) Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	c.errors = append(c.errors, err)
+	return err
+}
+
+// HasErrors 实现Context接口
+func (c *StdContext) HasErrors() bool {
+	return len(c.errors) > 0
+}
+
+// Errors 实现Context接口
+func (c *StdContext) Errors() []error {
+	return c.errors
+}
+
+// Next 实现Context接口
+// 标准库没有处理链，中间件通过包装HandlerFunc串联，这里无需操作
+func (c *StdContext) Next() {}
+
+// IsAborted 实现Context接口
+func (c *StdContext) IsAborted() bool {
+	return c.aborted
+}
+
+// Abort 实现Context接口
+func (c *StdContext) Abort() {
+	c.aborted = true
+}
+
+// AbortWithStatus 实现Context接口
+func (c *StdContext) AbortWithStatus(code int) {
+	c.Abort()
+	c.writer.WriteHeader(code)
+	c.writer.WriteHeaderNow()
+}
+
+// AbortWithJSON 实现Context接口
+func (c *StdContext) AbortWithJSON(code int, obj interface{}) error {
+	c.Abort()
+	return c.JSON(code, obj)
+}
+
+// bodyAllowedForStatus 判断状态码是否允许携带响应体
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
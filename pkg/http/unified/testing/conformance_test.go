@@ -0,0 +1,132 @@
+package testing_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	unifiedtesting "github.com/zhoudm1743/go-frame/pkg/http/unified/testing"
+)
+
+func TestConformance(t *testing.T) {
+	unifiedtesting.RunConformance(t, unified.GinEngine, unified.FiberEngine, unified.StdEngine)
+}
+
+// 根路径和以"/"结尾的路由只匹配该路径，未注册的路径在各引擎上都返回404
+func TestExactRoutes(t *testing.T) {
+	h := unifiedtesting.New()
+	defer h.Close()
+
+	ok := func(c unified.Context) error {
+		return c.String(http.StatusOK, c.Path())
+	}
+	h.Router().GET("/", ok)
+	h.Router().GET("/dir/", ok)
+
+	cases := map[string]int{
+		"/":        http.StatusOK,
+		"/dir/":    http.StatusOK,
+		"/nope":    http.StatusNotFound,
+		"/nope/x":  http.StatusNotFound,
+		"/dir/sub": http.StatusNotFound,
+	}
+	for target, status := range cases {
+		responses, err := h.Do(unifiedtesting.NewRequest(http.MethodGet, target, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, resp := range responses {
+			if resp.Status != status {
+				t.Errorf("%s %s: 状态码 %d，期望 %d", resp.Engine, target, resp.Status, status)
+			}
+		}
+	}
+}
+
+// 全局、路由组和路由的中间件按注册顺序执行
+func TestMiddlewareOrder(t *testing.T) {
+	h := unifiedtesting.New()
+	defer h.Close()
+
+	mark := func(name string) unified.MiddlewareFunc {
+		return func(next unified.HandlerFunc) unified.HandlerFunc {
+			return func(c unified.Context) error {
+				order, _ := c.Get("order")
+				s, _ := order.(string)
+				c.Set("order", s+name)
+				return next(c)
+			}
+		}
+	}
+	h.Router().Use(mark("a"))
+	group := h.Router().Group("/g", mark("b"), mark("c"))
+	group.Use(mark("d"))
+	group.GET("/order", func(c unified.Context) error {
+		order, _ := c.Get("order")
+		return c.String(http.StatusOK, order.(string))
+	}, mark("e"), mark("f"))
+
+	responses, err := h.Do(unifiedtesting.NewRequest(http.MethodGet, "/g/order", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, resp := range responses {
+		if order := string(resp.Body); order != "abcdef" {
+			t.Errorf("%s: 执行顺序 %q，期望 \"abcdef\"", resp.Engine, order)
+		}
+	}
+}
+
+// 声明了权限的路由由授权中间件检查，没有使用授权中间件时CheckAuthorization返回错误
+func TestAuthorize(t *testing.T) {
+	h := unifiedtesting.New()
+	defer h.Close()
+
+	errDenied := errors.New("denied")
+	authorize := unified.Authorize(func(c unified.Context, permissions []string) error {
+		if c.GetHeader("X-Role") != strings.Join(permissions, ",") {
+			c.String(http.StatusForbidden, "denied")
+			return errDenied
+		}
+		return nil
+	})
+	ok := func(c unified.Context) error {
+		return c.String(http.StatusOK, "ok")
+	}
+	group := h.Router().Group("/auth", authorize)
+	group.GET("/open", ok)
+	group.GET("/admin", ok).Permission("user:read", "user:write")
+
+	if err := unified.CheckAuthorization(h.Router().Routes()); err != nil {
+		t.Fatalf("所有声明权限的路由都使用了授权中间件: %v", err)
+	}
+
+	cases := []struct {
+		req    *unifiedtesting.Request
+		status int
+	}{
+		{unifiedtesting.NewRequest(http.MethodGet, "/auth/open", nil), http.StatusOK},
+		{unifiedtesting.NewRequest(http.MethodGet, "/auth/admin", nil), http.StatusForbidden},
+		{unifiedtesting.NewRequest(http.MethodGet, "/auth/admin", nil).WithHeader("X-Role", "user:read"), http.StatusForbidden},
+		{unifiedtesting.NewRequest(http.MethodGet, "/auth/admin", nil).WithHeader("X-Role", "user:read,user:write"), http.StatusOK},
+	}
+	for _, tc := range cases {
+		responses, err := h.Do(tc.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, resp := range responses {
+			if resp.Status != tc.status {
+				t.Errorf("%s %s: 状态码 %d，期望 %d", resp.Engine, tc.req, resp.Status, tc.status)
+			}
+		}
+	}
+
+	h.Router().GET("/unprotected", ok).Permission("user:delete")
+	err := unified.CheckAuthorization(h.Router().Routes())
+	if err == nil || !strings.Contains(err.Error(), "GET /unprotected") || strings.Contains(err.Error(), "/auth/admin") {
+		t.Errorf("CheckAuthorization = %v，期望只包含 GET /unprotected", err)
+	}
+}
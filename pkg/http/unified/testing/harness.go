@@ -0,0 +1,422 @@
+// Package testing 统一处理函数的引擎一致性测试工具
+// 在Gin、Fiber和标准库引擎上注册同一组处理函数，发送相同的请求并比较状态码、响应头和响应体，
+// 用于在切换http.engine之前发现各引擎Context实现之间的差异
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// TB 测试断言接口，*testing.T和*testing.B都实现了此接口
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// DefaultHost 请求使用的Host，各引擎监听的端口不同，统一Host后Host()和URL()的结果才能比较
+const DefaultHost = "conformance.test"
+
+// defaultIgnoredHeaders 默认不参与比较的响应头，这些响应头由传输层决定，与Context实现无关
+var defaultIgnoredHeaders = []string{"Date", "Content-Length", "Connection", "Keep-Alive"}
+
+// Request 发送给各引擎的请求，请求体以字节保存，可以重复发送
+type Request struct {
+	Method string
+	// Target 请求路径，可以带查询参数
+	Target string
+	Header http.Header
+	Body   []byte
+}
+
+// NewRequest 创建请求
+func NewRequest(method, target string, body []byte) *Request {
+	return &Request{
+		Method: method,
+		Target: target,
+		Header: http.Header{},
+		Body:   body,
+	}
+}
+
+// JSONRequest 创建JSON请求
+func JSONRequest(method, target string, v interface{}) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("序列化请求体失败: %v", err))
+	}
+	return NewRequest(method, target, body).WithHeader("Content-Type", unified.MIMEJSON)
+}
+
+// FormRequest 创建表单请求
+func FormRequest(method, target string, values url.Values) *Request {
+	return NewRequest(method, target, []byte(values.Encode())).WithHeader("Content-Type", unified.MIMEForm)
+}
+
+// FormFile multipart表单中的文件
+type FormFile struct {
+	Field    string
+	Filename string
+	Content  []byte
+}
+
+// MultipartRequest 创建multipart表单请求，分隔符固定，保证各引擎收到的请求体完全相同
+func MultipartRequest(method, target string, values url.Values, files ...FormFile) *Request {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary("conformance-boundary"); err != nil {
+		panic(err)
+	}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range values[k] {
+			w.WriteField(k, v)
+		}
+	}
+	for _, f := range files {
+		part, err := w.CreateFormFile(f.Field, f.Filename)
+		if err != nil {
+			panic(err)
+		}
+		part.Write(f.Content)
+	}
+	w.Close()
+	return NewRequest(method, target, buf.Bytes()).WithHeader("Content-Type", w.FormDataContentType())
+}
+
+// WithHeader 设置请求头
+func (r *Request) WithHeader(key, value string) *Request {
+	r.Header.Set(key, value)
+	return r
+}
+
+// String 请求的简要描述
+func (r *Request) String() string {
+	return r.Method + " " + r.Target
+}
+
+// Response 引擎返回的响应
+type Response struct {
+	Engine unified.EngineType
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Option 比较选项
+type Option func(*compareOptions)
+
+// compareOptions 比较选项
+type compareOptions struct {
+	ignored   map[string]bool
+	normalize []func(*Response)
+}
+
+// IgnoreHeaders 忽略指定的响应头
+func IgnoreHeaders(keys ...string) Option {
+	return func(o *compareOptions) {
+		for _, k := range keys {
+			o.ignored[http.CanonicalHeaderKey(k)] = true
+		}
+	}
+}
+
+// Normalize 比较前处理响应，例如去掉响应体中的时间戳
+func Normalize(fn func(r *Response)) Option {
+	return func(o *compareOptions) {
+		o.normalize = append(o.normalize, fn)
+	}
+}
+
+// engineServer 一个引擎的路由器和监听的服务器
+type engineServer struct {
+	engine  unified.EngineType
+	router  unified.Router
+	baseURL string
+	start   func() (string, error)
+	close   func() error
+}
+
+// Harness 在多个引擎上运行相同的处理函数
+// 路由需要在第一次发送请求之前注册，第一次发送请求时各引擎才开始监听
+type Harness struct {
+	servers   []*engineServer
+	router    unified.Router
+	client    *http.Client
+	startOnce sync.Once
+	startErr  error
+}
+
+// New 创建测试工具，不指定引擎时比较Gin、Fiber和标准库引擎，第一个引擎作为比较的基准
+func New(engines ...unified.EngineType) *Harness {
+	if len(engines) == 0 {
+		engines = []unified.EngineType{unified.GinEngine, unified.FiberEngine, unified.StdEngine}
+	}
+
+	h := &Harness{
+		client: &http.Client{
+			// 重定向和压缩都由测试自行检查
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Transport: &http.Transport{DisableCompression: true},
+		},
+	}
+	routers := make([]unified.Router, 0, len(engines))
+	for _, engine := range engines {
+		s := newEngineServer(engine)
+		h.servers = append(h.servers, s)
+		routers = append(routers, s.router)
+	}
+	h.router = &multiRouter{routers: routers}
+	return h
+}
+
+// newEngineServer 创建引擎，配置与统一服务器一致：处理函数返回的错误交给统一错误处理
+func newEngineServer(engine unified.EngineType) *engineServer {
+	s := &engineServer{engine: engine}
+	switch engine {
+	case unified.FiberEngine:
+		app := fiber.New(fiber.Config{
+			DisableStartupMessage: true,
+			ErrorHandler: func(c *fiber.Ctx, err error) error {
+				// 未匹配路由等Fiber自身的错误与其他引擎一样只返回状态码
+				var fiberErr *fiber.Error
+				if errors.As(err, &fiberErr) {
+					return fiber.DefaultErrorHandler(c, err)
+				}
+				unified.HandleError(unified.NewFiberContext(c), err)
+				return nil
+			},
+		})
+		s.router = unified.NewRouter(unified.FiberEngine, nil, app)
+		s.start = func() (string, error) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				return "", err
+			}
+			go app.Listener(ln)
+			return "http://" + ln.Addr().String(), nil
+		}
+		s.close = app.Shutdown
+	case unified.StdEngine:
+		mux := http.NewServeMux()
+		s.router = unified.NewStdRouter(mux)
+		s.start = httpStarter(s, mux)
+	default:
+		gin.SetMode(gin.ReleaseMode)
+		e := gin.New()
+		s.router = unified.NewRouter(unified.GinEngine, e, nil)
+		s.start = httpStarter(s, e)
+	}
+	return s
+}
+
+// httpStarter 使用httptest.Server监听net/http处理器
+func httpStarter(s *engineServer, handler http.Handler) func() (string, error) {
+	return func() (string, error) {
+		srv := httptest.NewServer(handler)
+		s.close = func() error {
+			srv.Close()
+			return nil
+		}
+		return srv.URL, nil
+	}
+}
+
+// Router 获取路由器，注册的路由会同时注册到所有引擎
+func (h *Harness) Router() unified.Router {
+	return h.router
+}
+
+// Engines 获取参与比较的引擎
+func (h *Harness) Engines() []unified.EngineType {
+	engines := make([]unified.EngineType, len(h.servers))
+	for i, s := range h.servers {
+		engines[i] = s.engine
+	}
+	return engines
+}
+
+// Close 关闭所有引擎
+func (h *Harness) Close() error {
+	var firstErr error
+	for _, s := range h.servers {
+		if s.close == nil {
+			continue
+		}
+		if err := s.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	h.client.CloseIdleConnections()
+	return firstErr
+}
+
+// start 启动所有引擎
+func (h *Harness) start() error {
+	h.startOnce.Do(func() {
+		for _, s := range h.servers {
+			baseURL, err := s.start()
+			if err != nil {
+				h.startErr = fmt.Errorf("启动%s引擎失败: %w", s.engine, err)
+				return
+			}
+			s.baseURL = baseURL
+		}
+	})
+	return h.startErr
+}
+
+// Do 将请求依次发送给各引擎，按引擎顺序返回响应
+func (h *Harness) Do(req *Request) ([]*Response, error) {
+	if err := h.start(); err != nil {
+		return nil, err
+	}
+	responses := make([]*Response, 0, len(h.servers))
+	for _, s := range h.servers {
+		resp, err := h.send(s, req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", s.engine, req, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// send 向一个引擎发送请求
+func (h *Harness) send(s *engineServer, req *Request) (*Response, error) {
+	r, err := http.NewRequest(req.Method, s.baseURL+req.Target, bytes.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	r.Host = DefaultHost
+	for k, v := range req.Header {
+		r.Header[k] = append([]string(nil), v...)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		r.Host = host
+	}
+
+	resp, err := h.client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		Engine: s.engine,
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   body,
+	}, nil
+}
+
+// Compare 发送请求并与第一个引擎的响应比较，返回响应和差异描述
+func (h *Harness) Compare(req *Request, opts ...Option) ([]*Response, []string, error) {
+	responses, err := h.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o := &compareOptions{ignored: map[string]bool{}}
+	IgnoreHeaders(defaultIgnoredHeaders...)(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, resp := range responses {
+		for k := range resp.Header {
+			if o.ignored[k] {
+				resp.Header.Del(k)
+			}
+		}
+		for _, fn := range o.normalize {
+			fn(resp)
+		}
+	}
+
+	base := responses[0]
+	var diffs []string
+	for _, resp := range responses[1:] {
+		diffs = append(diffs, diffResponse(base, resp)...)
+	}
+	return responses, diffs, nil
+}
+
+// AssertSame 断言各引擎对请求的响应一致
+func (h *Harness) AssertSame(t TB, req *Request, opts ...Option) []*Response {
+	t.Helper()
+	responses, diffs, err := h.Compare(req, opts...)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	for _, d := range diffs {
+		t.Errorf("%s: %s", req, d)
+	}
+	return responses
+}
+
+// diffResponse 比较两个响应
+func diffResponse(base, other *Response) []string {
+	var diffs []string
+	if base.Status != other.Status {
+		diffs = append(diffs, fmt.Sprintf("状态码不一致: %s=%d %s=%d", base.Engine, base.Status, other.Engine, other.Status))
+	}
+
+	keys := map[string]bool{}
+	for k := range base.Header {
+		keys[k] = true
+	}
+	for k := range other.Header {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		bv, ov := base.Header.Values(k), other.Header.Values(k)
+		if strings.Join(bv, "\n") != strings.Join(ov, "\n") {
+			diffs = append(diffs, fmt.Sprintf("响应头%s不一致: %s=%q %s=%q", k, base.Engine, bv, other.Engine, ov))
+		}
+	}
+
+	if !bytes.Equal(base.Body, other.Body) {
+		diffs = append(diffs, fmt.Sprintf("响应体不一致: %s=%q %s=%q", base.Engine, abbreviate(base.Body), other.Engine, abbreviate(other.Body)))
+	}
+	return diffs
+}
+
+// abbreviate 截断过长的响应体
+func abbreviate(body []byte) string {
+	const max = 512
+	if len(body) > max {
+		return string(body[:max]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,107 @@
+package testing
+
+import "github.com/zhoudm1743/go-frame/pkg/http/unified"
+
+// multiRouter 将路由同时注册到多个引擎的路由器
+type multiRouter struct {
+	routers []unified.Router
+}
+
+// each 对每个路由器执行注册操作
+func (m *multiRouter) each(fn func(r unified.Router) unified.Router) unified.Router {
+	for i, r := range m.routers {
+		m.routers[i] = fn(r)
+	}
+	return m
+}
+
+// GET 实现Router接口
+func (m *multiRouter) GET(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.GET, path, handler, middlewares...)
+}
+
+// POST 实现Router接口
+func (m *multiRouter) POST(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.POST, path, handler, middlewares...)
+}
+
+// PUT 实现Router接口
+func (m *multiRouter) PUT(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.PUT, path, handler, middlewares...)
+}
+
+// DELETE 实现Router接口
+func (m *multiRouter) DELETE(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.DELETE, path, handler, middlewares...)
+}
+
+// PATCH 实现Router接口
+func (m *multiRouter) PATCH(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.PATCH, path, handler, middlewares...)
+}
+
+// OPTIONS 实现Router接口
+func (m *multiRouter) OPTIONS(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.OPTIONS, path, handler, middlewares...)
+}
+
+// HEAD 实现Router接口
+func (m *multiRouter) HEAD(path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.Handle(unified.HEAD, path, handler, middlewares...)
+}
+
+// WebSocket 实现Router接口
+func (m *multiRouter) WebSocket(path string, handler unified.WSHandler, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.WebSocket(path, handler, middlewares...)
+	})
+}
+
+// Static 实现Router接口
+func (m *multiRouter) Static(prefix, root string) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.Static(prefix, root)
+	})
+}
+
+// Group 实现Router接口
+func (m *multiRouter) Group(prefix string, middlewares ...unified.MiddlewareFunc) unified.Router {
+	group := &multiRouter{routers: make([]unified.Router, len(m.routers))}
+	for i, r := range m.routers {
+		group.routers[i] = r.Group(prefix, middlewares...)
+	}
+	return group
+}
+
+// Use 实现Router接口
+func (m *multiRouter) Use(middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.Use(middlewares...)
+	})
+}
+
+// Handle 实现Router接口
+func (m *multiRouter) Handle(method unified.HTTPMethod, path string, handler unified.HandlerFunc, middlewares ...unified.MiddlewareFunc) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.Handle(method, path, handler, middlewares...)
+	})
+}
+
+// Routes 实现Router接口，各引擎的路由表相同，返回第一个引擎的路由
+func (m *multiRouter) Routes() []unified.RouteInfo {
+	return m.routers[0].Routes()
+}
+
+// Doc 实现Router接口
+func (m *multiRouter) Doc(doc unified.RouteDoc) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.Doc(doc)
+	})
+}
+
+// Permission 实现Router接口
+func (m *multiRouter) Permission(permissions ...string) unified.Router {
+	return m.each(func(r unified.Router) unified.Router {
+		return r.Permission(permissions...)
+	})
+}
@@ -0,0 +1,883 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// Case 一致性测试用例
+type Case struct {
+	// Name 用例名称，通常是被测试的Context方法
+	Name string
+	// Mount 注册用例使用的路由
+	Mount func(r unified.Router)
+	// Requests 依次发送并比较的请求
+	Requests []*Request
+	// Options 比较选项
+	Options []Option
+	// Run 需要多个步骤的用例，例如先获取Cookie再带回，设置后Requests仍会先执行
+	Run func(t TB, h *Harness)
+}
+
+// conformanceCookieConfig 一致性测试使用的Cookie密钥
+var conformanceCookieConfig = unified.CookieConfig{
+	SignKey:    "conformance-sign-key",
+	EncryptKey: "conformance-encrypt-key",
+}
+
+// fixtureContent File用例使用的文件内容
+const fixtureContent = "统一上下文一致性测试\n"
+
+// bindPayload 绑定用例使用的请求参数
+type bindPayload struct {
+	Name string   `json:"name" form:"name" xml:"name"`
+	Age  int      `json:"age" form:"age" xml:"age"`
+	Tags []string `json:"tags" form:"tags" xml:"tags"`
+}
+
+// RunConformance 在各引擎上运行Suite中的所有用例，不指定引擎时比较Gin、Fiber和标准库引擎，例如:
+//
+//	func TestConformance(t *testing.T) {
+//		unifiedtesting.RunConformance(t)
+//	}
+//
+// 签名和加密Cookie用例需要密钥，运行期间会使用测试密钥替换Cookie配置
+func RunConformance(t TB, engines ...unified.EngineType) {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "unified-conformance")
+	if err != nil {
+		t.Fatalf("创建测试目录失败: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	cases, err := Suite(dir)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	if err := unified.SetCookieConfig(conformanceCookieConfig); err != nil {
+		t.Fatalf("设置Cookie配置失败: %v", err)
+		return
+	}
+	defer unified.SetCookieConfig(unified.CookieConfig{})
+
+	h := New(engines...)
+	defer h.Close()
+	RunCases(t, h, cases)
+}
+
+// RunCases 在测试工具上注册并运行用例
+func RunCases(t TB, h *Harness, cases []Case) {
+	t.Helper()
+	for _, c := range cases {
+		if c.Mount != nil {
+			c.Mount(h.Router())
+		}
+	}
+	for _, c := range cases {
+		for _, req := range c.Requests {
+			_, diffs, err := h.Compare(req, c.Options...)
+			if err != nil {
+				t.Fatalf("%s: %v", c.Name, err)
+				return
+			}
+			for _, d := range diffs {
+				t.Errorf("%s: %s: %s", c.Name, req, d)
+			}
+		}
+		if c.Run != nil {
+			c.Run(&namedTB{TB: t, name: c.Name}, h)
+		}
+	}
+}
+
+// namedTB 在错误信息前加上用例名称
+type namedTB struct {
+	TB
+	name string
+}
+
+// Errorf 实现TB接口
+func (n *namedTB) Errorf(format string, args ...interface{}) {
+	n.TB.Helper()
+	n.TB.Errorf("%s: %s", n.name, fmt.Sprintf(format, args...))
+}
+
+// Fatalf 实现TB接口
+func (n *namedTB) Fatalf(format string, args ...interface{}) {
+	n.TB.Helper()
+	n.TB.Fatalf("%s: %s", n.name, fmt.Sprintf(format, args...))
+}
+
+// contextKey 测试SetContext使用的键
+type contextKey struct{}
+
+// echo 以JSON输出处理结果
+func echo(c unified.Context, data map[string]interface{}) error {
+	return c.JSON(http.StatusOK, data)
+}
+
+// errString 将错误转换为可比较的值，各引擎的错误信息不同，只比较是否出错
+func errString(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return "error"
+}
+
+// Suite 覆盖Context接口所有方法的一致性测试用例，fixtures为存放测试文件的目录
+func Suite(fixtures string) ([]Case, error) {
+	fixture := filepath.Join(fixtures, "fixture.txt")
+	if err := os.WriteFile(fixture, []byte(fixtureContent), 0o644); err != nil {
+		return nil, fmt.Errorf("创建测试文件失败: %w", err)
+	}
+	static := filepath.Join(fixtures, "static")
+	if err := os.MkdirAll(static, 0o755); err != nil {
+		return nil, fmt.Errorf("创建测试目录失败: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(static, "app.js"), []byte("console.log('ok')\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("创建测试文件失败: %w", err)
+	}
+
+	return []Case{
+		{
+			Name: "Method/Path/Host/URL",
+			Mount: func(r unified.Router) {
+				handler := func(c unified.Context) error {
+					u := c.URL()
+					return echo(c, map[string]interface{}{
+						"method":   c.Method(),
+						"path":     c.Path(),
+						"host":     c.Host(),
+						"urlPath":  u.Path,
+						"rawQuery": u.RawQuery,
+						"query":    u.Query(),
+					})
+				}
+				r.GET("/c/info/:id", handler)
+				r.POST("/c/info/:id", handler)
+				r.DELETE("/c/info/:id", handler)
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/info/1", nil),
+				NewRequest(http.MethodGet, "/c/info/2?a=1&b=%E4%B8%AD", nil),
+				NewRequest(http.MethodPost, "/c/info/3", nil).WithHeader("Host", "example.com:8080"),
+				NewRequest(http.MethodDelete, "/c/info/4", nil),
+			},
+		},
+		{
+			Name: "ClientIP",
+			Mount: func(r unified.Router) {
+				r.GET("/c/ip", func(c unified.Context) error {
+					return echo(c, map[string]interface{}{"ip": c.ClientIP()})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/ip", nil),
+				NewRequest(http.MethodGet, "/c/ip", nil).WithHeader("X-Forwarded-For", "203.0.113.7, 10.0.0.1"),
+				NewRequest(http.MethodGet, "/c/ip", nil).WithHeader("X-Real-IP", "203.0.113.8"),
+			},
+		},
+		{
+			Name: "GetHeader/SetHeader",
+			Mount: func(r unified.Router) {
+				r.GET("/c/header", func(c unified.Context) error {
+					c.SetHeader("X-Reply", c.GetHeader("X-Test"))
+					c.SetHeader("X-Overwrite", "a")
+					c.SetHeader("X-Overwrite", "b")
+					c.SetHeader("X-Removed", "a")
+					c.SetHeader("X-Removed", "")
+					return echo(c, map[string]interface{}{
+						"test":    c.GetHeader("x-test"),
+						"missing": c.GetHeader("X-Missing"),
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/header", nil).WithHeader("X-Test", "值"),
+				NewRequest(http.MethodGet, "/c/header", nil),
+			},
+		},
+		{
+			Name: "Query/QueryDefault/QueryMap",
+			Mount: func(r unified.Router) {
+				r.GET("/c/query", func(c unified.Context) error {
+					return echo(c, map[string]interface{}{
+						"a":        c.Query("a"),
+						"missing":  c.Query("missing"),
+						"empty":    c.QueryDefault("empty", "default"),
+						"fallback": c.QueryDefault("missing", "default"),
+						"unicode":  c.Query("u"),
+						"map":      c.QueryMap(),
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/query?a=1&a=2&empty=&u=%E4%B8%AD%E6%96%87&plus=a+b", nil),
+				NewRequest(http.MethodGet, "/c/query", nil),
+			},
+		},
+		{
+			Name: "Param/ParamInt/ParamUint",
+			Mount: func(r unified.Router) {
+				r.GET("/c/param/:name/:num", func(c unified.Context) error {
+					i, ierr := c.ParamInt("num")
+					u, uerr := c.ParamUint("num")
+					return echo(c, map[string]interface{}{
+						"name":     c.Param("name"),
+						"missing":  c.Param("missing"),
+						"int":      i,
+						"intErr":   errString(ierr),
+						"uint":     u,
+						"uintErr":  errString(uerr),
+						"template": c.Path(),
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/param/alice/42", nil),
+				NewRequest(http.MethodGet, "/c/param/%E5%BC%A0%E4%B8%89/-1", nil),
+				NewRequest(http.MethodGet, "/c/param/bob/abc", nil),
+			},
+		},
+		{
+			Name: "Bind",
+			Mount: func(r unified.Router) {
+				handler := func(c unified.Context) error {
+					var p bindPayload
+					err := c.Bind(&p)
+					return echo(c, map[string]interface{}{"payload": p, "err": errString(err)})
+				}
+				r.GET("/c/bind", handler)
+				r.POST("/c/bind", handler)
+			},
+			Requests: []*Request{
+				JSONRequest(http.MethodPost, "/c/bind", map[string]interface{}{"name": "json", "age": 18, "tags": []string{"a", "b"}}),
+				NewRequest(http.MethodPost, "/c/bind", []byte(`<bindPayload><name>xml</name><age>20</age><tags>a</tags><tags>b</tags></bindPayload>`)).WithHeader("Content-Type", unified.MIMEXML),
+				FormRequest(http.MethodPost, "/c/bind", url.Values{"name": {"form"}, "age": {"21"}, "tags": {"a", "b"}}),
+				MultipartRequest(http.MethodPost, "/c/bind", url.Values{"name": {"multipart"}, "age": {"22"}, "tags": {"a", "b"}}),
+				NewRequest(http.MethodGet, "/c/bind?name=query&age=23&tags=a&tags=b", nil),
+				NewRequest(http.MethodPost, "/c/bind", []byte(`{"name":`)).WithHeader("Content-Type", unified.MIMEJSON),
+			},
+		},
+		{
+			Name: "BindJSON",
+			Mount: func(r unified.Router) {
+				r.POST("/c/bind/json", func(c unified.Context) error {
+					var p bindPayload
+					err := c.BindJSON(&p)
+					return echo(c, map[string]interface{}{"payload": p, "err": errString(err)})
+				})
+			},
+			Requests: []*Request{
+				JSONRequest(http.MethodPost, "/c/bind/json", map[string]interface{}{"name": "json", "age": 18, "tags": []string{"a"}}),
+				NewRequest(http.MethodPost, "/c/bind/json", []byte(`{"name":"charset","age":1}`)).WithHeader("Content-Type", "application/json; charset=utf-8"),
+				NewRequest(http.MethodPost, "/c/bind/json", []byte(`{"age":"x"}`)).WithHeader("Content-Type", unified.MIMEJSON),
+			},
+		},
+		{
+			Name: "BindQuery",
+			Mount: func(r unified.Router) {
+				r.GET("/c/bind/query", func(c unified.Context) error {
+					var p bindPayload
+					err := c.BindQuery(&p)
+					return echo(c, map[string]interface{}{"payload": p, "err": errString(err)})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/bind/query?name=q&age=5&tags=a&tags=b", nil),
+				NewRequest(http.MethodGet, "/c/bind/query?age=x", nil),
+				NewRequest(http.MethodGet, "/c/bind/query", nil),
+			},
+		},
+		{
+			Name: "BindForm",
+			Mount: func(r unified.Router) {
+				r.POST("/c/bind/form", func(c unified.Context) error {
+					var p bindPayload
+					err := c.BindForm(&p)
+					return echo(c, map[string]interface{}{"payload": p, "err": errString(err)})
+				})
+			},
+			Requests: []*Request{
+				FormRequest(http.MethodPost, "/c/bind/form", url.Values{"name": {"form"}, "age": {"7"}, "tags": {"a", "b"}}),
+				MultipartRequest(http.MethodPost, "/c/bind/form", url.Values{"name": {"multipart"}, "age": {"8"}}),
+				FormRequest(http.MethodPost, "/c/bind/form", url.Values{"age": {"x"}}),
+			},
+		},
+		{
+			Name: "FormFile/FormValue",
+			Mount: func(r unified.Router) {
+				r.POST("/c/form", func(c unified.Context) error {
+					result := map[string]interface{}{
+						"title":   c.FormValue("title"),
+						"missing": c.FormValue("missing"),
+					}
+					fh, err := c.FormFile("file")
+					result["fileErr"] = errString(err)
+					if err == nil {
+						f, err := fh.Open()
+						if err != nil {
+							return err
+						}
+						defer f.Close()
+						content, err := io.ReadAll(f)
+						if err != nil {
+							return err
+						}
+						result["filename"] = fh.Filename
+						result["size"] = fh.Size
+						result["content"] = string(content)
+					}
+					return echo(c, result)
+				})
+			},
+			Requests: []*Request{
+				MultipartRequest(http.MethodPost, "/c/form", url.Values{"title": {"标题"}}, FormFile{Field: "file", Filename: "a.txt", Content: []byte("hello")}),
+				MultipartRequest(http.MethodPost, "/c/form", url.Values{"title": {"no file"}}),
+				FormRequest(http.MethodPost, "/c/form", url.Values{"title": {"urlencoded"}}),
+			},
+		},
+		{
+			Name: "Status/JSON",
+			Mount: func(r unified.Router) {
+				r.GET("/c/json", func(c unified.Context) error {
+					return c.JSON(http.StatusCreated, map[string]interface{}{"html": "<b>&</b>", "n": 1.5, "nil": nil})
+				})
+				r.GET("/c/status", func(c unified.Context) error {
+					c.Status(http.StatusAccepted)
+					return nil
+				})
+				r.GET("/c/status/json", func(c unified.Context) error {
+					return c.Status(http.StatusAccepted).JSON(http.StatusCreated, []int{1})
+				})
+				r.GET("/c/status/nocontent", func(c unified.Context) error {
+					return c.JSON(http.StatusNoContent, map[string]string{"dropped": "body"})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/json", nil),
+				NewRequest(http.MethodGet, "/c/status", nil),
+				NewRequest(http.MethodGet, "/c/status/json", nil),
+				NewRequest(http.MethodGet, "/c/status/nocontent", nil),
+			},
+		},
+		{
+			Name: "String",
+			Mount: func(r unified.Router) {
+				r.GET("/c/string", func(c unified.Context) error {
+					return c.String(http.StatusOK, "%s=%d%%", "n", 100)
+				})
+				r.GET("/c/string/raw", func(c unified.Context) error {
+					return c.String(http.StatusOK, "100%")
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/string", nil),
+				NewRequest(http.MethodGet, "/c/string/raw", nil),
+			},
+		},
+		{
+			Name: "HTML/XML/Data",
+			Mount: func(r unified.Router) {
+				r.GET("/c/html", func(c unified.Context) error {
+					return c.HTML(http.StatusOK, "<h1>标题</h1>")
+				})
+				r.GET("/c/xml", func(c unified.Context) error {
+					return c.XML(http.StatusOK, bindPayload{Name: "xml", Age: 1, Tags: []string{"a"}})
+				})
+				r.GET("/c/data", func(c unified.Context) error {
+					return c.Data(http.StatusOK, "application/octet-stream", []byte{0, 1, 2})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/html", nil),
+				NewRequest(http.MethodGet, "/c/xml", nil),
+				NewRequest(http.MethodGet, "/c/data", nil),
+			},
+		},
+		{
+			Name: "Negotiate",
+			Mount: func(r unified.Router) {
+				r.GET("/c/negotiate", func(c unified.Context) error {
+					return c.Negotiate(http.StatusOK, bindPayload{Name: "n", Age: 2})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/negotiate", nil),
+				NewRequest(http.MethodGet, "/c/negotiate", nil).WithHeader("Accept", "application/xml"),
+				NewRequest(http.MethodGet, "/c/negotiate", nil).WithHeader("Accept", "application/msgpack"),
+				NewRequest(http.MethodGet, "/c/negotiate", nil).WithHeader("Accept", "text/html,application/xhtml+xml,*/*;q=0.8"),
+			},
+		},
+		{
+			Name: "Redirect",
+			Mount: func(r unified.Router) {
+				r.GET("/c/redirect", func(c unified.Context) error {
+					return c.Redirect(http.StatusFound, "/c/json?from=redirect")
+				})
+				r.POST("/c/redirect", func(c unified.Context) error {
+					return c.Redirect(http.StatusSeeOther, "https://example.com/next")
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/redirect", nil),
+				NewRequest(http.MethodPost, "/c/redirect", nil),
+			},
+		},
+		{
+			Name: "File",
+			Mount: func(r unified.Router) {
+				r.GET("/c/file", func(c unified.Context) error {
+					return c.File(fixture)
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/file", nil),
+			},
+			// 文件的修改时间由测试目录决定，各引擎相同，但格式化和缓存协商由各引擎的文件服务实现，只比较内容
+			Options: []Option{IgnoreHeaders("Last-Modified", "Accept-Ranges")},
+		},
+		{
+			Name: "Stream",
+			Mount: func(r unified.Router) {
+				r.GET("/c/stream", func(c unified.Context) error {
+					return c.Stream("text/plain; charset=utf-8", strings.NewReader(strings.Repeat("流式数据\n", 1000)))
+				})
+				r.GET("/c/stream/status", func(c unified.Context) error {
+					c.Status(http.StatusPartialContent)
+					return c.Stream("application/octet-stream", strings.NewReader("partial"))
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/stream", nil),
+				NewRequest(http.MethodGet, "/c/stream/status", nil),
+			},
+		},
+		{
+			Name: "SSE",
+			Mount: func(r unified.Router) {
+				r.GET("/c/sse", func(c unified.Context) error {
+					return c.SSE(func(w unified.EventWriter) error {
+						if err := w.Send(unified.Event{ID: "1", Event: "greeting", Data: "你好\n世界"}); err != nil {
+							return err
+						}
+						if err := w.Data(map[string]string{"last": w.LastEventID()}); err != nil {
+							return err
+						}
+						return w.Comment("done")
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/sse", nil).WithHeader("Last-Event-ID", "41"),
+			},
+		},
+		{
+			Name: "Cookie/SetCookie/ClearCookie",
+			Mount: func(r unified.Router) {
+				r.GET("/c/cookie", func(c unified.Context) error {
+					value, err := c.Cookie("session")
+					c.SetCookie(&http.Cookie{Name: "lang", Value: "zh-CN", MaxAge: 3600, HttpOnly: true})
+					c.ClearCookie("old")
+					return echo(c, map[string]interface{}{"session": value, "err": errString(err)})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/cookie", nil).WithHeader("Cookie", "session=abc; other=1"),
+				NewRequest(http.MethodGet, "/c/cookie", nil).WithHeader("Cookie", `session="quoted"`),
+				NewRequest(http.MethodGet, "/c/cookie", nil),
+			},
+		},
+		{
+			Name: "SignedCookie/SetSignedCookie",
+			Mount: func(r unified.Router) {
+				r.GET("/c/cookie/signed", func(c unified.Context) error {
+					value, err := c.SignedCookie("uid")
+					setErr := c.SetSignedCookie(&http.Cookie{Name: "uid", Value: "42"})
+					return echo(c, map[string]interface{}{"uid": value, "err": errString(err), "setErr": errString(setErr)})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/cookie/signed", nil),
+				NewRequest(http.MethodGet, "/c/cookie/signed", nil).WithHeader("Cookie", "uid=NDI.tampered"),
+			},
+			Run: func(t TB, h *Harness) {
+				roundTrip(t, h, "/c/cookie/signed", "uid")
+			},
+		},
+		{
+			Name: "EncryptedCookie/SetEncryptedCookie",
+			Mount: func(r unified.Router) {
+				r.GET("/c/cookie/encrypted", func(c unified.Context) error {
+					value, err := c.EncryptedCookie("profile")
+					setErr := c.SetEncryptedCookie(&http.Cookie{Name: "profile", Value: "机密"})
+					return echo(c, map[string]interface{}{"profile": value, "err": errString(err), "setErr": errString(setErr)})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/cookie/encrypted", nil).WithHeader("Cookie", "profile=invalid"),
+			},
+			// 加密使用随机数，每次生成的Cookie值不同
+			Options: []Option{IgnoreHeaders("Set-Cookie")},
+			Run: func(t TB, h *Harness) {
+				roundTrip(t, h, "/c/cookie/encrypted", "profile")
+			},
+		},
+		{
+			Name: "Set/Get/MustGet",
+			Mount: func(r unified.Router) {
+				setter := func(next unified.HandlerFunc) unified.HandlerFunc {
+					return func(c unified.Context) error {
+						c.Set("user", "alice")
+						c.Set("count", 3)
+						c.Set("nil", nil)
+						return next(c)
+					}
+				}
+				r.GET("/c/keys", func(c unified.Context) error {
+					user, userOK := c.Get("user")
+					_, nilOK := c.Get("nil")
+					_, missingOK := c.Get("missing")
+					panicked := func() (panicked bool) {
+						defer func() {
+							panicked = recover() != nil
+						}()
+						c.MustGet("missing")
+						return false
+					}()
+					return echo(c, map[string]interface{}{
+						"user":      user,
+						"userOK":    userOK,
+						"count":     c.MustGet("count"),
+						"nilOK":     nilOK,
+						"missingOK": missingOK,
+						"panicked":  panicked,
+					})
+				}, setter)
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/keys", nil),
+			},
+		},
+		{
+			Name: "Context/SetContext/RequestID",
+			Mount: func(r unified.Router) {
+				r.GET("/c/context", func(c unified.Context) error {
+					c.SetContext(context.WithValue(c.Context(), contextKey{}, "value"))
+					return echo(c, map[string]interface{}{
+						"value":     c.Context().Value(contextKey{}),
+						"request":   c.GetRequest().Context().Value(contextKey{}),
+						"requestID": unified.GetRequestID(c),
+					})
+				}, unified.RequestID(unified.RequestIDConfig{Generator: func() string { return "generated" }}))
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/context", nil),
+				NewRequest(http.MethodGet, "/c/context", nil).WithHeader(unified.RequestIDHeader, "client-id"),
+				NewRequest(http.MethodGet, "/c/context", nil).WithHeader(unified.RequestIDHeader, "bad id"),
+			},
+		},
+		{
+			Name: "GinContext/FiberContext/GetRequest/GetResponse",
+			Mount: func(r unified.Router) {
+				r.POST("/c/native", func(c unified.Context) error {
+					_, isGin := c.(*unified.GinContext)
+					_, isFiber := c.(*unified.FiberContext)
+					result := map[string]interface{}{
+						"gin":   (c.GinContext() != nil) == isGin,
+						"fiber": (c.FiberContext() != nil) == isFiber,
+					}
+					if req := c.GetRequest(); req != nil {
+						body, _ := io.ReadAll(req.Body)
+						result["request"] = map[string]interface{}{
+							"method": req.Method,
+							"path":   req.URL.Path,
+							"query":  req.URL.RawQuery,
+							"host":   req.Host,
+							"header": req.Header.Get("X-Test"),
+							"body":   string(body),
+						}
+					}
+					if w := c.GetResponse(); w != nil {
+						w.Header().Set("Content-Type", "application/json")
+						w.Header().Set("X-Native", "1")
+						w.WriteHeader(http.StatusAccepted)
+						w.Write([]byte(`{"native":true}`))
+						return nil
+					}
+					return echo(c, result)
+				})
+				r.GET("/c/native/request", func(c unified.Context) error {
+					req := c.GetRequest()
+					if req == nil {
+						return echo(c, map[string]interface{}{"request": nil})
+					}
+					return echo(c, map[string]interface{}{
+						"method": req.Method,
+						"path":   req.URL.Path,
+						"query":  req.URL.RawQuery,
+						"host":   req.Host,
+						"header": req.Header.Get("X-Test"),
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodPost, "/c/native?x=1", []byte("raw body")).WithHeader("X-Test", "native"),
+				NewRequest(http.MethodGet, "/c/native/request?x=1", nil).WithHeader("X-Test", "native"),
+			},
+		},
+		{
+			Name: "Error/HasErrors/Errors",
+			Mount: func(r unified.Router) {
+				r.GET("/c/errors", func(c unified.Context) error {
+					before := c.HasErrors()
+					first := errors.New("first")
+					returned := c.Error(first)
+					c.Error(nil)
+					c.Error(errors.New("second"))
+					errs := c.Errors()
+					messages := make([]string, 0, len(errs))
+					for _, err := range errs {
+						messages = append(messages, err.Error())
+					}
+					return echo(c, map[string]interface{}{
+						"before":   before,
+						"after":    c.HasErrors(),
+						"returned": errors.Is(returned, first),
+						"errors":   messages,
+					})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/errors", nil),
+			},
+		},
+		{
+			Name: "Next/IsAborted/Abort",
+			Mount: func(r unified.Router) {
+				guard := func(next unified.HandlerFunc) unified.HandlerFunc {
+					return func(c unified.Context) error {
+						if c.Query("deny") != "" {
+							c.Abort()
+							return echo(c, map[string]interface{}{"aborted": c.IsAborted(), "handler": false})
+						}
+						return next(c)
+					}
+				}
+				r.GET("/c/abort", func(c unified.Context) error {
+					before := c.IsAborted()
+					c.Next()
+					return echo(c, map[string]interface{}{"aborted": before, "handler": true, "errors": len(c.Errors())})
+				}, guard)
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/abort", nil),
+				NewRequest(http.MethodGet, "/c/abort?deny=1", nil),
+			},
+		},
+		{
+			Name: "AbortWithStatus/AbortWithJSON",
+			Mount: func(r unified.Router) {
+				r.GET("/c/abort/status", func(c unified.Context) error {
+					c.AbortWithStatus(http.StatusForbidden)
+					return nil
+				})
+				r.GET("/c/abort/json", func(c unified.Context) error {
+					return c.AbortWithJSON(http.StatusUnauthorized, map[string]interface{}{"aborted": c.IsAborted()})
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/abort/status", nil),
+				NewRequest(http.MethodGet, "/c/abort/json", nil),
+			},
+		},
+		{
+			Name: "HandlerError",
+			Mount: func(r unified.Router) {
+				r.GET("/c/handler/error", func(c unified.Context) error {
+					return errors.New("handler failed")
+				})
+				r.GET("/c/handler/written", func(c unified.Context) error {
+					c.String(http.StatusCreated, "written")
+					return errors.New("after write")
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/handler/error", nil),
+				NewRequest(http.MethodGet, "/c/handler/written", nil),
+			},
+		},
+		{
+			Name: "Timeout",
+			Mount: func(r unified.Router) {
+				r.GET("/c/timeout/slow", func(c unified.Context) error {
+					<-c.Context().Done()
+					return c.Context().Err()
+				}, unified.Timeout(20*time.Millisecond))
+				r.GET("/c/timeout/fast", func(c unified.Context) error {
+					_, ok := c.Context().Deadline()
+					return echo(c, map[string]interface{}{
+						"deadline": ok,
+						"err":      errString(c.Context().Err()),
+					})
+				}, unified.Timeout(time.Minute))
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/timeout/slow", nil),
+				NewRequest(http.MethodGet, "/c/timeout/fast", nil),
+			},
+		},
+		{
+			Name: "CORS",
+			Mount: func(r unified.Router) {
+				cors := r.Group("/c/cors", unified.CORS(unified.CORSConfig{
+					AllowOrigins:  []string{"https://app.example.com", "https://*.example.org"},
+					AllowHeaders:  []string{"Content-Type", "Authorization"},
+					ExposeHeaders: []string{"X-Total"},
+					MaxAge:        time.Hour,
+				}))
+				cors.GET("/items", func(c unified.Context) error {
+					c.SetHeader("X-Total", "1")
+					return c.String(http.StatusOK, "items")
+				})
+				// 路由组中覆盖跨域配置
+				open := cors.Group("/open", unified.CORS(unified.CORSConfig{AllowOrigins: []string{"*"}}))
+				open.POST("/items", func(c unified.Context) error {
+					return c.String(http.StatusCreated, "created")
+				})
+				// 自动注册OPTIONS路由后再显式注册
+				cors.OPTIONS("/items", func(c unified.Context) error {
+					return c.String(http.StatusOK, "options")
+				})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/cors/items", nil),
+				NewRequest(http.MethodGet, "/c/cors/items", nil).WithHeader("Origin", "https://app.example.com"),
+				NewRequest(http.MethodGet, "/c/cors/items", nil).WithHeader("Origin", "https://api.example.org"),
+				NewRequest(http.MethodGet, "/c/cors/items", nil).WithHeader("Origin", "https://evil.com"),
+				NewRequest(http.MethodOptions, "/c/cors/items", nil).
+					WithHeader("Origin", "https://app.example.com").
+					WithHeader("Access-Control-Request-Method", "GET").
+					WithHeader("Access-Control-Request-Headers", "content-type, authorization"),
+				NewRequest(http.MethodOptions, "/c/cors/items", nil).
+					WithHeader("Origin", "https://app.example.com").
+					WithHeader("Access-Control-Request-Method", "GET").
+					WithHeader("Access-Control-Request-Headers", "X-Custom"),
+				NewRequest(http.MethodOptions, "/c/cors/items", nil),
+				NewRequest(http.MethodOptions, "/c/cors/open/items", nil).
+					WithHeader("Origin", "https://any.dev").
+					WithHeader("Access-Control-Request-Method", "POST").
+					WithHeader("Access-Control-Request-Headers", "X-Custom"),
+				NewRequest(http.MethodPost, "/c/cors/open/items", nil).WithHeader("Origin", "https://any.dev"),
+			},
+		},
+		{
+			Name: "Compress",
+			Mount: func(r unified.Router) {
+				text := strings.Repeat("compress ", 32)
+				compress := r.Group("/c/compress", unified.Compress(unified.CompressConfig{MinSize: 64}))
+				compress.GET("/json", func(c unified.Context) error {
+					return c.JSON(http.StatusOK, map[string]string{"text": text})
+				})
+				compress.GET("/string", func(c unified.Context) error {
+					return c.String(http.StatusOK, text)
+				})
+				compress.GET("/small", func(c unified.Context) error {
+					return c.String(http.StatusOK, "small")
+				})
+				compress.GET("/image", func(c unified.Context) error {
+					return c.Data(http.StatusOK, "image/png", []byte(text))
+				})
+				compress.GET("/encoded", func(c unified.Context) error {
+					c.SetHeader("Content-Encoding", "identity")
+					return c.Data(http.StatusOK, "text/plain", []byte(text))
+				})
+				compress.GET("/stream", func(c unified.Context) error {
+					return c.Stream("text/plain", strings.NewReader(text))
+				})
+				// 路由上覆盖压缩配置，并通过BufferResponse修改响应
+				compress.GET("/override", func(c unified.Context) error {
+					c.SetHeader("ETag", `"v1"`)
+					return c.String(http.StatusOK, "override")
+				}, unified.Compress(unified.CompressConfig{MinSize: 1, Encodings: []string{unified.EncodingGzip}}),
+					func(next unified.HandlerFunc) unified.HandlerFunc {
+						return func(c unified.Context) error {
+							resp, err := unified.BufferResponse(c, next)
+							if err != nil || resp == nil {
+								return err
+							}
+							resp.Body = append(resp.Body, "!"...)
+							resp.Header.Set("X-Buffered", "1")
+							return unified.WriteResponse(c, resp)
+						}
+					})
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/compress/json", nil),
+				NewRequest(http.MethodGet, "/c/compress/json", nil).WithHeader("Accept-Encoding", "gzip, deflate, br"),
+				NewRequest(http.MethodGet, "/c/compress/json", nil).WithHeader("Accept-Encoding", "gzip;q=0.5, deflate"),
+				NewRequest(http.MethodGet, "/c/compress/json", nil).WithHeader("Accept-Encoding", "br;q=0, *"),
+				NewRequest(http.MethodGet, "/c/compress/string", nil).WithHeader("Accept-Encoding", "gzip"),
+				NewRequest(http.MethodGet, "/c/compress/small", nil).WithHeader("Accept-Encoding", "gzip"),
+				NewRequest(http.MethodGet, "/c/compress/image", nil).WithHeader("Accept-Encoding", "gzip"),
+				NewRequest(http.MethodGet, "/c/compress/encoded", nil).WithHeader("Accept-Encoding", "gzip"),
+				NewRequest(http.MethodGet, "/c/compress/stream", nil).WithHeader("Accept-Encoding", "gzip"),
+				NewRequest(http.MethodGet, "/c/compress/override", nil).WithHeader("Accept-Encoding", "br"),
+				NewRequest(http.MethodGet, "/c/compress/override", nil).WithHeader("Accept-Encoding", "gzip"),
+			},
+		},
+		{
+			Name: "Static",
+			Mount: func(r unified.Router) {
+				r.Static("/c/static", static)
+			},
+			Requests: []*Request{
+				NewRequest(http.MethodGet, "/c/static/app.js", nil),
+			},
+			Options: []Option{IgnoreHeaders("Last-Modified", "Accept-Ranges")},
+		},
+	}, nil
+}
+
+// roundTrip 从第一个引擎获取Set-Cookie，将Cookie带回各引擎并比较读取结果，验证各引擎之间生成的Cookie可以互相识别
+func roundTrip(t TB, h *Harness, target, name string) {
+	t.Helper()
+	responses, err := h.Do(NewRequest(http.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+		return
+	}
+	for _, resp := range responses {
+		var value string
+		for _, ck := range (&http.Response{Header: resp.Header}).Cookies() {
+			if ck.Name == name {
+				value = ck.Value
+			}
+		}
+		if value == "" {
+			t.Errorf("%s没有设置Cookie %s", resp.Engine, name)
+			continue
+		}
+		req := NewRequest(http.MethodGet, target, nil).WithHeader("Cookie", name+"="+value)
+		_, diffs, err := h.Compare(req, IgnoreHeaders("Set-Cookie"))
+		if err != nil {
+			t.Fatalf("%v", err)
+			return
+		}
+		for _, d := range diffs {
+			t.Errorf("%s生成的Cookie: %s", resp.Engine, d)
+		}
+	}
+}
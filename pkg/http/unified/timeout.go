@@ -0,0 +1,44 @@
+package unified
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrRequestTimeout 请求处理超时，统一错误处理流程将其映射为504响应
+var ErrRequestTimeout = fmt.Errorf("请求处理超时: %w", context.DeadlineExceeded)
+
+// Timeout 路由超时中间件，为c.Context()设置截止时间，例如:
+//
+//	router.GET("/report", handler, unified.Timeout(5*time.Second))
+//
+// 超时通过上下文通知处理函数，处理函数需要将c.Context()传给数据库和缓存等调用才能及时返回；
+// 超时后尚未输出响应时返回ErrRequestTimeout，由统一错误处理流程输出504响应
+func Timeout(timeout time.Duration) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			parent := c.Context()
+			ctx, cancel := context.WithTimeout(parent, timeout)
+			defer cancel()
+			c.SetContext(ctx)
+			err := next(c)
+			// 外层中间件继续使用原来的上下文
+			c.SetContext(parent)
+
+			// 只处理本中间件设置的截止时间，客户端断开等外层取消原样返回
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) || parent.Err() != nil || ResponseWritten(c) {
+				return err
+			}
+			if err == nil || errors.Is(err, context.DeadlineExceeded) {
+				return ErrRequestTimeout
+			}
+			return fmt.Errorf("%w: %v", ErrRequestTimeout, err)
+		}
+	}
+}
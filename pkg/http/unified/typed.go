@@ -0,0 +1,239 @@
+package unified
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// TypedHandlerFunc 类型化处理函数，req为已绑定并校验的请求参数
+type TypedHandlerFunc[Req any, Resp any] func(c Context, req *Req) (Resp, error)
+
+// Empty 空结构体，用作无请求参数或无响应数据的类型化处理函数的类型参数
+type Empty struct{}
+
+// BindError 请求参数绑定失败的错误
+type BindError struct {
+	Err error
+}
+
+// Error 实现error接口
+func (e *BindError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 获取原始错误
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Responder 类型化处理函数的响应方式
+type Responder interface {
+	// Success 输出处理结果
+	Success(c Context, data interface{}) error
+	// Fail 输出错误，包括参数绑定、校验错误以及处理函数返回的错误
+	Fail(c Context, err error) error
+}
+
+// defaultResponder 默认响应方式，直接输出JSON
+type defaultResponder struct{}
+
+// Success 实现Responder接口
+func (defaultResponder) Success(c Context, data interface{}) error {
+	return c.JSON(http.StatusOK, data)
+}
+
+// Fail 实现Responder接口
+func (defaultResponder) Fail(c Context, err error) error {
+	code := http.StatusInternalServerError
+	if _, ok := err.(*BindError); ok {
+		code = http.StatusBadRequest
+	}
+	c.Error(err)
+	return c.JSON(code, map[string]string{"error": err.Error()})
+}
+
+var (
+	typedMu        sync.RWMutex
+	typedValidator           = binding.Validator.ValidateStruct
+	typedResponder Responder = defaultResponder{}
+)
+
+// SetValidator 设置类型化处理函数的参数校验函数，传入nil则不校验
+func SetValidator(validate func(obj interface{}) error) {
+	typedMu.Lock()
+	defer typedMu.Unlock()
+	typedValidator = validate
+}
+
+// SetResponder 设置类型化处理函数的响应方式
+func SetResponder(responder Responder) {
+	typedMu.Lock()
+	defer typedMu.Unlock()
+	if responder == nil {
+		responder = defaultResponder{}
+	}
+	typedResponder = responder
+}
+
+// Handle 将类型化处理函数转换为HandlerFunc
+// 请求参数依次从请求体、查询参数、请求头和路径参数绑定到Req，校验通过后调用处理函数，
+// 返回值和错误通过Responder输出，例如:
+//
+//	router.POST("", unified.Handle(func(c unified.Context, req *req.DemoCreateReq) (*model.Demo, error) {
+//		return service.Create(req)
+//	}))
+func Handle[Req any, Resp any](fn TypedHandlerFunc[Req, Resp]) HandlerFunc {
+	serve := func(c Context) error {
+		typedMu.RLock()
+		validate, responder := typedValidator, typedResponder
+		typedMu.RUnlock()
+
+		req := new(Req)
+		if err := BindRequest(c, req); err != nil {
+			return responder.Fail(c, &BindError{Err: err})
+		}
+		if validate != nil {
+			if err := validate(req); err != nil {
+				return responder.Fail(c, err)
+			}
+		}
+
+		resp, err := fn(c, req)
+		if err != nil {
+			return responder.Fail(c, err)
+		}
+		return responder.Success(c, resp)
+	}
+
+	// 路由列表中显示原始函数名，而不是Handle内部的闭包名
+	return (&typedHandler{name: FuncName(fn), serve: serve}).handle
+}
+
+// typedHandler 类型化处理函数转换后的处理函数，记录原始函数名
+type typedHandler struct {
+	name  string
+	serve HandlerFunc
+}
+
+// handle 处理请求，传入handlerNameProbe时只返回原始函数名
+func (h *typedHandler) handle(c Context) error {
+	if probe, ok := c.(*handlerNameProbe); ok {
+		probe.name = h.name
+		return nil
+	}
+	return h.serve(c)
+}
+
+// handlerNameProbe 路由器获取类型化处理函数原始函数名时传入的上下文，只会传给typedHandler.handle
+type handlerNameProbe struct {
+	probeContext
+	name string
+}
+
+// probeContext Context的别名，Context接口自身有Context方法，嵌入别名避免字段名与方法名冲突
+type probeContext = Context
+
+// typedHandlerPC typedHandler.handle方法值的函数地址，同一方法的所有方法值相同，与接收者无关
+var typedHandlerPC = funcPC((*typedHandler)(nil).handle)
+
+// handlerName 获取处理函数名称，类型化处理函数返回原始函数名
+func handlerName(handler HandlerFunc) string {
+	if handler == nil {
+		return ""
+	}
+	if funcPC(handler) == typedHandlerPC {
+		probe := &handlerNameProbe{}
+		handler(probe)
+		return probe.name
+	}
+	return FuncName(handler)
+}
+
+// BindRequest 将请求参数绑定到结构体
+// 请求体按Content-Type选择解析方式，查询参数使用form标签，请求头使用header标签，路径参数使用uri标签
+func BindRequest(c Context, obj interface{}) error {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return nil
+	}
+
+	if hasRequestBody(c) {
+		if err := c.Bind(obj); err != nil {
+			return err
+		}
+	}
+
+	if u := c.URL(); u != nil && u.RawQuery != "" {
+		if err := bindValues(obj, "form", u.Query(), nil); err != nil {
+			return err
+		}
+	}
+
+	if names := tagNames(t, "header"); len(names) > 0 {
+		headers := make(map[string][]string, len(names))
+		for _, name := range names {
+			if v := c.GetHeader(name); v != "" {
+				headers[name] = []string{v}
+			}
+		}
+		if err := bindValues(obj, "header", headers, nil); err != nil {
+			return err
+		}
+	}
+
+	if names := tagNames(t, "uri"); len(names) > 0 {
+		params := make(map[string][]string, len(names))
+		for _, name := range names {
+			if v := c.Param(name); v != "" {
+				params[name] = []string{v}
+			}
+		}
+		if err := bindValues(obj, "uri", params, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRequestBody 判断请求是否携带请求体
+func hasRequestBody(c Context) bool {
+	switch c.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	if c.GetHeader("Content-Type") == "" {
+		return false
+	}
+	return c.GetHeader("Content-Length") != "0"
+}
+
+// tagNames 获取结构体中声明了指定标签的参数名，包括匿名嵌入结构体中的字段
+func tagNames(t reflect.Type, tag string) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get(tag), ",")[0]
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if name == "" {
+			if f.Anonymous && ft.Kind() == reflect.Struct {
+				names = append(names, tagNames(ft, tag)...)
+			}
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
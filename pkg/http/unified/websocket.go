@@ -0,0 +1,488 @@
+package unified
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/valyala/fasthttp"
+)
+
+// WebSocket消息类型，与RFC 6455定义一致
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// WebSocket关闭状态码
+const (
+	CloseNormalClosure     = 1000
+	CloseGoingAway         = 1001
+	CloseInternalServerErr = 1011
+)
+
+// WSHandler WebSocket处理函数，返回后连接会被关闭
+type WSHandler func(conn WSConn) error
+
+// WSConn 统一的WebSocket连接接口
+// 写方法可以被多个goroutine并发调用，读方法只能在一个goroutine中调用
+type WSConn interface {
+	// ID 连接唯一标识
+	ID() string
+
+	// 读写消息
+	ReadMessage() (messageType int, data []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	WriteText(text string) error
+
+	// 心跳与关闭
+	Ping(data []byte) error
+	SetPongHandler(handler func(data string) error)
+	SetReadDeadline(t time.Time) error
+	Close() error
+	CloseWithCode(code int, reason string) error
+
+	// 升级请求信息，Path返回路由路径
+	Path() string
+	Param(key string) string
+	Query(key string) string
+	Header(key string) string
+	ClientIP() string
+
+	// 连接数据，升级前中间件通过Context.Set设置的数据会复制到连接中
+	Set(key string, value interface{})
+	Get(key string) (interface{}, bool)
+}
+
+// WebSocketConfig WebSocket配置
+type WebSocketConfig struct {
+	// ReadBufferSize 读缓冲区大小
+	ReadBufferSize int
+	// WriteBufferSize 写缓冲区大小
+	WriteBufferSize int
+	// ReadLimit 单条消息的最大字节数，0表示不限制
+	ReadLimit int64
+	// WriteTimeout 写消息超时时间
+	WriteTimeout time.Duration
+	// PingInterval 自动发送Ping的间隔，0表示不自动发送
+	// 开启后读超时为两倍间隔，收到Pong时自动延长，处理函数不应再调用SetPongHandler
+	PingInterval time.Duration
+	// EnableCompression 是否启用消息压缩
+	EnableCompression bool
+	// CheckOrigin 校验升级请求的Origin，为nil时只允许同源请求
+	CheckOrigin func(c Context) bool
+}
+
+var (
+	wsMu     sync.RWMutex
+	wsConfig = WebSocketConfig{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		WriteTimeout:    10 * time.Second,
+	}
+)
+
+// SetWebSocketConfig 设置WebSocket配置
+func SetWebSocketConfig(config WebSocketConfig) {
+	wsMu.Lock()
+	defer wsMu.Unlock()
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	wsConfig = config
+}
+
+// getWebSocketConfig 获取WebSocket配置
+func getWebSocketConfig() WebSocketConfig {
+	wsMu.RLock()
+	defer wsMu.RUnlock()
+	return wsConfig
+}
+
+// IsWSCloseError 判断错误是否为连接关闭导致，读消息返回此类错误时处理函数应当直接返回
+func IsWSCloseError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var gorillaErr *websocket.CloseError
+	var fastErr *fastws.CloseError
+	if errors.As(err, &gorillaErr) || errors.As(err, &fastErr) {
+		return true
+	}
+	return errors.Is(err, websocket.ErrCloseSent) || errors.Is(err, fastws.ErrCloseSent) ||
+		strings.Contains(err.Error(), "use of closed network connection")
+}
+
+// rawWSConn gorilla和fasthttp的WebSocket连接的公共方法
+type rawWSConn interface {
+	ReadMessage() (int, []byte, error)
+	WriteMessage(messageType int, data []byte) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+	SetReadLimit(limit int64)
+	SetPongHandler(h func(appData string) error)
+	Close() error
+}
+
+// wsRequest 升级请求信息的快照
+type wsRequest struct {
+	path     string
+	clientIP string
+	params   map[string]string
+	query    url.Values
+	header   http.Header
+}
+
+// wsConn WSConn的实现
+type wsConn struct {
+	id      string
+	raw     rawWSConn
+	request wsRequest
+	config  WebSocketConfig
+
+	writeMu   sync.Mutex
+	keysMu    sync.RWMutex
+	keys      map[string]interface{}
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// newWSConn 创建WebSocket连接
+func newWSConn(raw rawWSConn, request wsRequest, keys map[string]interface{}, config WebSocketConfig) *wsConn {
+	if keys == nil {
+		keys = make(map[string]interface{})
+	}
+	if config.ReadLimit > 0 {
+		raw.SetReadLimit(config.ReadLimit)
+	}
+	return &wsConn{
+		id:      newWSConnID(),
+		raw:     raw,
+		request: request,
+		config:  config,
+		keys:    keys,
+		closed:  make(chan struct{}),
+	}
+}
+
+// newWSConnID 生成连接ID
+func newWSConnID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(time.Now().Format("20060102150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(b)
+}
+
+// ID 实现WSConn接口
+func (c *wsConn) ID() string {
+	return c.id
+}
+
+// ReadMessage 实现WSConn接口
+func (c *wsConn) ReadMessage() (int, []byte, error) {
+	return c.raw.ReadMessage()
+}
+
+// WriteMessage 实现WSConn接口
+func (c *wsConn) WriteMessage(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.raw.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+	return c.raw.WriteMessage(messageType, data)
+}
+
+// ReadJSON 实现WSConn接口
+func (c *wsConn) ReadJSON(v interface{}) error {
+	_, data, err := c.raw.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON 实现WSConn接口
+func (c *wsConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, data)
+}
+
+// WriteText 实现WSConn接口
+func (c *wsConn) WriteText(text string) error {
+	return c.WriteMessage(TextMessage, []byte(text))
+}
+
+// Ping 实现WSConn接口
+func (c *wsConn) Ping(data []byte) error {
+	return c.raw.WriteControl(PingMessage, data, time.Now().Add(c.config.WriteTimeout))
+}
+
+// SetPongHandler 实现WSConn接口
+func (c *wsConn) SetPongHandler(handler func(data string) error) {
+	c.raw.SetPongHandler(handler)
+}
+
+// SetReadDeadline 实现WSConn接口
+func (c *wsConn) SetReadDeadline(t time.Time) error {
+	return c.raw.SetReadDeadline(t)
+}
+
+// Close 实现WSConn接口
+func (c *wsConn) Close() error {
+	return c.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode 实现WSConn接口，发送关闭帧后关闭底层连接
+func (c *wsConn) CloseWithCode(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		msg := websocket.FormatCloseMessage(code, truncateCloseReason(reason))
+		c.raw.WriteControl(CloseMessage, msg, time.Now().Add(time.Second))
+		err = c.raw.Close()
+	})
+	return err
+}
+
+// truncateCloseReason 关闭原因最长123字节，超出时在字符边界截断，截断半个UTF-8字符的关闭帧会被对端拒绝
+func truncateCloseReason(reason string) string {
+	const maxLen = 123
+	if len(reason) <= maxLen {
+		return reason
+	}
+	i := maxLen
+	for i > 0 && !utf8.RuneStart(reason[i]) {
+		i--
+	}
+	return reason[:i]
+}
+
+// Path 实现WSConn接口
+func (c *wsConn) Path() string {
+	return c.request.path
+}
+
+// Param 实现WSConn接口
+func (c *wsConn) Param(key string) string {
+	return c.request.params[key]
+}
+
+// Query 实现WSConn接口
+func (c *wsConn) Query(key string) string {
+	return c.request.query.Get(key)
+}
+
+// Header 实现WSConn接口
+func (c *wsConn) Header(key string) string {
+	return c.request.header.Get(key)
+}
+
+// ClientIP 实现WSConn接口
+func (c *wsConn) ClientIP() string {
+	return c.request.clientIP
+}
+
+// Set 实现WSConn接口
+func (c *wsConn) Set(key string, value interface{}) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	c.keys[key] = value
+}
+
+// Get 实现WSConn接口
+func (c *wsConn) Get(key string) (interface{}, bool) {
+	c.keysMu.RLock()
+	defer c.keysMu.RUnlock()
+	value, ok := c.keys[key]
+	return value, ok
+}
+
+// serve 运行处理函数，返回后关闭连接
+func (c *wsConn) serve(handler WSHandler) {
+	if c.config.PingInterval > 0 {
+		// 读超时为两倍Ping间隔，收到Pong时延长
+		wait := 2 * c.config.PingInterval
+		c.raw.SetReadDeadline(time.Now().Add(wait))
+		c.raw.SetPongHandler(func(string) error {
+			return c.raw.SetReadDeadline(time.Now().Add(wait))
+		})
+		go c.keepAlive()
+	}
+
+	err := handler(c)
+	if err != nil && !IsWSCloseError(err) {
+		c.CloseWithCode(CloseInternalServerErr, err.Error())
+		return
+	}
+	c.Close()
+}
+
+// keepAlive 定时发送Ping，直到连接关闭
+func (c *wsConn) keepAlive() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.Ping(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// webSocketHandler 将WebSocket处理函数转换为普通处理函数，在路由中间件执行完成后升级连接
+func webSocketHandler(handler WSHandler) HandlerFunc {
+	return func(c Context) error {
+		config := getWebSocketConfig()
+		if !websocketOriginAllowed(c, config) {
+			return c.String(http.StatusForbidden, "origin not allowed")
+		}
+
+		switch ctx := c.(type) {
+		case *GinContext:
+			return upgradeHTTP(ctx.ctx.Writer, ctx.ctx.Request, ginRequest(ctx.ctx), ctx.ctx.Keys, config, handler)
+		case *StdContext:
+			return upgradeHTTP(ctx.writer, ctx.request, stdRequest(ctx), ctx.keys, config, handler)
+		case *FiberContext:
+			return upgradeFiber(ctx, config, handler)
+		}
+		return errors.New("当前上下文不支持WebSocket")
+	}
+}
+
+// websocketOriginAllowed 校验Origin
+func websocketOriginAllowed(c Context, config WebSocketConfig) bool {
+	if config.CheckOrigin != nil {
+		return config.CheckOrigin(c)
+	}
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, c.Host())
+}
+
+// upgradeHTTP 基于net/http升级连接（gin和标准库引擎），处理函数在当前goroutine中运行
+func upgradeHTTP(w http.ResponseWriter, r *http.Request, request wsRequest, keys map[string]interface{}, config WebSocketConfig, handler WSHandler) error {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:    config.ReadBufferSize,
+		WriteBufferSize:   config.WriteBufferSize,
+		EnableCompression: config.EnableCompression,
+		CheckOrigin:       func(*http.Request) bool { return true },
+	}
+	raw, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade失败时已经写入了错误响应
+		return err
+	}
+	newWSConn(raw, request, copyKeys(keys), config).serve(handler)
+	return nil
+}
+
+// upgradeFiber 基于fasthttp升级连接
+// 处理函数在连接被接管后的独立goroutine中运行，此时fiber上下文已被回收，因此需要预先复制请求信息
+func upgradeFiber(c *FiberContext, config WebSocketConfig, handler WSHandler) error {
+	request := fiberRequest(c)
+	keys := make(map[string]interface{})
+	c.ctx.Context().VisitUserValues(func(key []byte, value interface{}) {
+		keys[string(key)] = value
+	})
+
+	upgrader := fastws.FastHTTPUpgrader{
+		ReadBufferSize:    config.ReadBufferSize,
+		WriteBufferSize:   config.WriteBufferSize,
+		EnableCompression: config.EnableCompression,
+		CheckOrigin:       func(*fasthttp.RequestCtx) bool { return true },
+	}
+	return upgrader.Upgrade(c.ctx.Context(), func(raw *fastws.Conn) {
+		newWSConn(raw, request, keys, config).serve(handler)
+	})
+}
+
+// ginRequest 获取gin请求信息
+func ginRequest(c *gin.Context) wsRequest {
+	params := make(map[string]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = p.Value
+	}
+	return wsRequest{
+		path:     c.FullPath(),
+		clientIP: c.ClientIP(),
+		params:   params,
+		query:    c.Request.URL.Query(),
+		header:   c.Request.Header,
+	}
+}
+
+// stdRequest 获取标准库请求信息
+func stdRequest(c *StdContext) wsRequest {
+	params := make(map[string]string)
+	for _, match := range strings.Split(c.fullPath, "/") {
+		if strings.HasPrefix(match, ":") || strings.HasPrefix(match, "*") {
+			name := match[1:]
+			params[name] = c.request.PathValue(name)
+		}
+	}
+	return wsRequest{
+		path:     c.Path(),
+		clientIP: c.ClientIP(),
+		params:   params,
+		query:    c.request.URL.Query(),
+		header:   c.request.Header,
+	}
+}
+
+// fiberRequest 复制fiber请求信息，fiber返回的字符串引用了可复用的缓冲区，需要拷贝
+func fiberRequest(c *FiberContext) wsRequest {
+	header := make(http.Header)
+	c.ctx.Request().Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+	params := make(map[string]string)
+	for k, v := range c.ctx.AllParams() {
+		params[strings.Clone(k)] = strings.Clone(v)
+	}
+	query, _ := url.ParseQuery(string(c.ctx.Request().URI().QueryString()))
+	return wsRequest{
+		path:     strings.Clone(c.Path()),
+		clientIP: strings.Clone(c.ClientIP()),
+		params:   params,
+		query:    query,
+		header:   header,
+	}
+}
+
+// copyKeys 复制上下文数据
+func copyKeys(keys map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(keys))
+	for k, v := range keys {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,357 @@
+package unified
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// xmlCodec XML编解码器
+type xmlCodec struct{}
+
+// ContentType 实现Codec接口
+func (xmlCodec) ContentType() string {
+	return MIMEXML + "; charset=utf-8"
+}
+
+// Marshal 实现Codec接口，encoding/xml不支持的类型（如map）按JSON结构转换，根元素为response
+func (xmlCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	e := xml.NewEncoder(&buf)
+	err := e.Encode(v)
+	var unsupported *xml.UnsupportedTypeError
+	if errors.As(err, &unsupported) || (err == nil && isXMLUnnamed(v)) {
+		buf.Reset()
+		buf.WriteString(xml.Header)
+		e = xml.NewEncoder(&buf)
+		err = EncodeXMLElement(e, xml.StartElement{Name: xml.Name{Local: "response"}}, v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal 实现Codec接口
+// 目标声明了xml标签或实现了xml.Unmarshaler时使用encoding/xml，
+// 否则按照json标签匹配元素名（不区分大小写），使同一个请求结构体可以同时接收JSON和XML
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return errors.New("绑定目标必须是非空指针")
+	}
+	if hasXMLMapping(t.Elem()) {
+		return xml.Unmarshal(data, v)
+	}
+
+	root, err := parseXMLNode(data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(xmlNodeValue(root, t.Elem()))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// hasXMLMapping 判断类型是否自行定义了XML映射
+func hasXMLMapping(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(xmlUnmarshaler) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if _, ok := f.Tag.Lookup("xml"); ok || f.Name == "XMLName" {
+			return true
+		}
+		if f.Anonymous && hasXMLMapping(f.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	xmlUnmarshaler  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// xmlNode 解析后的XML元素，属性按子元素处理
+type xmlNode struct {
+	name     string
+	text     string
+	children []*xmlNode
+}
+
+// parseXMLNode 解析XML文档的根元素
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local}
+			for _, attr := range t.Attr {
+				node.children = append(node.children, &xmlNode{name: attr.Name.Local, text: attr.Value})
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("请求体中没有XML元素")
+	}
+	return root, nil
+}
+
+// xmlNodeValue 按照目标类型将XML元素转换为可以序列化为JSON的值
+func xmlNodeValue(n *xmlNode, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	text := strings.TrimSpace(n.text)
+	if t == nil || t.Kind() == reflect.Interface {
+		if len(n.children) == 0 {
+			return text
+		}
+		return xmlChildrenValue(n, func(string) reflect.Type { return nil })
+	}
+	// time.Time等自定义解析的类型使用元素文本
+	pt := reflect.PointerTo(t)
+	if pt.Implements(jsonUnmarshaler) || pt.Implements(textUnmarshaler) {
+		return text
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return xmlChildrenValue(n, func(name string) reflect.Type { return jsonFieldType(t, name) })
+	case reflect.Map:
+		return xmlChildrenValue(n, func(string) reflect.Type { return t.Elem() })
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return text
+		}
+		// 数组元素包裹在子元素中，例如<tags><item>a</item><item>b</item></tags>
+		items := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			items = append(items, xmlNodeValue(child, t.Elem()))
+		}
+		if len(items) == 0 && text != "" {
+			items = append(items, xmlNodeValue(n, t.Elem()))
+		}
+		return items
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if text == "" {
+			return nil
+		}
+		if _, err := strconv.ParseFloat(text, 64); err == nil {
+			return json.Number(text)
+		}
+	case reflect.String:
+		return n.text
+	}
+	// 无法转换时保留文本，由JSON解析返回类型错误
+	return text
+}
+
+// xmlChildrenValue 将子元素转换为对象，同名的多个子元素转换为数组
+func xmlChildrenValue(n *xmlNode, fieldType func(name string) reflect.Type) map[string]interface{} {
+	m := make(map[string]interface{}, len(n.children))
+	groups := make(map[string][]*xmlNode, len(n.children))
+	var names []string
+	for _, child := range n.children {
+		if _, ok := groups[child.name]; !ok {
+			names = append(names, child.name)
+		}
+		groups[child.name] = append(groups[child.name], child)
+	}
+	for _, name := range names {
+		nodes := groups[name]
+		ft := fieldType(name)
+		isList := ft != nil && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array) && ft.Elem().Kind() != reflect.Uint8
+		switch {
+		case isList && len(nodes) > 1:
+			// 数组元素直接重复，例如<tags>a</tags><tags>b</tags>
+			items := make([]interface{}, 0, len(nodes))
+			for _, node := range nodes {
+				items = append(items, xmlNodeValue(node, ft.Elem()))
+			}
+			m[name] = items
+		case isList && len(nodes[0].children) == 0:
+			m[name] = []interface{}{xmlNodeValue(nodes[0], ft.Elem())}
+		case len(nodes) > 1 && ft == nil:
+			items := make([]interface{}, 0, len(nodes))
+			for _, node := range nodes {
+				items = append(items, xmlNodeValue(node, nil))
+			}
+			m[name] = items
+		default:
+			m[name] = xmlNodeValue(nodes[len(nodes)-1], ft)
+		}
+	}
+	return m
+}
+
+// jsonFieldType 按照encoding/json的规则查找字段类型，包括匿名嵌入结构体中的字段
+func jsonFieldType(t reflect.Type, name string) reflect.Type {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tagName, ok := fieldName(f, "json")
+		if !ok {
+			continue
+		}
+		if tagName == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && ft.Kind() == reflect.Struct {
+				if found := jsonFieldType(ft, name); found != nil {
+					return found
+				}
+				continue
+			}
+			tagName = f.Name
+		}
+		if strings.EqualFold(tagName, name) {
+			return f.Type
+		}
+	}
+	return nil
+}
+
+// isXMLUnnamed 判断值在encoding/xml中是否没有合适的根元素，只有结构体以类型名或XMLName作为根元素
+func isXMLUnnamed(v interface{}) bool {
+	if _, ok := v.(xml.Marshaler); ok {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return true
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() != reflect.Struct
+}
+
+// EncodeXMLElement 将任意值按照其JSON结构编码为XML元素
+// 对象字段名使用json标签，数组元素使用item元素，不是合法XML名称的键使用带key属性的entry元素
+// 实现了xml.Marshaler的值直接使用自身的编码方式
+func EncodeXMLElement(e *xml.Encoder, start xml.StartElement, v interface{}) error {
+	if _, ok := v.(xml.Marshaler); ok {
+		return e.EncodeElement(v, start)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return encodeXMLToken(e, dec, start)
+}
+
+// encodeXMLToken 读取一个JSON值并编码为XML元素
+func encodeXMLToken(e *xml.Encoder, dec *json.Decoder, start xml.StartElement) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if err := e.EncodeToken(start); err != nil {
+			return err
+		}
+		for dec.More() {
+			child := xml.StartElement{Name: xml.Name{Local: "item"}}
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlElement(key.(string))
+			}
+			if err := encodeXMLToken(e, dec, child); err != nil {
+				return err
+			}
+		}
+		// 读取结束符
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		return e.EncodeToken(start.End())
+	case nil:
+		return e.EncodeElement("", start)
+	default:
+		return e.EncodeElement(fmt.Sprint(t), start)
+	}
+}
+
+// xmlElement 根据键名创建元素
+func xmlElement(key string) xml.StartElement {
+	if isXMLName(key) {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: "entry"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+	}
+}
+
+// isXMLName 判断键名是否可以直接作为XML元素名
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 0x7f:
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 上下文相关的日志字段名
+const (
+	// RequestIDField 请求ID的日志字段名
+	RequestIDField = "request_id"
+	// TraceIDField 链路追踪ID的日志字段名
+	TraceIDField = "trace_id"
+	// SpanIDField 当前span ID的日志字段名
+	SpanIDField = "span_id"
+)
+
+// requestIDKey 请求ID在context.Context中的键
+type requestIDKey struct{}
+
+// WithRequestID 将请求ID放入context.Context，通过Logger.WithContext记录的日志会带上请求ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 获取context.Context中的请求ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHook 将日志条目关联的context.Context中的请求ID和链路追踪ID加入日志字段
+type contextHook struct{}
+
+// Levels 实现logrus.Hook接口
+func (contextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 实现logrus.Hook接口
+func (contextHook) Fire(entry *logrus.Entry) error {
+	if requestID := RequestIDFromContext(entry.Context); requestID != "" {
+		if _, ok := entry.Data[RequestIDField]; !ok {
+			entry.Data[RequestIDField] = requestID
+		}
+	}
+	if entry.Context != nil {
+		if sc := trace.SpanContextFromContext(entry.Context); sc.IsValid() {
+			if _, ok := entry.Data[TraceIDField]; !ok {
+				entry.Data[TraceIDField] = sc.TraceID().String()
+				entry.Data[SpanIDField] = sc.SpanID().String()
+			}
+		}
+	}
+	return nil
+}
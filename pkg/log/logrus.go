@@ -1,6 +1,7 @@
 package log
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -26,6 +27,8 @@ type Logger interface {
 	Fatalf(format string, args ...interface{})
 	WithField(key string, value interface{}) *logrus.Entry
 	WithFields(fields logrus.Fields) *logrus.Entry
+	// WithContext 创建关联context.Context的日志条目，上下文中的请求ID和链路追踪ID会加入日志字段
+	WithContext(ctx context.Context) *logrus.Entry
 }
 
 // LoggerParams 日志参数
@@ -101,8 +104,18 @@ func NewLogger(p LoggerParams) (Logger, error) {
 	}
 	log.SetOutput(output)
 
+	// 记录上下文中的请求ID和链路追踪ID
+	log.AddHook(contextHook{})
+
 	return log, nil
 }
 
+// NewDiscardLogger 创建丢弃所有输出的日志记录器，用于测试和需要静默日志的命令
+func NewDiscardLogger() Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
 // Module 提供日志模块
 var Module = fx.Provide(NewLogger)
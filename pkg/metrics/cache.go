@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+)
+
+// cacheBuckets 缓存操作耗时的分桶（秒），内存缓存的操作通常在微秒级
+var cacheBuckets = []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// CacheObserver 缓存指标，按缓存类型记录每种操作的耗时和错误，以及get和hget的命中和未命中次数
+type CacheObserver struct {
+	duration *Histogram
+	hits     *Counter
+	misses   *Counter
+	failures *Counter
+}
+
+// NewCacheObserver 注册缓存指标
+func NewCacheObserver(registry *Registry) *CacheObserver {
+	return &CacheObserver{
+		duration: registry.Histogram("cache_operation_duration_seconds", "缓存操作耗时（秒）", cacheBuckets, "backend", "operation"),
+		hits:     registry.Counter("cache_hits_total", "缓存命中次数", "backend"),
+		misses:   registry.Counter("cache_misses_total", "缓存未命中次数", "backend"),
+		failures: registry.Counter("cache_errors_total", "缓存操作失败次数，不包括未命中", "backend", "operation"),
+	}
+}
+
+// Start 实现cache.Observer接口
+func (o *CacheObserver) Start(ctx context.Context, backend, op string) (context.Context, func(err error)) {
+	start := time.Now()
+	return ctx, func(err error) {
+		o.duration.Observe(time.Since(start).Seconds(), backend, op)
+		miss := cache.IsMiss(err)
+		if err != nil && !miss {
+			o.failures.Inc(backend, op)
+			return
+		}
+		if op == "get" || op == "hget" {
+			if miss {
+				o.misses.Inc(backend)
+			} else {
+				o.hits.Inc(backend)
+			}
+		}
+	}
+}
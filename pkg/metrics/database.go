@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/database"
+	"gorm.io/gorm"
+)
+
+// startKey 语句开始时间在gorm实例设置中的键
+const startKey = "metrics:start"
+
+// RegisterDatabase 通过GORM回调记录每条语句的耗时和错误，并在输出时读取sql.DB连接池状态
+func RegisterDatabase(registry *Registry, db *gorm.DB) error {
+	duration := registry.Histogram("db_query_duration_seconds", "数据库语句执行耗时（秒）", nil, "operation", "table")
+	failures := registry.Counter("db_query_errors_total", "数据库语句执行失败次数，不包括记录不存在", "operation", "table")
+
+	before := func(string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			tx.InstanceSet(startKey, time.Now())
+		}
+	}
+	after := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			duration.Observe(time.Since(v.(time.Time)).Seconds(), operation, table)
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				failures.Inc(operation, table)
+			}
+		}
+	}
+	if err := database.RegisterCallbacks(db, "metrics", before, after); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	registry.GaugeFunc("db_connections_max_open", "连接池最大连接数", func() float64 {
+		return float64(sqlDB.Stats().MaxOpenConnections)
+	})
+	registry.GaugeFunc("db_connections_open", "连接池当前连接数", func() float64 {
+		return float64(sqlDB.Stats().OpenConnections)
+	})
+	registry.GaugeFunc("db_connections_in_use", "正在使用的连接数", func() float64 {
+		return float64(sqlDB.Stats().InUse)
+	})
+	registry.GaugeFunc("db_connections_idle", "空闲连接数", func() float64 {
+		return float64(sqlDB.Stats().Idle)
+	})
+	registry.CounterFunc("db_connections_wait_total", "等待空闲连接的次数", func() float64 {
+		return float64(sqlDB.Stats().WaitCount)
+	})
+	registry.CounterFunc("db_connections_wait_seconds_total", "等待空闲连接的总时长（秒）", func() float64 {
+		return sqlDB.Stats().WaitDuration.Seconds()
+	})
+	return nil
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+)
+
+// HTTPMetrics HTTP请求指标，按路由模板（如/users/:id）而不是请求路径统计，避免路径参数产生大量序列
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+	inFlight *Gauge
+}
+
+// NewHTTPMetrics 注册HTTP请求指标
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: registry.Counter("http_requests_total", "HTTP请求数", "method", "route", "status"),
+		duration: registry.Histogram("http_request_duration_seconds", "HTTP请求处理耗时（秒）", nil, "method", "route"),
+		inFlight: registry.Gauge("http_requests_in_flight", "正在处理的HTTP请求数"),
+	}
+}
+
+// Middleware 记录请求数、耗时和状态码的中间件，需要在其他中间件之前注册，
+// 处理函数返回的错误在此交给统一错误处理流程输出，才能记录最终的状态码
+func (m *HTTPMetrics) Middleware() unified.MiddlewareFunc {
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			start := time.Now()
+			unified.HandleError(c, next(c))
+			method, route := c.Method(), c.Path()
+			m.duration.Observe(time.Since(start).Seconds(), method, route)
+			m.requests.Inc(method, route, strconv.Itoa(unified.ResponseStatus(c)))
+			return nil
+		}
+	}
+}
+
+// Handler 按Prometheus文本格式输出注册表中所有指标的处理函数
+func (r *Registry) Handler() unified.HandlerFunc {
+	return func(c unified.Context) error {
+		var buf bytes.Buffer
+		if err := r.Write(&buf); err != nil {
+			return err
+		}
+		c.SetHeader("Cache-Control", "no-store")
+		return c.Data(http.StatusOK, ContentType, buf.Bytes())
+	}
+}
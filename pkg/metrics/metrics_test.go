@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	unifiedtesting "github.com/zhoudm1743/go-frame/pkg/http/unified/testing"
+)
+
+// newRegistry 创建带有命名空间的注册表
+func newRegistry(namespace string) *Registry {
+	cfg := &config.Config{}
+	cfg.Metrics.Namespace = namespace
+	return NewRegistry(cfg)
+}
+
+const golden = `# HELP app_latency_seconds 耗时
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{route="/x",le="0.1"} 1
+app_latency_seconds_bucket{route="/x",le="1"} 2
+app_latency_seconds_bucket{route="/x",le="+Inf"} 3
+app_latency_seconds_sum{route="/x"} 3.5
+app_latency_seconds_count{route="/x"} 3
+# TYPE app_pool_size gauge
+app_pool_size 4
+# HELP app_requests_total 请求数\n按路径 \\ 统计
+# TYPE app_requests_total counter
+app_requests_total{path="/a\"b\\c\nd"} 1
+app_requests_total{path="/b"} 2.5
+# HELP app_temperature 温度
+# TYPE app_temperature gauge
+app_temperature -1.5
+`
+
+func TestWrite(t *testing.T) {
+	r := newRegistry("app")
+
+	requests := r.Counter("requests_total", "请求数\n按路径 \\ 统计", "path")
+	requests.Add(2.5, "/b")
+	requests.Inc("/a\"b\\c\nd")
+
+	r.Gauge("temperature", "温度").Set(-1.5)
+
+	// 分桶按上界排序，大于所有上界的值只计入+Inf
+	latency := r.Histogram("latency_seconds", "耗时", []float64{1, 0.1}, "route")
+	for _, v := range []float64{0.0625, 0.4375, 3} {
+		latency.Observe(v, "/x")
+	}
+
+	r.GaugeFunc("pool_size", "", func() float64 { return 4 })
+
+	// 没有任何序列的指标不输出
+	r.Counter("unused_total", "未使用", "path")
+
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != golden {
+		t.Errorf("输出不一致:\n%s\n期望:\n%s", out.String(), golden)
+	}
+}
+
+func TestRegisterConflict(t *testing.T) {
+	r := newRegistry("")
+	first := r.Counter("jobs_total", "任务数", "queue")
+	if again := r.Counter("jobs_total", "任务数", "queue"); again.f != first.f {
+		t.Error("重复注册返回了不同的指标")
+	}
+	for name, register := range map[string]func(){
+		"类型不同":    func() { r.Gauge("jobs_total", "任务数", "queue") },
+		"标签不同":    func() { r.Counter("jobs_total", "任务数", "worker") },
+		"名称不合法":   func() { r.Counter("jobs-total", "任务数") },
+		"le标签":    func() { r.Histogram("job_seconds", "耗时", nil, "le") },
+		"标签值数量错误": func() { r.Counter("jobs_total", "任务数", "queue").Inc() },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: 没有panic", name)
+				}
+			}()
+			register()
+		}()
+	}
+}
+
+// HTTP指标按路由模板统计，不同的路径参数计入同一个序列
+func TestHTTPMiddleware(t *testing.T) {
+	for _, engine := range []unified.EngineType{unified.GinEngine, unified.FiberEngine, unified.StdEngine} {
+		r := newRegistry("")
+		h := unifiedtesting.New(engine)
+		h.Router().Use(NewHTTPMetrics(r).Middleware())
+		h.Router().GET("/users/:id", func(c unified.Context) error {
+			if c.Param("id") == "0" {
+				return c.String(http.StatusNotFound, "not found")
+			}
+			return c.String(http.StatusOK, "ok")
+		})
+
+		for _, target := range []string{"/users/1", "/users/2", "/users/0"} {
+			if _, err := h.Do(unifiedtesting.NewRequest(http.MethodGet, target, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		h.Close()
+
+		var out strings.Builder
+		if err := r.Write(&out); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range []string{
+			`http_requests_total{method="GET",route="/users/:id",status="200"} 2`,
+			`http_requests_total{method="GET",route="/users/:id",status="404"} 1`,
+			`http_request_duration_seconds_count{method="GET",route="/users/:id"} 3`,
+			`http_requests_in_flight 0`,
+		} {
+			if !strings.Contains(out.String(), line+"\n") {
+				t.Errorf("%s: 输出中缺少 %s:\n%s", engine, line, out.String())
+			}
+		}
+		if strings.Contains(out.String(), "/users/1") {
+			t.Errorf("%s: 按请求路径统计了指标", engine)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Module 指标模块，提供*Registry和*HTTPMetrics，启用时记录数据库和缓存指标
+var Module = fx.Options(
+	fx.Provide(NewRegistry),
+	fx.Provide(NewHTTPMetrics),
+	cache.ProvideObserver(cacheObserver),
+	fx.Invoke(registerDatabase),
+)
+
+// cacheObserver 启用指标时提供缓存观察者
+func cacheObserver(registry *Registry, cfg *config.Config) cache.Observer {
+	if !cfg.Metrics.Enable {
+		return nil
+	}
+	return NewCacheObserver(registry)
+}
+
+// databaseParams 数据库指标参数，未使用数据库模块时不记录
+type databaseParams struct {
+	fx.In
+	Config   *config.Config
+	Registry *Registry
+	DB       *gorm.DB `optional:"true"`
+}
+
+// registerDatabase 启用指标时记录数据库指标
+func registerDatabase(p databaseParams) error {
+	if !p.Config.Metrics.Enable || p.DB == nil {
+		return nil
+	}
+	return RegisterDatabase(p.Registry, p.DB)
+}
@@ -0,0 +1,365 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zhoudm1743/go-frame/pkg/config"
+)
+
+// ContentType Prometheus文本格式的内容类型
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// 指标类型
+const (
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// DefBuckets 耗时直方图的默认分桶（秒）
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Registry 指标注册表，按Prometheus文本格式输出所有指标，模块注入后注册自己的指标，例如:
+//
+//	orders := registry.Counter("orders_created_total", "创建的订单数", "channel")
+//	orders.Inc("app")
+//
+// 配置了metrics.namespace时所有指标名称加上该前缀，相同名称、类型和标签重复注册时返回已注册的指标
+type Registry struct {
+	namespace string
+	buckets   []float64
+	mu        sync.RWMutex
+	families  map[string]*family
+}
+
+// NewRegistry 创建指标注册表
+func NewRegistry(cfg *config.Config) *Registry {
+	buckets := cfg.Metrics.Buckets
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	return &Registry{
+		namespace: cfg.Metrics.Namespace,
+		buckets:   buckets,
+		families:  make(map[string]*family),
+	}
+}
+
+// Buckets 配置的耗时直方图分桶
+func (r *Registry) Buckets() []float64 {
+	return r.buckets
+}
+
+// Counter 注册只增不减的计数器，labels为标签名称
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(name, help, kindCounter, labels, nil, nil)}
+}
+
+// Gauge 注册可增可减的仪表盘
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(name, help, kindGauge, labels, nil, nil)}
+}
+
+// Histogram 注册直方图，buckets为分桶的上界，为空时使用配置的耗时分桶
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = r.buckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	return &Histogram{r.register(name, help, kindHistogram, labels, buckets, nil)}
+}
+
+// CounterFunc 注册在输出时读取的计数器，用于统计已由其他组件累计的值，例如连接池的等待次数
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, kindCounter, nil, nil, fn)
+}
+
+// GaugeFunc 注册在输出时读取的仪表盘，例如连接池的连接数
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, help, kindGauge, nil, nil, fn)
+}
+
+// Write 按Prometheus文本格式输出所有指标，指标按名称排序
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	buf := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(buf)
+	}
+	return buf.Flush()
+}
+
+// register 注册指标，名称或标签不合法、与已注册的指标冲突时panic
+func (r *Registry) register(name, help, kind string, labels []string, buckets []float64, fn func() float64) *family {
+	if r.namespace != "" {
+		name = r.namespace + "_" + name
+	}
+	if !namePattern.MatchString(name) {
+		panic(fmt.Sprintf("指标名称不合法: %q", name))
+	}
+	for _, label := range labels {
+		if !namePattern.MatchString(label) || strings.Contains(label, ":") || label == "le" {
+			panic(fmt.Sprintf("指标%s的标签名称不合法: %q", name, label))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		if f.kind != kind || f.fn != nil || fn != nil || !slices.Equal(f.labels, labels) || !slices.Equal(f.buckets, buckets) {
+			panic(fmt.Sprintf("指标%s已注册为不同的类型或标签", name))
+		}
+		return f
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    kind,
+		labels:  slices.Clone(labels),
+		buckets: buckets,
+		fn:      fn,
+		series:  make(map[string]*series),
+	}
+	// 没有标签的指标立即创建，尚未记录时也输出0
+	if len(labels) == 0 && fn == nil {
+		f.get(nil)
+	}
+	r.families[name] = f
+	return f
+}
+
+// family 同一名称的指标，每组标签值对应一个序列
+type family struct {
+	name    string
+	help    string
+	kind    string
+	labels  []string
+	buckets []float64
+	fn      func() float64
+	mu      sync.RWMutex
+	series  map[string]*series
+}
+
+// series 一组标签值的指标数据
+type series struct {
+	values []string
+	mu     sync.Mutex
+	value  float64
+	counts []uint64 // 直方图各分桶的计数，不累计
+	count  uint64
+	sum    float64
+}
+
+// get 获取标签值对应的序列，不存在时创建，标签值数量与标签数量不一致时panic
+func (f *family) get(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("指标%s需要%d个标签值，传入了%d个", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s = &series{values: slices.Clone(values)}
+	if f.kind == kindHistogram {
+		s.counts = make([]uint64, len(f.buckets))
+	}
+	f.series[key] = s
+	return s
+}
+
+// write 输出指标，没有任何序列的指标不输出
+func (f *family) write(w *bufio.Writer) {
+	if f.fn != nil {
+		f.writeHeader(w)
+		fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.fn()))
+		return
+	}
+
+	f.mu.RLock()
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	all := make([]*series, len(keys))
+	for i, key := range keys {
+		all[i] = f.series[key]
+	}
+	f.mu.RUnlock()
+	if len(all) == 0 {
+		return
+	}
+
+	f.writeHeader(w)
+	for _, s := range all {
+		s.mu.Lock()
+		if f.kind != kindHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelText(s.values, ""), formatFloat(s.value))
+			s.mu.Unlock()
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelText(s.values, formatFloat(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelText(s.values, "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labelText(s.values, ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labelText(s.values, ""), s.count)
+		s.mu.Unlock()
+	}
+}
+
+// writeHeader 输出HELP和TYPE行
+func (f *family) writeHeader(w *bufio.Writer) {
+	if f.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+// labelText 生成标签部分，le不为空时追加直方图分桶的le标签
+func (f *family) labelText(values []string, le string) string {
+	if len(values) == 0 && le == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, label := range f.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(label)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(values[i]))
+		b.WriteByte('"')
+	}
+	if le != "" {
+		if len(f.labels) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`le="`)
+		b.WriteString(le)
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// formatFloat 按Prometheus文本格式输出浮点数
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Counter 计数器
+type Counter struct {
+	f *family
+}
+
+// Inc 计数加1，values为各标签的值
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add 计数增加v，v不能为负数
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("计数器%s不能减少", c.f.name))
+	}
+	s := c.f.get(values)
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+// Gauge 仪表盘
+type Gauge struct {
+	f *family
+}
+
+// Set 设置当前值
+func (g *Gauge) Set(v float64, values ...string) {
+	s := g.f.get(values)
+	s.mu.Lock()
+	s.value = v
+	s.mu.Unlock()
+}
+
+// Add 当前值增加v，v可以为负数
+func (g *Gauge) Add(v float64, values ...string) {
+	s := g.f.get(values)
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+// Inc 当前值加1
+func (g *Gauge) Inc(values ...string) {
+	g.Add(1, values...)
+}
+
+// Dec 当前值减1
+func (g *Gauge) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+// Histogram 直方图
+type Histogram struct {
+	f *family
+}
+
+// Observe 记录一个观测值，耗时使用秒
+func (h *Histogram) Observe(v float64, values ...string) {
+	s := h.f.get(values)
+	i := sort.SearchFloat64s(h.f.buckets, v)
+	s.mu.Lock()
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+	s.mu.Unlock()
+}
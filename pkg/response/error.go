@@ -1,8 +1,11 @@
 package response
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gofiber/fiber/v2"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
 )
 
 // NoRoute 无路由的响应
@@ -34,3 +37,13 @@ func FiberNoMethod(c *fiber.Ctx) error {
 func FiberErrDuplicateName(c *fiber.Ctx) error {
 	return FiberFail(c, RequestErrDuplicateNameError)
 }
+
+// StdNoRoute 标准库无路由的响应
+func StdNoRoute(w http.ResponseWriter, r *http.Request) {
+	UnifiedFail(unified.NewStdContext(w, r), Request404Error)
+}
+
+// StdNoMethod 标准库无方法的响应
+func StdNoMethod(w http.ResponseWriter, r *http.Request) {
+	UnifiedFail(unified.NewStdContext(w, r), Request405Error)
+}
@@ -70,7 +70,12 @@ var (
 	TenantDisableOrExpired = RespType{code: 401, msg: "租户已被禁用或过期"}
 
 	RequestErrDuplicateNameError = RespType{code: 406, msg: "请求参数名称重复"}
+	IdempotencyKeyInProgress     = RespType{code: 409, msg: "相同幂等键的请求正在处理"}
+	IdempotencyKeyConflict       = RespType{code: 422, msg: "幂等键已用于其他请求"}
+	TooManyRequests              = RespType{code: 429, msg: "请求过于频繁"}
 	SystemError                  = RespType{code: 500, msg: "系统错误"}
+	ServiceUnavailable           = RespType{code: 503, msg: "服务暂不可用"}
+	RequestTimeout               = RespType{code: 504, msg: "请求处理超时"}
 )
 
 // Error 实现error方法
@@ -105,6 +110,26 @@ func (rt RespType) Data() interface{} {
 	return rt.data
 }
 
+// HTTPStatus 获取响应类型对应的HTTP状态码
+// 参数错误为400，登录和token错误为401，租户不可用为403，名称重复为409，其他4xx/5xx的code原样使用
+func (rt RespType) HTTPStatus() int {
+	switch {
+	case rt.code == Success.code:
+		return http.StatusOK
+	case rt.code >= 310 && rt.code < 330:
+		return http.StatusBadRequest
+	case rt.code >= 330 && rt.code < 340:
+		return http.StatusUnauthorized
+	case rt.code == TenantDisableOrExpired.code:
+		return http.StatusForbidden
+	case rt.code == RequestErrDuplicateNameError.code:
+		return http.StatusConflict
+	case rt.code >= 400 && rt.code < 600:
+		return rt.code
+	}
+	return http.StatusBadRequest
+}
+
 // Result 统一响应
 func Result(c *gin.Context, resp RespType, data interface{}) {
 	if data == nil {
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"encoding/xml"
 	"net/http"
 
 	"github.com/zhoudm1743/go-frame/pkg/http/unified"
@@ -13,6 +14,24 @@ type UnifiedResponse struct {
 	Data interface{} `json:"data"`
 }
 
+// MarshalXML 实现xml.Marshaler接口，根元素为response，data按照JSON结构转换，字段名与JSON一致
+func (r UnifiedResponse) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "response"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(r.Code, xml.StartElement{Name: xml.Name{Local: "code"}}); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(r.Msg, xml.StartElement{Name: xml.Name{Local: "message"}}); err != nil {
+		return err
+	}
+	if err := unified.EncodeXMLElement(e, xml.StartElement{Name: xml.Name{Local: "data"}}, r.Data); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
 // UnifiedResult 统一响应
 func UnifiedResult(c unified.Context, resp RespType, data interface{}) error {
 	if data == nil {
@@ -23,7 +42,8 @@ func UnifiedResult(c unified.Context, resp RespType, data interface{}) error {
 		c.Error(resp)
 	}
 
-	return c.JSON(http.StatusOK, UnifiedResponse{
+	// 按Accept请求头选择JSON、XML、MessagePack或Protobuf格式
+	return c.Negotiate(http.StatusOK, UnifiedResponse{
 		Code: resp.code,
 		Msg:  resp.msg,
 		Data: data,
@@ -66,6 +86,18 @@ func UnifiedFailWithData(c unified.Context, resp RespType, data interface{}) err
 	return UnifiedResult(c, resp, data)
 }
 
+// UnifiedFailWithStatus 以指定的HTTP状态码输出错误响应，供统一错误处理使用，不会再记录错误
+func UnifiedFailWithStatus(c unified.Context, status int, resp RespType, data interface{}) error {
+	if data == nil {
+		data = []string{}
+	}
+	return c.Negotiate(status, UnifiedResponse{
+		Code: resp.code,
+		Msg:  resp.msg,
+		Data: data,
+	})
+}
+
 // UnifiedIsFailWithResp 判断是否出现错误，并追加错误返回信息
 func UnifiedIsFailWithResp(c unified.Context, err error) bool {
 	if err == nil {
@@ -101,3 +133,20 @@ func UnifiedCheckAndRespWithData(c unified.Context, data interface{}, err error)
 	}
 	return UnifiedOkWithData(c, data)
 }
+
+// UnifiedResponder 类型化处理函数的统一响应方式
+// 错误原样返回，由统一错误处理映射为HTTP状态码和统一响应
+type UnifiedResponder struct{}
+
+// Success 实现unified.Responder接口
+func (UnifiedResponder) Success(c unified.Context, data interface{}) error {
+	if _, ok := data.(unified.Empty); ok {
+		return UnifiedOk(c)
+	}
+	return UnifiedOkWithData(c, data)
+}
+
+// Fail 实现unified.Responder接口
+func (UnifiedResponder) Fail(c unified.Context, err error) error {
+	return err
+}
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheObserver 为缓存操作创建子span，上下文中没有span时不创建，避免健康检查等后台操作产生大量span
+type CacheObserver struct {
+	tracer trace.Tracer
+}
+
+// NewCacheObserver 创建缓存span观察者
+func NewCacheObserver(provider trace.TracerProvider) *CacheObserver {
+	return &CacheObserver{tracer: provider.Tracer(InstrumentationName)}
+}
+
+// Start 实现cache.Observer接口
+func (o *CacheObserver) Start(ctx context.Context, backend, op string) (context.Context, func(err error)) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, func(error) {}
+	}
+	ctx, span := o.tracer.Start(ctx, "cache "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNameKey.String(backend),
+			semconv.DBOperationName(op),
+		),
+	)
+	return ctx, func(err error) {
+		miss := cache.IsMiss(err)
+		if op == "get" || op == "hget" {
+			span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+		}
+		if err != nil && !miss {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/database"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// spanKey 语句的span在gorm实例设置中的键
+const spanKey = "tracing:span"
+
+// RegisterDatabase 通过GORM回调为每条语句创建子span，记录SQL、表名和错误，
+// 只有db.WithContext传入的上下文中带有span时才创建，启动时的迁移等后台语句不产生span
+func RegisterDatabase(provider trace.TracerProvider, db *gorm.DB) error {
+	tracer := provider.Tracer(InstrumentationName)
+	system := db.Dialector.Name()
+
+	before := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+				return
+			}
+			ctx, span := tracer.Start(ctx, operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemNameKey.String(system),
+					semconv.DBOperationName(operation),
+				),
+			)
+			tx.Statement.Context = ctx
+			tx.InstanceSet(spanKey, span)
+		}
+	}
+	after := func(operation string) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			v, ok := tx.InstanceGet(spanKey)
+			if !ok {
+				return
+			}
+			span := v.(trace.Span)
+			defer span.End()
+
+			if table := tx.Statement.Table; table != "" {
+				span.SetName(operation + " " + table)
+				span.SetAttributes(semconv.DBCollectionName(table))
+			}
+			if sql := strings.TrimSpace(tx.Statement.SQL.String()); sql != "" {
+				span.SetAttributes(semconv.DBQueryText(sql))
+			}
+			if operation == "query" {
+				span.SetAttributes(semconv.DBResponseReturnedRows(int(tx.RowsAffected)))
+			}
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				span.RecordError(tx.Error)
+				span.SetStatus(codes.Error, tx.Error.Error())
+			}
+		}
+	}
+	return database.RegisterCallbacks(db, "tracing", before, after)
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware 为每个请求创建服务端span的中间件，span名称为"方法 路由模板"，
+// 请求带有traceparent头时作为上游调用的子span；span保存在c.Context()中，
+// 传给数据库和缓存调用的上下文会产生子span，通过Logger.WithContext记录的日志带有trace_id和span_id
+func Middleware(provider trace.TracerProvider) unified.MiddlewareFunc {
+	tracer := provider.Tracer(InstrumentationName)
+	return func(next unified.HandlerFunc) unified.HandlerFunc {
+		return func(c unified.Context) error {
+			parent := c.Context()
+			method, route := c.Method(), c.Path()
+			ctx := otel.GetTextMapPropagator().Extract(parent, headerCarrier{c})
+			ctx, span := tracer.Start(ctx, method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(c.URL().Path),
+					semconv.ClientAddress(c.ClientIP()),
+					semconv.UserAgentOriginal(c.GetHeader("User-Agent")),
+				),
+			)
+			defer span.End()
+
+			c.SetContext(ctx)
+			err := next(c)
+			if err != nil {
+				span.RecordError(err)
+			}
+			// 在span结束前输出错误响应，错误日志带有trace_id，并能记录最终的状态码
+			unified.HandleError(c, err)
+			c.SetContext(parent)
+
+			status := unified.ResponseStatus(c)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		}
+	}
+}
+
+// headerCarrier 从请求头读取传播字段
+type headerCarrier struct {
+	c unified.Context
+}
+
+// Get 实现propagation.TextMapCarrier接口，Fiber返回的请求头引用请求缓冲区，复制后交给传播器保存
+func (h headerCarrier) Get(key string) string {
+	return strings.Clone(h.c.GetHeader(key))
+}
+
+// Set 实现propagation.TextMapCarrier接口
+func (h headerCarrier) Set(key, value string) {
+	h.c.SetHeader(key, value)
+}
+
+// Keys 实现propagation.TextMapCarrier接口，W3C传播器只通过Get读取
+func (h headerCarrier) Keys() []string {
+	return nil
+}
@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Module 链路追踪模块，提供trace.TracerProvider，启用时为数据库和缓存操作创建子span
+var Module = fx.Options(
+	fx.Provide(NewTracerProvider),
+	cache.ProvideObserver(cacheObserver),
+	fx.Invoke(registerDatabase),
+)
+
+// cacheObserver 启用链路追踪时提供缓存观察者
+func cacheObserver(provider trace.TracerProvider, cfg *config.Config) cache.Observer {
+	if !cfg.Tracing.Enable {
+		return nil
+	}
+	return NewCacheObserver(provider)
+}
+
+// databaseParams 数据库span参数，未使用数据库模块时不记录
+type databaseParams struct {
+	fx.In
+	Config   *config.Config
+	Provider trace.TracerProvider
+	DB       *gorm.DB `optional:"true"`
+}
+
+// registerDatabase 启用链路追踪时为数据库语句创建span
+func registerDatabase(p databaseParams) error {
+	if !p.Config.Tracing.Enable || p.DB == nil {
+		return nil
+	}
+	return RegisterDatabase(p.Provider, p.DB)
+}
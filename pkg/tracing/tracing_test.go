@@ -0,0 +1,215 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhoudm1743/go-frame/pkg/cache"
+	"github.com/zhoudm1743/go-frame/pkg/config"
+	"github.com/zhoudm1743/go-frame/pkg/http/unified"
+	unifiedtesting "github.com/zhoudm1743/go-frame/pkg/http/unified/testing"
+	"github.com/zhoudm1743/go-frame/pkg/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 上游调用的traceparent
+const (
+	upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	upstreamSpanID  = "00f067aa0ba902b7"
+	traceparent     = "00-" + upstreamTraceID + "-" + upstreamSpanID + "-01"
+)
+
+// newProvider 创建同步导出到内存的TracerProvider，并像启用链路追踪时一样设置W3C传播器
+func newProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(propagator) })
+	return provider, exporter
+}
+
+// attr 获取span的属性值
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddlewareTraceparent(t *testing.T) {
+	for _, engine := range []unified.EngineType{unified.GinEngine, unified.FiberEngine, unified.StdEngine} {
+		provider, exporter := newProvider(t)
+		h := unifiedtesting.New(engine)
+		h.Router().Use(Middleware(provider))
+		h.Router().GET("/users/:id", func(c unified.Context) error {
+			// 调用下游服务时注入当前span，这里写入响应头检查
+			otel.GetTextMapPropagator().Inject(c.Context(), headerCarrier{c})
+			if c.Param("id") == "0" {
+				return c.String(http.StatusInternalServerError, "failed")
+			}
+			return c.String(http.StatusOK, "ok")
+		})
+
+		responses, err := h.Do(unifiedtesting.NewRequest(http.MethodGet, "/users/1", nil).WithHeader("traceparent", traceparent))
+		if err != nil {
+			t.Fatal(err)
+		}
+		spans := exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("%s: 导出了 %d 个span，期望 1", engine, len(spans))
+		}
+		span := spans[0]
+		if span.Name != "GET /users/:id" || span.SpanKind != trace.SpanKindServer {
+			t.Errorf("%s: span %q %s", engine, span.Name, span.SpanKind)
+		}
+		if span.SpanContext.TraceID().String() != upstreamTraceID || span.Parent.SpanID().String() != upstreamSpanID {
+			t.Errorf("%s: span没有作为上游调用的子span: trace %s parent %s", engine, span.SpanContext.TraceID(), span.Parent.SpanID())
+		}
+		if attr(span, "http.route").AsString() != "/users/:id" || attr(span, "http.response.status_code").AsInt64() != http.StatusOK {
+			t.Errorf("%s: span属性 %v", engine, span.Attributes)
+		}
+
+		injected := responses[0].Header.Get("traceparent")
+		want := "00-" + upstreamTraceID + "-" + span.SpanContext.SpanID().String() + "-01"
+		if injected != want {
+			t.Errorf("%s: 注入的traceparent %q，期望 %q", engine, injected, want)
+		}
+
+		// 没有traceparent时创建新的链路，5xx响应的span状态为Error
+		exporter.Reset()
+		if _, err := h.Do(unifiedtesting.NewRequest(http.MethodGet, "/users/0", nil)); err != nil {
+			t.Fatal(err)
+		}
+		spans = exporter.GetSpans()
+		if len(spans) != 1 {
+			t.Fatalf("%s: 导出了 %d 个span，期望 1", engine, len(spans))
+		}
+		if spans[0].Parent.IsValid() || spans[0].SpanContext.TraceID().String() == upstreamTraceID {
+			t.Errorf("%s: 没有traceparent的请求使用了上游链路", engine)
+		}
+		if spans[0].Status.Code != codes.Error {
+			t.Errorf("%s: 5xx响应的span状态 %v", engine, spans[0].Status)
+		}
+		h.Close()
+	}
+}
+
+func TestCacheSpans(t *testing.T) {
+	provider, exporter := newProvider(t)
+	c := cache.Observe(cache.NewTestMemory(t), "memory", NewCacheObserver(provider))
+
+	// 上下文中没有span时不创建
+	if _, err := c.GetCtx(context.Background(), "k"); !cache.IsMiss(err) {
+		t.Fatal(err)
+	}
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Fatalf("没有父span时创建了 %d 个span", len(spans))
+	}
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	if err := c.SetCtx(ctx, "k", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	c.GetCtx(ctx, "k")
+	c.GetCtx(ctx, "missing")
+	c.IncrByCtx(ctx, "k", 1)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 5 {
+		t.Fatalf("导出了 %d 个span，期望 5", len(spans))
+	}
+	for _, span := range spans[:4] {
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() || span.SpanKind != trace.SpanKindClient {
+			t.Errorf("%s: 不是请求span的子span", span.Name)
+		}
+		if attr(span, "db.system.name").AsString() != "memory" {
+			t.Errorf("%s: 属性 %v", span.Name, span.Attributes)
+		}
+	}
+	names := []string{spans[0].Name, spans[1].Name, spans[2].Name, spans[3].Name}
+	if strings.Join(names, ",") != "cache set,cache get,cache get,cache incrby" {
+		t.Errorf("span名称 %v", names)
+	}
+	if hit := attr(spans[1], "cache.hit"); hit.Type() != attribute.BOOL || !hit.AsBool() {
+		t.Errorf("命中时cache.hit %v", hit.Emit())
+	}
+	if hit := attr(spans[2], "cache.hit"); hit.Type() != attribute.BOOL || hit.AsBool() {
+		t.Errorf("未命中时cache.hit %v", hit.Emit())
+	}
+	// 未命中不是错误，其他错误记录在span上
+	if spans[2].Status.Code == codes.Error {
+		t.Error("未命中的span状态为Error")
+	}
+	if spans[3].Status.Code != codes.Error || len(spans[3].Events) == 0 {
+		t.Errorf("失败的操作 %v %v", spans[3].Status, spans[3].Events)
+	}
+}
+
+func TestLogTraceID(t *testing.T) {
+	provider, exporter := newProvider(t)
+	cfg := &config.Config{}
+	cfg.Log.Level = "info"
+	cfg.Log.Format = "json"
+	cfg.Log.OutputPath = filepath.Join(t.TempDir(), "app.log")
+	logger, err := log.NewLogger(log.LoggerParams{Config: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("traceparent", traceparent)
+	err = unified.NewChain(Middleware(provider)).Then(func(c unified.Context) error {
+		logger.WithContext(c.Context()).Info("创建订单")
+		return c.String(http.StatusOK, "ok")
+	})(unified.NewStdContext(w, r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.WithContext(context.Background()).Info("后台任务")
+
+	data, err := os.ReadFile(cfg.Log.OutputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("日志 %q，期望 2 行", data)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	span := exporter.GetSpans()[0]
+	if entry[log.TraceIDField] != upstreamTraceID || entry[log.SpanIDField] != span.SpanContext.SpanID().String() {
+		t.Errorf("日志的trace_id %v span_id %v，期望 %s %s", entry[log.TraceIDField], entry[log.SpanIDField], upstreamTraceID, span.SpanContext.SpanID())
+	}
+
+	// 没有span的日志不带trace_id
+	entry = nil
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := entry[log.TraceIDField]; ok {
+		t.Errorf("没有span的日志带有trace_id: %v", entry)
+	}
+}
@@ -16,8 +16,16 @@ var (
 
 // InitValidator 初始化验证器和中文翻译
 func InitValidator() error {
-	// 获取gin默认的validator
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+	// 获取gin默认的validator，gin禁用了绑定校验时创建使用binding标签的validator
+	var engine interface{}
+	if binding.Validator != nil {
+		engine = binding.Validator.Engine()
+	} else {
+		fallback := validator.New()
+		fallback.SetTagName("binding")
+		engine = fallback
+	}
+	if v, ok := engine.(*validator.Validate); ok {
 		Validator = v
 
 		// 注册自定义验证规则